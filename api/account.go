@@ -1,13 +1,11 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 
 	db "github.com/khorsl/simple_bank/db/sqlc"
 	"github.com/khorsl/simple_bank/token"
-	"github.com/lib/pq"
+	"github.com/khorsl/simple_bank/util"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,12 +16,14 @@ import (
 type createAccountRequest struct {
 	Owner    int64  `json:"owner"`
 	Currency string `json:"currency" binding:"required,currency"`
+	Type     string `json:"type" binding:"omitempty,account_type"`
+	Nickname string `json:"nickname" binding:"max=50"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -31,27 +31,28 @@ func (server *Server) createAccount(ctx *gin.Context) {
 
 	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
-	arg := db.CreateAccountParams{
-		Owner:    user.ID,
-		Currency: req.Currency,
-		Balance:  0,
+	if req.Type == "" {
+		req.Type = util.Checking
+	}
+
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    user.ID,
+			Currency: req.Currency,
+			Balance:  0,
+			Type:     req.Type,
+			Nickname: req.Nickname,
+		},
+		MaxAccounts: server.config.MaxAccounts(req.Type),
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation", "foreign_key_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -67,32 +68,12 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	account, err := server.store.GetAccount(ctx, req.AccountID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-
-	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	if account.Owner != user.ID {
-		err = errors.New("account does not belong to authenticated users")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	account, _, ok := server.authorizeAccount(ctx, req.AccountID, viewAccountRoles...)
+	if !ok {
 		return
 	}
 
@@ -102,14 +83,16 @@ func (server *Server) getAccount(ctx *gin.Context) {
 // List accounts
 
 type listAccountRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Type     string `form:"type" binding:"omitempty,account_type"`
+	Currency string `form:"currency" binding:"omitempty,currency"`
 }
 
 func (server *Server) listAccount(ctx *gin.Context) {
 	var req listAccountRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -117,19 +100,21 @@ func (server *Server) listAccount(ctx *gin.Context) {
 
 	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
 	arg := db.ListAccountsParams{
-		Owner:  user.ID,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+		UserID:   user.ID,
+		Type:     req.Type,
+		Currency: req.Currency,
+		Limit:    req.PageSize,
+		Offset:   (req.PageID - 1) * req.PageSize,
 	}
 
 	account, err := server.store.ListAccounts(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -0,0 +1,250 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
+)
+
+var (
+	errInvitationNotFound    = newAPIError(http.StatusNotFound, "invitation_not_found", "no pending invitation to this account")
+	errAccountMemberNotFound = newAPIError(http.StatusNotFound, "account_member_not_found", "account member not found")
+)
+
+// Roles of the account members that are allowed to perform each kind of action
+var (
+	viewAccountRoles     = []string{db.AccountRoleOwner, db.AccountRoleCoOwner, db.AccountRoleViewer}
+	transferAccountRoles = []string{db.AccountRoleOwner, db.AccountRoleCoOwner}
+	manageAccountRoles   = []string{db.AccountRoleOwner}
+)
+
+// authorizeAccount writes the error response and returns false when the account does not exist or the
+// authenticated user is not an active member of it with one of the given roles
+func (server *Server) authorizeAccount(ctx *gin.Context, accountID int64, roles ...string) (db.Account, db.User, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errAccountNotFound)
+			return db.Account{}, db.User{}, false
+		}
+
+		abortWithError(ctx, err)
+		return db.Account{}, db.User{}, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.Account{}, db.User{}, false
+	}
+
+	if !server.isAccountMemberWithRole(ctx, account.ID, user.ID, roles) {
+		return db.Account{}, db.User{}, false
+	}
+
+	return account, user, true
+}
+
+// isAccountMemberWithRole reports accounts the user is not an active member of as not found, so the ids
+// of other users' accounts cannot be probed. Members without one of the roles get a 403.
+func (server *Server) isAccountMemberWithRole(ctx *gin.Context, accountID int64, userID int64, roles []string) bool {
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: accountID,
+		UserID:    userID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return false
+	}
+
+	if err == sql.ErrNoRows || member.Status != db.MemberStatusActive {
+		abortWithError(ctx, errAccountNotFound)
+		return false
+	}
+
+	for _, role := range roles {
+		if member.Role == role {
+			return true
+		}
+	}
+
+	abortWithError(ctx, forbiddenError(fmt.Sprintf("account role %s is not allowed to perform this action", member.Role)))
+	return false
+}
+
+type accountMemberURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// Invite Account Member
+
+type inviteAccountMemberRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	Role     string `json:"role" binding:"required,oneof=co_owner viewer"`
+}
+
+func (server *Server) inviteAccountMember(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req inviteAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	_, owner, ok := server.authorizeAccount(ctx, uri.AccountID, manageAccountRoles...)
+	if !ok {
+		return
+	}
+
+	invitee, err := server.store.GetUserByUsername(ctx, req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.CreateAccountMemberParams{
+		AccountID: uri.AccountID,
+		UserID:    invitee.ID,
+		Role:      req.Role,
+		Status:    db.MemberStatusInvited,
+		InvitedBy: owner.ID,
+	}
+
+	member, err := server.store.CreateAccountMember(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// Accept Account Invitation
+
+func (server *Server) acceptAccountMember(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	member, err := server.store.AcceptAccountMember(ctx, db.AcceptAccountMemberParams{
+		AccountID: uri.AccountID,
+		UserID:    user.ID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errInvitationNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, member)
+}
+
+// List Account Members
+
+func (server *Server) listAccountMembers(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if _, _, ok := server.authorizeAccount(ctx, uri.AccountID, viewAccountRoles...); !ok {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, uri.AccountID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, members)
+}
+
+// Remove Account Member
+
+type removeAccountMemberURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+	UserID    int64 `uri:"user_id" binding:"required,min=1"`
+}
+
+// removeAccountMember lets the owner remove any other member, and any other member leave the account
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var uri removeAccountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if user.ID != uri.UserID && !server.isAccountMemberWithRole(ctx, uri.AccountID, user.ID, manageAccountRoles) {
+		return
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: uri.AccountID,
+		UserID:    uri.UserID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errAccountMemberNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	if member.Role == db.AccountRoleOwner {
+		abortWithError(ctx, forbiddenError("the owner cannot be removed from the account"))
+		return
+	}
+
+	err = server.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{
+		AccountID: uri.AccountID,
+		UserID:    uri.UserID,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
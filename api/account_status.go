@@ -0,0 +1,85 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+type accountStatusURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type changeAccountStatusRequest struct {
+	Status         string `json:"status" binding:"required,oneof=active frozen closed"`
+	Reason         string `json:"reason" binding:"max=200"`
+	SweepAccountID int64  `json:"sweep_account_id" binding:"omitempty,min=1"`
+}
+
+// Change Account Status (owner)
+
+func (server *Server) changeAccountStatus(ctx *gin.Context) {
+	var uri accountStatusURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req changeAccountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	_, owner, ok := server.authorizeAccount(ctx, uri.AccountID, manageAccountRoles...)
+	if !ok {
+		return
+	}
+
+	server.runChangeAccountStatus(ctx, uri.AccountID, req, owner, db.AccountStatusChangeByOwner)
+}
+
+// Change Account Status (admin)
+
+func (server *Server) adminChangeAccountStatus(ctx *gin.Context) {
+	var uri accountStatusURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req changeAccountStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	admin := ctx.MustGet(authorizationUserKey).(db.User)
+
+	server.runChangeAccountStatus(ctx, uri.AccountID, req, admin, db.AccountStatusChangeByAdmin)
+}
+
+func (server *Server) runChangeAccountStatus(ctx *gin.Context, accountID int64, req changeAccountStatusRequest, actor db.User, actorRole string) {
+	arg := db.ChangeAccountStatusTxParams{
+		AccountID:      accountID,
+		Status:         req.Status,
+		ChangedBy:      actor.ID,
+		ActorRole:      actorRole,
+		Reason:         req.Reason,
+		SweepAccountID: req.SweepAccountID,
+	}
+
+	result, err := server.store.ChangeAccountStatusTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = errAccountNotFound
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
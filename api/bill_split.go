@@ -0,0 +1,208 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/split"
+	"github.com/khorsl/simple_bank/token"
+)
+
+var (
+	errBillSplitNotFound  = newAPIError(http.StatusNotFound, "bill_split_not_found", "bill split not found")
+	errNoOtherParticipant = newAPIError(http.StatusBadRequest, codeInvalidRequest, "a split needs at least one participant other than its creator")
+	// errUnknownParticipant does not name the participant, so splits cannot be used to probe usernames
+	errUnknownParticipant = newAPIError(http.StatusBadRequest, codeInvalidRequest, "every participant must be a registered user")
+)
+
+type billSplitResponse struct {
+	db.BillSplit
+	Participants []db.ListBillSplitSharesRow `json:"participants"`
+	// Collected is what the participants have paid so far, Outstanding what is still pending
+	Collected   int64 `json:"collected"`
+	Outstanding int64 `json:"outstanding"`
+}
+
+// Create Bill Split
+
+type billSplitParticipant struct {
+	Username string `json:"username" binding:"required,alphanum"`
+	// PercentageBps is only used by percentage splits and Amount by exact splits
+	PercentageBps int64 `json:"percentage_bps" binding:"gte=0,lte=10000"`
+	Amount        int64 `json:"amount" binding:"gte=0"`
+}
+
+type createBillSplitRequest struct {
+	ToAccountID  int64                  `json:"to_account_id" binding:"required,min=1"`
+	Amount       int64                  `json:"amount" binding:"required,gt=0"`
+	Currency     string                 `json:"currency" binding:"required,currency"`
+	Memo         string                 `json:"memo" binding:"max=140"`
+	Method       string                 `json:"method" binding:"required,oneof=equal percentage exact"`
+	Participants []billSplitParticipant `json:"participants" binding:"required,min=1,max=20,dive"`
+}
+
+// createBillSplit divides the amount between the participants and requests every share that is not
+// the creator's. The minor units left over by rounding are never requested, the creator absorbs them.
+func (server *Server) createBillSplit(ctx *gin.Context) {
+	var req createBillSplitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, user, ok := server.authorizeAccount(ctx, req.ToAccountID, transferAccountRoles...)
+	if !ok {
+		return
+	}
+
+	if account.Status != db.AccountStatusActive {
+		abortWithError(ctx, &db.AccountNotActiveError{AccountID: account.ID, Status: account.Status})
+		return
+	}
+
+	if account.Currency != req.Currency {
+		abortWithError(ctx, currencyMismatchError(account.ID, account.Currency, req.Currency))
+		return
+	}
+
+	seen := make(map[string]bool, len(req.Participants))
+	weights := make([]int64, len(req.Participants))
+	for i, participant := range req.Participants {
+		if seen[participant.Username] {
+			message := fmt.Sprintf("participant %s is listed more than once", participant.Username)
+			abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInvalidRequest, message))
+			return
+		}
+		seen[participant.Username] = true
+
+		switch req.Method {
+		case split.Percentage:
+			weights[i] = participant.PercentageBps
+		case split.Exact:
+			weights[i] = participant.Amount
+		}
+	}
+
+	shares, remainder, err := split.Divide(req.Amount, req.Method, weights)
+	if err != nil {
+		abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInvalidRequest, err.Error()))
+		return
+	}
+
+	arg := db.CreateBillSplitTxParams{
+		CreatorID:    user.ID,
+		ToAccountID:  account.ID,
+		Amount:       req.Amount,
+		Currency:     req.Currency,
+		Memo:         req.Memo,
+		Method:       req.Method,
+		CreatorShare: remainder,
+		ExpiresAt:    time.Now().Add(server.config.MoneyRequestTTL),
+	}
+
+	for i, participant := range req.Participants {
+		if participant.Username == user.Username {
+			arg.CreatorShare += shares[i]
+			continue
+		}
+
+		participantUser, err := server.store.GetUserByUsername(ctx, participant.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				abortWithError(ctx, errUnknownParticipant)
+				return
+			}
+
+			abortWithError(ctx, err)
+			return
+		}
+
+		arg.Shares = append(arg.Shares, db.BillSplitShareParams{
+			UserID: participantUser.ID,
+			Amount: shares[i],
+		})
+	}
+
+	if len(arg.Shares) == 0 {
+		abortWithError(ctx, errNoOtherParticipant)
+		return
+	}
+
+	result, err := server.store.CreateBillSplitTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Get Bill Split
+
+type billSplitURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getBillSplit shows who has paid their share. Only the creator and the participants can see a split.
+func (server *Server) getBillSplit(ctx *gin.Context) {
+	var uri billSplitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	billSplit, err := server.store.GetBillSplit(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errBillSplitNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	participants, err := server.store.ListBillSplitShares(ctx, billSplit.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	response := billSplitResponse{
+		BillSplit:    billSplit,
+		Participants: participants,
+	}
+
+	isParticipant := billSplit.CreatorID == user.ID
+	for _, participant := range participants {
+		if participant.UserID == user.ID {
+			isParticipant = true
+		}
+
+		switch participant.Status {
+		case db.MoneyRequestStatusPaid:
+			response.Collected += participant.Amount
+		case db.MoneyRequestStatusPending:
+			response.Outstanding += participant.Amount
+		}
+	}
+
+	if !isParticipant {
+		abortWithError(ctx, errBillSplitNotFound)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
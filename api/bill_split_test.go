@@ -0,0 +1,317 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBillSplitAPI(t *testing.T) {
+	creator, _ := randomUser(t)
+	friend1, _ := randomUser(t)
+	friend2, _ := randomUser(t)
+	account := randomAccount(creator.ID)
+	account.Currency = util.USD
+	member := newAccountMember(account.ID, creator.ID, db.AccountRoleOwner)
+
+	authorize := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(creator.Username)).Times(1).Return(creator, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+	}
+
+	friends := func(store *mockdb.MockStore) {
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(friend1.Username)).Times(1).Return(friend1, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(friend2.Username)).Times(1).Return(friend2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Equal",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        10_000,
+				"currency":      account.Currency,
+				"memo":          "Dinner",
+				"method":        "equal",
+				"participants": []gin.H{
+					{"username": creator.Username},
+					{"username": friend1.Username},
+					{"username": friend2.Username},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				friends(store)
+				store.EXPECT().
+					CreateBillSplitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateBillSplitTxParams) (db.CreateBillSplitTxResult, error) {
+						// the creator keeps their own share and the minor unit left over
+						require.Equal(t, int64(3334), arg.CreatorShare)
+						require.Equal(t, []db.BillSplitShareParams{
+							{UserID: friend1.ID, Amount: 3333},
+							{UserID: friend2.ID, Amount: 3333},
+						}, arg.Shares)
+						return db.CreateBillSplitTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Percentage",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        999,
+				"currency":      account.Currency,
+				"method":        "percentage",
+				"participants": []gin.H{
+					{"username": friend1.Username, "percentage_bps": 5000},
+					{"username": friend2.Username, "percentage_bps": 5000},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				friends(store)
+				store.EXPECT().
+					CreateBillSplitTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateBillSplitTxParams) (db.CreateBillSplitTxResult, error) {
+						require.Equal(t, int64(1), arg.CreatorShare)
+						require.Equal(t, int64(499), arg.Shares[0].Amount)
+						require.Equal(t, int64(499), arg.Shares[1].Amount)
+						return db.CreateBillSplitTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ExactAmountsMismatch",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        1000,
+				"currency":      account.Currency,
+				"method":        "exact",
+				"participants": []gin.H{
+					{"username": friend1.Username, "amount": 600},
+					{"username": friend2.Username, "amount": 300},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().CreateBillSplitTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "OnlyCreator",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        1000,
+				"currency":      account.Currency,
+				"method":        "equal",
+				"participants": []gin.H{
+					{"username": creator.Username},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().CreateBillSplitTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DuplicateParticipant",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        1000,
+				"currency":      account.Currency,
+				"method":        "equal",
+				"participants": []gin.H{
+					{"username": friend1.Username},
+					{"username": friend1.Username},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().CreateBillSplitTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ParticipantNotFound",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        1000,
+				"currency":      account.Currency,
+				"method":        "equal",
+				"participants": []gin.H{
+					{"username": friend1.Username},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(friend1.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateBillSplitTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				// the error does not say which username is unknown
+				apiErr := requireErrorBody(t, recorder.Body, codeInvalidRequest)
+				require.NotContains(t, apiErr.Message, friend1.Username)
+			},
+		},
+		{
+			name: "UnsupportedMethod",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        1000,
+				"currency":      account.Currency,
+				"method":        "weighted",
+				"participants": []gin.H{
+					{"username": friend1.Username},
+				},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateBillSplitTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.MoneyRequestTTL = time.Hour
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/bill-splits", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, creator.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetBillSplitAPI(t *testing.T) {
+	creator, _ := randomUser(t)
+	friend, _ := randomUser(t)
+	stranger, _ := randomUser(t)
+
+	billSplit := db.BillSplit{
+		ID:           1,
+		CreatorID:    creator.ID,
+		Amount:       1000,
+		Method:       "equal",
+		CreatorShare: 334,
+	}
+	participants := []db.ListBillSplitSharesRow{
+		{SplitID: billSplit.ID, UserID: friend.ID, Username: friend.Username, Amount: 333, Status: db.MoneyRequestStatusPaid},
+		{SplitID: billSplit.ID, UserID: stranger.ID + 1000, Amount: 333, Status: db.MoneyRequestStatusPending},
+	}
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Creator",
+			user: creator,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response billSplitResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, int64(333), response.Collected)
+				require.Equal(t, int64(333), response.Outstanding)
+			},
+		},
+		{
+			name: "Participant",
+			user: friend,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Stranger",
+			user: stranger,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			store.EXPECT().GetBillSplit(gomock.Any(), gomock.Eq(billSplit.ID)).Times(1).Return(billSplit, nil)
+			store.EXPECT().ListBillSplitShares(gomock.Any(), gomock.Eq(billSplit.ID)).Times(1).Return(participants, nil)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/bill-splits/%d", billSplit.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
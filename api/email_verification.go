@@ -0,0 +1,94 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/mail"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+)
+
+var errEmailAlreadyVerified = newAPIError(http.StatusConflict, "email_already_verified", "the email address is already verified")
+
+// issueEmailToken stores the hash of a new single use token of the user and returns the token to
+// put in the email
+func (server *Server) issueEmailToken(ctx context.Context, user db.User, purpose string, duration time.Duration) (string, error) {
+	emailToken, err := util.RandomEmailToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = server.store.IssueUserTokenTx(ctx, db.CreateUserTokenParams{
+		UserID:      user.ID,
+		Purpose:     purpose,
+		HashedToken: util.HashToken(emailToken),
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(duration),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return emailToken, nil
+}
+
+func (server *Server) sendVerificationEmail(ctx context.Context, user db.User) error {
+	duration := server.config.EmailVerificationTokenDuration
+
+	emailToken, err := server.issueEmailToken(ctx, user, db.UserTokenPurposeVerifyEmail, duration)
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(ctx, mail.NewVerificationEmail(server.config.AppURL, user.Email, user.FullName, emailToken, duration))
+}
+
+// Resend Verification Email
+
+func (server *Server) resendVerificationEmail(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !user.EmailVerifiedAt.IsZero() {
+		abortWithError(ctx, errEmailAlreadyVerified)
+		return
+	}
+
+	if err := server.sendVerificationEmail(ctx, user); err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// Verify Email
+
+type emailTokenRequest struct {
+	Token string `json:"token" binding:"required,max=64"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req emailTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	user, err := server.store.VerifyEmailTx(ctx, util.HashToken(req.Token))
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserReponse(user))
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/lib/pq"
+)
+
+// Error codes are part of the API, clients branch on them. Messages may change, codes may not.
+const (
+	codeInvalidRequest          = "invalid_request"
+	codeUnauthenticated         = "unauthenticated"
+	codeForbidden               = "forbidden"
+	codeNotFound                = "not_found"
+	codeAlreadyExists           = "already_exists"
+	codeInvalidReference        = "invalid_reference"
+	codeConflict                = "conflict"
+	codeInternal                = "internal_error"
+	codeAccountNotFound         = "account_not_found"
+	codeUserNotFound            = "user_not_found"
+	codeCurrencyMismatch        = "currency_mismatch"
+	codeInsufficientFunds       = "insufficient_funds"
+	codeLimitExceeded           = "limit_exceeded"
+	codeCoolingOffLimitExceeded = "cooling_off_limit_exceeded"
+	codeAccountNotActive        = "account_not_active"
+	codeAccountLimitReached     = "account_limit_reached"
+	codeRiskBlocked             = "risk_blocked"
+	codeApprovalRequired        = "approval_required"
+)
+
+// apiError is the body of every error response. Message is safe to show to users, it never
+// carries the text of a database or driver error.
+type apiError struct {
+	status  int
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Fields  []fieldError `json:"fields,omitempty"`
+	Details gin.H        `json:"details,omitempty"`
+}
+
+// fieldError points at one field of the request that failed validation
+type fieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func newAPIError(status int, code string, message string) *apiError {
+	return &apiError{status: status, Code: code, Message: message}
+}
+
+func (err *apiError) Error() string {
+	return err.Message
+}
+
+var (
+	errInternal        = newAPIError(http.StatusInternalServerError, codeInternal, "internal server error")
+	errNotFound        = newAPIError(http.StatusNotFound, codeNotFound, "resource not found")
+	errAccountNotFound = newAPIError(http.StatusNotFound, codeAccountNotFound, "account not found")
+	errUserNotFound    = newAPIError(http.StatusNotFound, codeUserNotFound, "user not found")
+	errAlreadyExists   = newAPIError(http.StatusConflict, codeAlreadyExists, "resource already exists")
+	errInvalidRef      = newAPIError(http.StatusBadRequest, codeInvalidReference, "a referenced resource does not exist")
+)
+
+func unauthenticatedError(message string) *apiError {
+	return newAPIError(http.StatusUnauthorized, codeUnauthenticated, message)
+}
+
+func forbiddenError(message string) *apiError {
+	return newAPIError(http.StatusForbidden, codeForbidden, message)
+}
+
+func conflictError(message string) *apiError {
+	return newAPIError(http.StatusConflict, codeConflict, message)
+}
+
+func currencyMismatchError(accountID int64, accountCurrency string, currency string) *apiError {
+	message := fmt.Sprintf("account [%d] currency mismatch: %s vs %s", accountID, accountCurrency, currency)
+	return newAPIError(http.StatusBadRequest, codeCurrencyMismatch, message)
+}
+
+// bindingError turns an error of ShouldBindJSON, ShouldBindUri or ShouldBindQuery into a 400 that
+// lists the offending fields
+func bindingError(err error) *apiError {
+	apiErr := newAPIError(http.StatusBadRequest, codeInvalidRequest, "request is not valid")
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var numErr *strconv.NumError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fieldErr := range validationErrs {
+			apiErr.Fields = append(apiErr.Fields, fieldError{
+				Field:   fieldErr.Field(),
+				Rule:    fieldErr.Tag(),
+				Message: validationMessage(fieldErr),
+			})
+		}
+	case errors.As(err, &typeErr):
+		apiErr.Fields = append(apiErr.Fields, fieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Message: fmt.Sprintf("must be a %s", typeErr.Type.Kind()),
+		})
+	case errors.As(err, &syntaxErr):
+		apiErr.Message = "request body is not valid JSON"
+	case errors.As(err, &numErr):
+		apiErr.Message = "request has a parameter that is not a number"
+	}
+
+	return apiErr
+}
+
+// requestFieldName makes validation errors name fields the way clients send them
+func requestFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "uri", "form"} {
+		name := strings.SplitN(field.Tag.Get(key), ",", 2)[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func validationMessage(err validator.FieldError) string {
+	switch err.Tag() {
+	case "required", "required_without_all":
+		return "is required"
+	case "min", "gte":
+		return fmt.Sprintf("must be at least %s", err.Param())
+	case "max", "lte":
+		return fmt.Sprintf("must be at most %s", err.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", err.Param())
+	case "len":
+		return fmt.Sprintf("must have a length of %s", err.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", err.Param())
+	case "email":
+		return "must be a valid email address"
+	case "alphanum":
+		return "must contain only letters and digits"
+	case "currency":
+		return "is not a supported currency"
+	case "account_type":
+		return "is not a supported account type"
+	case "excluded_with":
+		return fmt.Sprintf("cannot be combined with %s", err.Param())
+	default:
+		return fmt.Sprintf("failed the %s check", err.Tag())
+	}
+}
+
+// toAPIError is the one place that maps domain and driver errors to responses. Errors it does not
+// know are reported as internal errors so their text stays in the logs.
+func toAPIError(err error) *apiError {
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return errNotFound
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return errAlreadyExists
+		case "foreign_key_violation":
+			return errInvalidRef
+		}
+		return errInternal
+	}
+
+	var limitErr *db.LimitExceededError
+	if errors.As(err, &limitErr) {
+		apiErr := newAPIError(http.StatusBadRequest, codeLimitExceeded, limitErr.Error())
+		apiErr.Details = gin.H{
+			"limit":               limitErr.Limit,
+			"currency":            limitErr.Currency,
+			"remaining_allowance": limitErr.Remaining,
+		}
+		return apiErr
+	}
+
+	var coolingOffErr *db.CoolingOffLimitError
+	if errors.As(err, &coolingOffErr) {
+		apiErr := newAPIError(http.StatusBadRequest, codeCoolingOffLimitExceeded, coolingOffErr.Error())
+		apiErr.Details = gin.H{
+			"payee_id":            coolingOffErr.PayeeID,
+			"account_id":          coolingOffErr.AccountID,
+			"remaining_allowance": coolingOffErr.Remaining,
+		}
+		return apiErr
+	}
+
+	var notActiveErr *db.AccountNotActiveError
+	if errors.As(err, &notActiveErr) {
+		return newAPIError(http.StatusForbidden, codeAccountNotActive, notActiveErr.Error())
+	}
+
+	var accountLimitErr *db.AccountLimitError
+	if errors.As(err, &accountLimitErr) {
+		return newAPIError(http.StatusForbidden, codeAccountLimitReached, accountLimitErr.Error())
+	}
+
+	for _, domainErr := range domainErrors {
+		if errors.Is(err, domainErr.err) {
+			return newAPIError(domainErr.status, domainErr.code, domainErr.err.Error())
+		}
+	}
+
+	return errInternal
+}
+
+// domainErrors are the sentinel errors of the store, their messages are written for users
+var domainErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{db.ErrInsufficientFunds, http.StatusBadRequest, codeInsufficientFunds},
+	{db.ErrInsufficientPocketFunds, http.StatusBadRequest, "insufficient_pocket_funds"},
+	{db.ErrAccountHasPocketFunds, http.StatusBadRequest, "account_has_pocket_funds"},
+	{db.ErrInvalidStatusTransition, http.StatusConflict, "invalid_status_transition"},
+	{db.ErrFrozenByAdmin, http.StatusForbidden, "frozen_by_admin"},
+	{db.ErrAccountHasHeldFunds, http.StatusBadRequest, "account_has_held_funds"},
+	{db.ErrNonZeroBalance, http.StatusBadRequest, "non_zero_balance"},
+	{db.ErrInvalidSweepAccount, http.StatusBadRequest, "invalid_sweep_account"},
+	{db.ErrTransferNotPending, http.StatusConflict, "transfer_not_pending"},
+	{db.ErrNotApprover, http.StatusForbidden, "not_approver"},
+	{db.ErrTransferNotAwaitingApproval, http.StatusConflict, "transfer_not_awaiting_approval"},
+	{db.ErrApprovalExpired, http.StatusConflict, "approval_expired"},
+	{db.ErrMoneyRequestNotPending, http.StatusConflict, "money_request_not_pending"},
+	{db.ErrMoneyRequestExpired, http.StatusConflict, "money_request_expired"},
+	{db.ErrNotMoneyRequestPayer, http.StatusForbidden, "not_money_request_payer"},
+	{db.ErrInstallmentAlreadyPaid, http.StatusConflict, "installment_already_paid"},
+	{db.ErrInterestAlreadyPosted, http.StatusConflict, "interest_already_posted"},
+	{db.ErrInvalidEscrowTransition, http.StatusConflict, "invalid_escrow_transition"},
+	{db.ErrEscrowStatusChanged, http.StatusConflict, "escrow_status_changed"},
+	{db.ErrEscrowNotDue, http.StatusConflict, "escrow_not_due"},
+	{db.ErrEscrowDisputeClosed, http.StatusConflict, "escrow_dispute_closed"},
+	{db.ErrInvalidUserToken, http.StatusBadRequest, "invalid_token"},
+}
+
+// abortWithError writes the error response and stops the handler chain. Internal errors are logged
+// with the request id so a report from a client can be traced.
+func abortWithError(ctx *gin.Context, err error) {
+	apiErr := toAPIError(err)
+	requestID := ctx.GetString(requestIDKey)
+
+	if apiErr.status == http.StatusInternalServerError {
+		log.Printf("request %s: %s %s: %v", requestID, ctx.Request.Method, ctx.FullPath(), err)
+	}
+
+	ctx.AbortWithStatusJSON(apiErr.status, gin.H{
+		"error":      apiErr,
+		"request_id": requestID,
+	})
+}
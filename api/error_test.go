@@ -0,0 +1,202 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type errorBody struct {
+	Error     apiError `json:"error"`
+	RequestID string   `json:"request_id"`
+}
+
+// requireErrorBody decodes an error response and checks its code
+func requireErrorBody(t *testing.T, body *bytes.Buffer, code string) apiError {
+	var response errorBody
+	err := json.Unmarshal(body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Equal(t, code, response.Error.Code)
+	require.NotEmpty(t, response.Error.Message)
+	require.NotEmpty(t, response.RequestID)
+
+	return response.Error
+}
+
+func TestToAPIError(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:    "NoRows",
+			err:     sql.ErrNoRows,
+			status:  http.StatusNotFound,
+			code:    codeNotFound,
+			message: "resource not found",
+		},
+		{
+			name:    "UniqueViolation",
+			err:     &pq.Error{Code: "23505", Message: `duplicate key value violates unique constraint "users_pkey"`},
+			status:  http.StatusConflict,
+			code:    codeAlreadyExists,
+			message: "resource already exists",
+		},
+		{
+			name:    "ForeignKeyViolation",
+			err:     &pq.Error{Code: "23503", Message: `insert or update on table "accounts" violates foreign key constraint`},
+			status:  http.StatusBadRequest,
+			code:    codeInvalidReference,
+			message: "a referenced resource does not exist",
+		},
+		{
+			name:    "OtherDriverError",
+			err:     &pq.Error{Code: "42P01", Message: `relation "accounts" does not exist`},
+			status:  http.StatusInternalServerError,
+			code:    codeInternal,
+			message: "internal server error",
+		},
+		{
+			name:    "WrappedDomainError",
+			err:     fmt.Errorf("transfer tx: %w", db.ErrInsufficientFunds),
+			status:  http.StatusBadRequest,
+			code:    codeInsufficientFunds,
+			message: db.ErrInsufficientFunds.Error(),
+		},
+		{
+			name:    "AccountNotActive",
+			err:     &db.AccountNotActiveError{AccountID: 1, Status: db.AccountStatusFrozen},
+			status:  http.StatusForbidden,
+			code:    codeAccountNotActive,
+			message: (&db.AccountNotActiveError{AccountID: 1, Status: db.AccountStatusFrozen}).Error(),
+		},
+		{
+			name:    "APIError",
+			err:     errAccountNotFound,
+			status:  http.StatusNotFound,
+			code:    codeAccountNotFound,
+			message: "account not found",
+		},
+		{
+			name:    "Unknown",
+			err:     sql.ErrConnDone,
+			status:  http.StatusInternalServerError,
+			code:    codeInternal,
+			message: "internal server error",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := toAPIError(tc.err)
+			require.Equal(t, tc.status, apiErr.status)
+			require.Equal(t, tc.code, apiErr.Code)
+			require.Equal(t, tc.message, apiErr.Message)
+		})
+	}
+}
+
+func TestErrorResponse(t *testing.T) {
+	testCases := []struct {
+		name          string
+		body          string
+		requestID     string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "InvalidFields",
+			body:      `{"username": "not-alphanum", "password": "123"}`,
+			requestID: "client-request-id",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, "client-request-id", recorder.Header().Get(requestIDHeaderKey))
+
+				apiErr := requireErrorBody(t, recorder.Body, codeInvalidRequest)
+				require.Equal(t, []fieldError{
+					{Field: "username", Rule: "alphanum", Message: "must contain only letters and digits"},
+					{Field: "password", Rule: "min", Message: "must be at least 6"},
+				}, apiErr.Fields)
+			},
+		},
+		{
+			name: "InvalidJSON",
+			body: `{"username": 42}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				apiErr := requireErrorBody(t, recorder.Body, codeInvalidRequest)
+				require.Len(t, apiErr.Fields, 1)
+				require.Equal(t, "username", apiErr.Fields[0].Field)
+				require.Equal(t, "type", apiErr.Fields[0].Rule)
+			},
+		},
+		{
+			name: "InternalError",
+			body: `{"username": "alice", "password": "secret"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetUserByUsername(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, &pq.Error{Code: "08006", Message: "connection to 10.0.0.5 failed"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "10.0.0.5")
+
+				requireErrorBody(t, recorder.Body, codeInternal)
+
+				// a generated id is returned in both the header and the body
+				var body errorBody
+				err := json.Unmarshal(recorder.Body.Bytes(), &body)
+				require.NoError(t, err)
+				require.Equal(t, recorder.Header().Get(requestIDHeaderKey), body.RequestID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
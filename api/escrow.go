@@ -0,0 +1,266 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
+)
+
+var errEscrowNotFound = newAPIError(http.StatusNotFound, "escrow_not_found", "escrow not found")
+
+// escrowResponse leaves out the escrow account side of the funding transfer, it is internal to the bank
+type escrowResponse struct {
+	Escrow      db.Escrow   `json:"escrow"`
+	Transfer    db.Transfer `json:"transfer"`
+	FromAccount db.Account  `json:"from_account"`
+	FromEntry   db.Entry    `json:"from_entry"`
+}
+
+// Create Escrow
+
+type createEscrowRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Currency      string `json:"currency" binding:"required,currency"`
+	Description   string `json:"description" binding:"max=200"`
+	// ReleaseAfterHours is how long the buyer has to confirm or dispute before the funds go to the seller
+	ReleaseAfterHours int64 `json:"release_after_hours" binding:"required,min=1,max=2160"`
+	// MFACode is required when the amount is above the step-up threshold
+	MFACode string `json:"mfa_code" binding:"omitempty,numeric,len=6"`
+}
+
+// createEscrow moves the amount from the buyer account into escrow. Like money requests, an escrow
+// is funded right away or refused, it never waits for review or approval.
+func (server *Server) createEscrow(ctx *gin.Context) {
+	var req createEscrowRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if req.FromAccountID == req.ToAccountID {
+		abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInvalidRequest, "buyer and seller accounts must be different"))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	assessment, payee, ok := server.checkImmediateTransfer(ctx, user, transferRequest{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		MFACode:       req.MFACode,
+	})
+	if !ok {
+		return
+	}
+
+	result, err := server.store.CreateEscrowTx(ctx, db.CreateEscrowTxParams{
+		BuyerID:         user.ID,
+		BuyerAccountID:  req.FromAccountID,
+		SellerAccountID: req.ToAccountID,
+		Amount:          req.Amount,
+		Description:     req.Description,
+		ReleaseAt:       time.Now().Add(time.Duration(req.ReleaseAfterHours) * time.Hour),
+		RiskDecision:    string(assessment.Decision),
+		MatchedRules:    assessment.MatchedRules,
+		Payee:           payee,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, escrowResponse{
+		Escrow:      result.Escrow,
+		Transfer:    result.Funding.Transfer,
+		FromAccount: result.Funding.FromAccount,
+		FromEntry:   result.Funding.FromEntry,
+	})
+}
+
+// Get Escrow
+
+type escrowURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getEscrowForUser loads the escrow of the uri for the authenticated user. Escrows the user is not
+// a party to are reported as not found.
+func (server *Server) getEscrowForUser(ctx *gin.Context) (db.Escrow, db.User, bool) {
+	var uri escrowURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return db.Escrow{}, db.User{}, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.Escrow{}, db.User{}, false
+	}
+
+	escrow, err := server.store.GetEscrow(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errEscrowNotFound)
+			return db.Escrow{}, db.User{}, false
+		}
+
+		abortWithError(ctx, err)
+		return db.Escrow{}, db.User{}, false
+	}
+
+	return escrow, user, true
+}
+
+// getEscrow shows an escrow to its buyer and to the members of the seller account
+func (server *Server) getEscrow(ctx *gin.Context) {
+	escrow, user, ok := server.getEscrowForUser(ctx)
+	if !ok {
+		return
+	}
+
+	if escrow.BuyerID != user.ID {
+		member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+			AccountID: escrow.SellerAccountID,
+			UserID:    user.ID,
+		})
+		if err != nil && err != sql.ErrNoRows {
+			abortWithError(ctx, err)
+			return
+		}
+
+		if err == sql.ErrNoRows || member.Status != db.MemberStatusActive {
+			abortWithError(ctx, errEscrowNotFound)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, escrow)
+}
+
+// Release Escrow
+
+// releaseEscrow is the buyer confirming the purchase, the held funds are paid to the seller
+func (server *Server) releaseEscrow(ctx *gin.Context) {
+	escrow, user, ok := server.getEscrowForUser(ctx)
+	if !ok {
+		return
+	}
+
+	if escrow.BuyerID != user.ID {
+		abortWithError(ctx, errEscrowNotFound)
+		return
+	}
+
+	server.changeEscrowStatus(ctx, db.ChangeEscrowStatusTxParams{
+		EscrowID:   escrow.ID,
+		FromStatus: escrow.Status,
+		Status:     db.EscrowStatusReleased,
+		ChangedBy:  user.ID,
+	})
+}
+
+// Dispute Escrow
+
+type disputeEscrowRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// disputeEscrow stops the automatic release, the funds stay held until an admin resolves the dispute
+func (server *Server) disputeEscrow(ctx *gin.Context) {
+	var req disputeEscrowRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	escrow, user, ok := server.getEscrowForUser(ctx)
+	if !ok {
+		return
+	}
+
+	if escrow.BuyerID != user.ID {
+		abortWithError(ctx, errEscrowNotFound)
+		return
+	}
+
+	server.changeEscrowStatus(ctx, db.ChangeEscrowStatusTxParams{
+		EscrowID:      escrow.ID,
+		FromStatus:    escrow.Status,
+		Status:        db.EscrowStatusDisputed,
+		ChangedBy:     user.ID,
+		DisputeReason: req.Reason,
+	})
+}
+
+// Resolve Escrow Dispute
+
+type resolveEscrowRequest struct {
+	// Outcome is the party the dispute is resolved for
+	Outcome string `json:"outcome" binding:"required,oneof=buyer seller"`
+}
+
+// resolveEscrow settles a disputed escrow, refunding the buyer or releasing the funds to the seller
+func (server *Server) resolveEscrow(ctx *gin.Context) {
+	var uri escrowURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req resolveEscrowRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	admin := ctx.MustGet(authorizationUserKey).(db.User)
+
+	status := db.EscrowStatusReleased
+	if req.Outcome == "buyer" {
+		status = db.EscrowStatusRefunded
+	}
+
+	server.changeEscrowStatus(ctx, db.ChangeEscrowStatusTxParams{
+		EscrowID:   uri.ID,
+		FromStatus: db.EscrowStatusDisputed,
+		Status:     status,
+		ChangedBy:  admin.ID,
+	})
+}
+
+func (server *Server) changeEscrowStatus(ctx *gin.Context, arg db.ChangeEscrowStatusTxParams) {
+	result, err := server.store.ChangeEscrowStatusTx(ctx, arg)
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			err = errEscrowNotFound
+		case errors.Is(err, db.ErrEscrowStatusChanged):
+			message := fmt.Sprintf("escrow is no longer %s", arg.FromStatus)
+			err = newAPIError(http.StatusConflict, "escrow_status_changed", message)
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result.Escrow)
+}
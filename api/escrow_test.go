@@ -0,0 +1,535 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/risk"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomEscrow(buyer db.User, buyerAccount db.Account, sellerAccount db.Account) db.Escrow {
+	return db.Escrow{
+		ID:              util.RandomInt(1, 1000),
+		BuyerID:         buyer.ID,
+		BuyerAccountID:  buyerAccount.ID,
+		SellerAccountID: sellerAccount.ID,
+		Amount:          10,
+		Currency:        buyerAccount.Currency,
+		Description:     "Vintage camera",
+		Status:          db.EscrowStatusHeld,
+		ReleaseAt:       time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateEscrowAPI(t *testing.T) {
+	buyer, _ := randomUser(t)
+	seller, _ := randomUser(t)
+
+	fromAccount := randomAccount(buyer.ID)
+	toAccount := randomAccount(seller.ID)
+	fromAccount.Currency = util.USD
+	toAccount.Currency = util.USD
+	fromAccount.Balance = 100
+
+	// amounts above the threshold need an authentication code, like transfers
+	stepUpThreshold := int64(50)
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{UserID: buyer.ID, Secret: secret, Status: db.MFAStatusEnabled}
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+
+	checkedTransfer := func(store *mockdb.MockStore) {
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(buyer.Username)).Times(1).Return(buyer, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(3).Return(fromAccount, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(fromAccount.ID, buyer.ID, db.AccountRoleOwner), nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	}
+
+	validTransfer := func(store *mockdb.MockStore) {
+		checkedTransfer(store)
+		store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"from_account_id":     fromAccount.ID,
+				"to_account_id":       toAccount.ID,
+				"amount":              10,
+				"currency":            util.USD,
+				"description":         "Vintage camera",
+				"release_after_hours": 72,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().
+					CreateEscrowTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateEscrowTxParams) (db.CreateEscrowTxResult, error) {
+						require.Equal(t, buyer.ID, arg.BuyerID)
+						require.Equal(t, fromAccount.ID, arg.BuyerAccountID)
+						require.Equal(t, toAccount.ID, arg.SellerAccountID)
+						require.Equal(t, string(risk.Allow), arg.RiskDecision)
+						require.WithinDuration(t, time.Now().Add(72*time.Hour), arg.ReleaseAt, time.Minute)
+						return db.CreateEscrowTxResult{
+							Escrow:  randomEscrow(buyer, fromAccount, toAccount),
+							Funding: db.TransferTxResult{FromAccount: fromAccount},
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotContains(t, response, "to_account")
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{
+				"from_account_id":     fromAccount.ID,
+				"to_account_id":       toAccount.ID,
+				"amount":              10,
+				"currency":            util.USD,
+				"release_after_hours": 72,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CreateEscrowTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "StepUpOK",
+			body: gin.H{
+				"from_account_id":     fromAccount.ID,
+				"to_account_id":       toAccount.ID,
+				"amount":              60,
+				"currency":            util.USD,
+				"release_after_hours": 72,
+				"mfa_code":            code,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(buyer.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(1).Return(mfa, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CreateEscrowTxResult{
+					Escrow:  randomEscrow(buyer, fromAccount, toAccount),
+					Funding: db.TransferTxResult{FromAccount: fromAccount},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "StepUpCodeRequired",
+			body: gin.H{
+				"from_account_id":     fromAccount.ID,
+				"to_account_id":       toAccount.ID,
+				"amount":              60,
+				"currency":            util.USD,
+				"release_after_hours": 72,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				checkedTransfer(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(buyer.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFARequired.Code)
+			},
+		},
+		{
+			name: "SameAccount",
+			body: gin.H{
+				"from_account_id":     fromAccount.ID,
+				"to_account_id":       fromAccount.ID,
+				"amount":              10,
+				"currency":            util.USD,
+				"release_after_hours": 72,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ReleaseTooFarAway",
+			body: gin.H{
+				"from_account_id":     fromAccount.ID,
+				"to_account_id":       toAccount.ID,
+				"amount":              10,
+				"currency":            util.USD,
+				"release_after_hours": 24 * 365,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateEscrowTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.StepUpTransferThreshold = stepUpThreshold
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/escrows", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, buyer.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetEscrowAPI(t *testing.T) {
+	buyer, _ := randomUser(t)
+	seller, _ := randomUser(t)
+	stranger, _ := randomUser(t)
+
+	sellerAccount := randomAccount(seller.ID)
+	escrow := randomEscrow(buyer, randomAccount(buyer.ID), sellerAccount)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:       "Buyer",
+			user:       buyer,
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "SellerMember",
+			user: seller,
+			buildStubs: func(store *mockdb.MockStore) {
+				member := newAccountMember(sellerAccount.ID, seller.ID, db.AccountRoleViewer)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Stranger",
+			user: stranger,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			store.EXPECT().GetEscrow(gomock.Any(), gomock.Eq(escrow.ID)).Times(1).Return(escrow, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/escrows/%d", escrow.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestChangeEscrowStatusAPI(t *testing.T) {
+	buyer, _ := randomUser(t)
+	seller, _ := randomUser(t)
+	escrow := randomEscrow(buyer, randomAccount(buyer.ID), randomAccount(seller.ID))
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		action        string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Release",
+			user:   buyer,
+			action: "release",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ChangeEscrowStatusTxParams{
+					EscrowID:   escrow.ID,
+					FromStatus: db.EscrowStatusHeld,
+					Status:     db.EscrowStatusReleased,
+					ChangedBy:  buyer.ID,
+				}
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ChangeEscrowStatusTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Dispute",
+			user:   buyer,
+			action: "dispute",
+			body:   gin.H{"reason": "Item never arrived"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ChangeEscrowStatusTxParams{
+					EscrowID:      escrow.ID,
+					FromStatus:    db.EscrowStatusHeld,
+					Status:        db.EscrowStatusDisputed,
+					ChangedBy:     buyer.ID,
+					DisputeReason: "Item never arrived",
+				}
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ChangeEscrowStatusTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "DisputeAfterReleaseTime",
+			user:   buyer,
+			action: "dispute",
+			body:   gin.H{"reason": "Item never arrived"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ChangeEscrowStatusTxResult{}, db.ErrEscrowDisputeClosed)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyReleased",
+			user:   buyer,
+			action: "release",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ChangeEscrowStatusTxResult{}, db.ErrEscrowStatusChanged)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "SellerCannotRelease",
+			user:   seller,
+			action: "release",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			store.EXPECT().GetEscrow(gomock.Any(), gomock.Eq(escrow.ID)).Times(1).Return(escrow, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/escrows/%d/%s", escrow.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResolveEscrowAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	escrowID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		user          db.User
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ForBuyer",
+			user: admin,
+			body: gin.H{"outcome": "buyer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ChangeEscrowStatusTxParams{
+					EscrowID:   escrowID,
+					FromStatus: db.EscrowStatusDisputed,
+					Status:     db.EscrowStatusRefunded,
+					ChangedBy:  admin.ID,
+				}
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ChangeEscrowStatusTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "ForSeller",
+			user: admin,
+			body: gin.H{"outcome": "seller"},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ChangeEscrowStatusTxParams{
+					EscrowID:   escrowID,
+					FromStatus: db.EscrowStatusDisputed,
+					Status:     db.EscrowStatusReleased,
+					ChangedBy:  admin.ID,
+				}
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.ChangeEscrowStatusTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotDisputed",
+			user: admin,
+			body: gin.H{"outcome": "buyer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ChangeEscrowStatusTxResult{}, db.ErrEscrowStatusChanged)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			user: admin,
+			body: gin.H{"outcome": "buyer"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ChangeEscrowStatusTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidOutcome",
+			user: admin,
+			body: gin.H{"outcome": "split"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			user: user,
+			body: gin.H{"outcome": "seller"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(tc.user.Username)).Times(1).Return(tc.user, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/admin/escrows/%d/resolve", escrowID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+// List Interest Rates
+
+func (server *Server) listInterestRates(ctx *gin.Context) {
+	rates, err := server.store.ListInterestRates(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+// Set Interest Rate (admin)
+
+type setInterestRateRequest struct {
+	Currency      string `json:"currency" binding:"required,currency"`
+	AccountType   string `json:"account_type" binding:"required,account_type"`
+	AnnualRateBps int32  `json:"annual_rate_bps" binding:"min=0,max=10000"`
+}
+
+func (server *Server) setInterestRate(ctx *gin.Context) {
+	var req setInterestRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	rate, err := server.store.UpsertInterestRate(ctx, db.UpsertInterestRateParams{
+		Currency:      req.Currency,
+		AccountType:   req.AccountType,
+		AnnualRateBps: req.AnnualRateBps,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
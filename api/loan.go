@@ -0,0 +1,142 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/interest"
+	"github.com/khorsl/simple_bank/loan"
+)
+
+var errLoanNotFound = newAPIError(http.StatusNotFound, "loan_not_found", "loan not found")
+
+// Originate Loan (admin)
+
+type originateLoanRequest struct {
+	AccountID     int64  `json:"account_id" binding:"required,min=1"`
+	Principal     int64  `json:"principal" binding:"required,gt=0"`
+	AnnualRateBps int32  `json:"annual_rate_bps" binding:"min=0,max=10000"`
+	TermMonths    int32  `json:"term_months" binding:"required,min=1,max=360"`
+	Method        string `json:"method" binding:"required,oneof=annuity equal_principal"`
+}
+
+func (server *Server) originateLoan(ctx *gin.Context) {
+	var req originateLoanRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	schedule, err := loan.Schedule(req.Principal, req.AnnualRateBps, req.TermMonths, req.Method, interest.Date(time.Now()))
+	if err != nil {
+		abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInvalidRequest, err.Error()))
+		return
+	}
+
+	installments := make([]db.LoanInstallmentParams, len(schedule))
+	for i, installment := range schedule {
+		installments[i] = db.LoanInstallmentParams{
+			DueDate:   installment.DueDate,
+			Principal: installment.Principal,
+			Interest:  installment.Interest,
+		}
+	}
+
+	admin := ctx.MustGet(authorizationUserKey).(db.User)
+
+	result, err := server.store.OriginateLoanTx(ctx, db.OriginateLoanTxParams{
+		AccountID:     req.AccountID,
+		Principal:     req.Principal,
+		AnnualRateBps: req.AnnualRateBps,
+		Method:        req.Method,
+		CreatedBy:     admin.ID,
+		Installments:  installments,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errAccountNotFound
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Get Loan
+
+type loanURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type loanResponse struct {
+	db.Loan
+	// NextInstallment is nil once the loan is paid off
+	NextInstallment *db.LoanInstallment `json:"next_installment"`
+}
+
+// authorizeLoan writes the error response and returns false when the loan does not exist or the
+// authenticated user cannot view its account
+func (server *Server) authorizeLoan(ctx *gin.Context) (db.Loan, bool) {
+	var req loanURI
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return db.Loan{}, false
+	}
+
+	l, err := server.store.GetLoan(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errLoanNotFound)
+			return db.Loan{}, false
+		}
+
+		abortWithError(ctx, err)
+		return db.Loan{}, false
+	}
+
+	_, _, ok := server.authorizeAccount(ctx, l.AccountID, viewAccountRoles...)
+	return l, ok
+}
+
+func (server *Server) getLoan(ctx *gin.Context) {
+	l, ok := server.authorizeLoan(ctx)
+	if !ok {
+		return
+	}
+
+	response := loanResponse{Loan: l}
+
+	next, err := server.store.GetNextLoanInstallment(ctx, l.ID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return
+	}
+	if err == nil {
+		response.NextInstallment = &next
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Get Loan Schedule
+
+func (server *Server) getLoanSchedule(ctx *gin.Context) {
+	l, ok := server.authorizeLoan(ctx)
+	if !ok {
+		return
+	}
+
+	installments, err := server.store.ListLoanInstallments(ctx, l.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, installments)
+}
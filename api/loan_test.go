@@ -0,0 +1,340 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/loan"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomLoan(account db.Account) db.Loan {
+	principal := util.RandomInt(1000, 100_000)
+	return db.Loan{
+		ID:                   util.RandomInt(1, 1000),
+		AccountID:            account.ID,
+		BorrowerID:           account.Owner,
+		Currency:             account.Currency,
+		Principal:            principal,
+		OutstandingPrincipal: principal,
+		AnnualRateBps:        1200,
+		TermMonths:           12,
+		Method:               loan.Annuity,
+		Status:               db.LoanStatusActive,
+	}
+}
+
+func TestOriginateLoanAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	account := randomAccount(user.ID)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"account_id":      account.ID,
+				"principal":       100_000,
+				"annual_rate_bps": 1200,
+				"term_months":     12,
+				"method":          loan.Annuity,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().
+					OriginateLoanTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.OriginateLoanTxParams) (db.OriginateLoanTxResult, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, admin.ID, arg.CreatedBy)
+						require.Len(t, arg.Installments, 12)
+
+						var repaid int64
+						for _, installment := range arg.Installments {
+							repaid += installment.Principal
+						}
+						require.Equal(t, int64(100_000), repaid)
+
+						return db.OriginateLoanTxResult{}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			body: gin.H{
+				"account_id":  account.ID,
+				"principal":   100_000,
+				"term_months": 12,
+				"method":      loan.Annuity,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidMethod",
+			body: gin.H{
+				"account_id":  account.ID,
+				"principal":   100_000,
+				"term_months": 12,
+				"method":      "balloon",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: gin.H{
+				"account_id":  account.ID,
+				"principal":   100_000,
+				"term_months": 12,
+				"method":      loan.EqualPrincipal,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().OriginateLoanTx(gomock.Any(), gomock.Any()).Times(1).Return(db.OriginateLoanTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AccountFrozen",
+			body: gin.H{
+				"account_id":  account.ID,
+				"principal":   100_000,
+				"term_months": 12,
+				"method":      loan.EqualPrincipal,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().
+					OriginateLoanTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.OriginateLoanTxResult{}, &db.AccountNotActiveError{AccountID: account.ID, Status: db.AccountStatusFrozen})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/loans", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetLoanAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.ID)
+	l := randomLoan(account)
+
+	next := db.LoanInstallment{
+		ID:        util.RandomInt(1, 1000),
+		LoanID:    l.ID,
+		Seq:       3,
+		Principal: 100,
+		Interest:  10,
+		LateFee:   25,
+		Status:    db.InstallmentStatusOverdue,
+	}
+
+	testCases := []struct {
+		name          string
+		loanID        int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "OK",
+			loanID: l.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Eq(l.ID)).Times(1).Return(l, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetNextLoanInstallment(gomock.Any(), gomock.Eq(l.ID)).Times(1).Return(next, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got loanResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, l.OutstandingPrincipal, got.OutstandingPrincipal)
+				require.NotNil(t, got.NextInstallment)
+				require.Equal(t, next.ID, got.NextInstallment.ID)
+				require.Equal(t, next.LateFee, got.NextInstallment.LateFee)
+			},
+		},
+		{
+			name:   "PaidOff",
+			loanID: l.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				paidOff := l
+				paidOff.OutstandingPrincipal = 0
+				paidOff.Status = db.LoanStatusPaidOff
+
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Eq(l.ID)).Times(1).Return(paidOff, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleViewer), nil)
+				store.EXPECT().GetNextLoanInstallment(gomock.Any(), gomock.Eq(l.ID)).Times(1).Return(db.LoanInstallment{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got loanResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Zero(t, got.OutstandingPrincipal)
+				require.Nil(t, got.NextInstallment)
+			},
+		},
+		{
+			name:   "UnauthorizedUser",
+			loanID: l.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "any_unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				unauthorizedUser, _ := randomUser(t)
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Eq(l.ID)).Times(1).Return(l, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(1).Return(unauthorizedUser, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().GetNextLoanInstallment(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				// accounts of other users are reported as not found
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorBody(t, recorder.Body, codeAccountNotFound)
+			},
+		},
+		{
+			name:   "NotFound",
+			loanID: l.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Eq(l.ID)).Times(1).Return(db.Loan{}, sql.ErrNoRows)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidID",
+			loanID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoan(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/loans/%d", tc.loanID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,12 +1,16 @@
 package api
 
 import (
+	"database/sql"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
 	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/ratelimit"
 	"github.com/khorsl/simple_bank/util"
 	"github.com/stretchr/testify/require"
 )
@@ -17,12 +21,29 @@ func newTestServer(t *testing.T, store db.Store) *Server {
 		AccessTokenDuration: time.Minute,
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, ratelimit.NewMemoryLimiter())
 	require.NoError(t, err)
 
+	stubPasswordChangedAt(store)
+	stubNoSavedPayees(store)
 	return server
 }
 
+// stubPasswordChangedAt lets the tokens of the tests through authMiddleware, as if their users never
+// changed their password. Tests that stub the lookup before creating the server take precedence.
+func stubPasswordChangedAt(store db.Store) {
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).AnyTimes().Return(time.Time{}, nil)
+	}
+}
+
+// stubNoSavedPayees treats the recipients of the tests as accounts that are not saved payees
+func stubNoSavedPayees(store db.Store) {
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(db.Payee{}, sql.ErrNoRows)
+	}
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
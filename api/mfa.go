@@ -0,0 +1,311 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+)
+
+const (
+	// mfaIssuer is the name authenticator apps show next to the codes
+	mfaIssuer        = "Simple Bank"
+	mfaRecoveryCodes = 10
+)
+
+var (
+	errMFAAlreadyEnabled     = newAPIError(http.StatusConflict, "mfa_already_enabled", "two-factor authentication is already enabled")
+	errMFANotEnrolled        = newAPIError(http.StatusNotFound, "mfa_not_enrolled", "two-factor authentication has not been set up")
+	errInvalidMFAToken       = newAPIError(http.StatusUnauthorized, "invalid_mfa_token", "the login has expired, log in again")
+	errInvalidMFACode        = newAPIError(http.StatusUnauthorized, "invalid_mfa_code", "incorrect authentication code")
+	errMFARequired           = newAPIError(http.StatusForbidden, "mfa_required", "an authentication code is required for this transfer")
+	errMFAEnrollmentRequired = newAPIError(http.StatusForbidden, "mfa_enrollment_required", "set up two-factor authentication to make this transfer")
+)
+
+// Enroll MFA
+
+type enrollMFAResponse struct {
+	Secret string `json:"secret"`
+	// OTPAuthURL is the payload of the QR code that authenticator apps scan
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// enrollMFA starts the enrolment of the authenticated user with a new secret. Two-factor
+// authentication is only enabled once a code of the secret is confirmed.
+func (server *Server) enrollMFA(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	secret, err := util.RandomTOTPSecret()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	_, err = server.store.CreateUserMFA(ctx, db.CreateUserMFAParams{
+		UserID: user.ID,
+		Secret: secret,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errMFAAlreadyEnabled)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollMFAResponse{
+		Secret:     secret,
+		OTPAuthURL: util.TOTPURL(mfaIssuer, user.Username, secret),
+	})
+}
+
+// Confirm MFA
+
+type confirmMFARequest struct {
+	Code string `json:"code" binding:"required,numeric,len=6"`
+}
+
+type confirmMFAResponse struct {
+	// RecoveryCodes are only shown once, each one can be used once instead of a code
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (server *Server) confirmMFA(ctx *gin.Context) {
+	var req confirmMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errMFANotEnrolled)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	if mfa.Status == db.MFAStatusEnabled {
+		abortWithError(ctx, errMFAAlreadyEnabled)
+		return
+	}
+
+	step, ok := util.ValidateTOTP(mfa.Secret, req.Code, time.Now())
+	if !ok {
+		abortWithError(ctx, errInvalidMFACode)
+		return
+	}
+
+	recoveryCodes, err := util.RandomRecoveryCodes(mfaRecoveryCodes)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	hashedCodes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashedCodes[i] = util.HashRecoveryCode(code)
+	}
+
+	_, err = server.store.ConfirmUserMFATx(ctx, db.ConfirmUserMFATxParams{
+		UserID:              user.ID,
+		Step:                step,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errMFAAlreadyEnabled)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, confirmMFAResponse{RecoveryCodes: recoveryCodes})
+}
+
+// Verify Login MFA
+
+type mfaChallengeResponse struct {
+	MFARequired bool `json:"mfa_required"`
+	// MFAToken is exchanged for an access token together with a code at /users/login/mfa
+	MFAToken          string    `json:"mfa_token"`
+	MFATokenExpiresAt time.Time `json:"mfa_token_expires_at"`
+}
+
+// startMFAChallenge answers a correct password of a user with two-factor authentication
+func (server *Server) startMFAChallenge(ctx *gin.Context, user db.User) {
+	mfaToken, payload, err := server.tokenMaker.CreatePurposeToken(user.Username, token.PurposeMFAChallenge, server.config.MFAChallengeDuration)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mfaChallengeResponse{
+		MFARequired:       true,
+		MFAToken:          mfaToken,
+		MFATokenExpiresAt: payload.ExpiredAt,
+	})
+}
+
+type verifyLoginMFARequest struct {
+	MFAToken     string `json:"mfa_token" binding:"required"`
+	Code         string `json:"code" binding:"required_without=RecoveryCode,omitempty,numeric,len=6"`
+	RecoveryCode string `json:"recovery_code" binding:"max=16"`
+}
+
+// verifyLoginMFA is the second step of a login with two-factor authentication. Wrong codes count
+// as failed logins, so the lockout also stops codes from being guessed.
+func (server *Server) verifyLoginMFA(ctx *gin.Context) {
+	var req verifyLoginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.MFAToken)
+	if err != nil || payload.Purpose != token.PurposeMFAChallenge {
+		abortWithError(ctx, errInvalidMFAToken)
+		return
+	}
+
+	if !server.checkLoginLockout(ctx, payload.Username) {
+		return
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	// a password change cancels logins that were waiting for their second step
+	if payload.ValidSince(user.PasswordChangedAt) != nil {
+		abortWithError(ctx, errInvalidMFAToken)
+		return
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if err == sql.ErrNoRows || mfa.Status != db.MFAStatusEnabled {
+		abortWithError(ctx, errInvalidMFAToken)
+		return
+	}
+
+	ok, err := server.useMFACode(ctx, mfa, req.Code, req.RecoveryCode)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !ok {
+		server.loginFailed(ctx, payload.Username, errInvalidMFACode)
+		return
+	}
+
+	server.completeLogin(ctx, user)
+}
+
+// useMFACode reports whether the TOTP code or the recovery code is valid, and uses it up so it
+// cannot be accepted again
+func (server *Server) useMFACode(ctx *gin.Context, mfa db.UserMfa, code string, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := util.ValidateTOTP(mfa.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		_, err := server.store.UseUserMFAStep(ctx, db.UseUserMFAStepParams{
+			UserID:       mfa.UserID,
+			LastUsedStep: step,
+		})
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	if recoveryCode == "" {
+		return false, nil
+	}
+
+	_, err := server.store.UseMFARecoveryCode(ctx, db.UseMFARecoveryCodeParams{
+		UserID:     mfa.UserID,
+		HashedCode: util.HashRecoveryCode(recoveryCode),
+	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkStepUp writes the error response and returns false when the amount is above the step-up
+// threshold and the request does not carry a valid authentication code of the user. Wrong codes count
+// as failed logins, so the lockout also stops codes from being guessed.
+func (server *Server) checkStepUp(ctx *gin.Context, user db.User, amount int64, code string) bool {
+	threshold := server.config.StepUpTransferThreshold
+	if threshold <= 0 || amount <= threshold {
+		return true
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return false
+	}
+
+	if err == sql.ErrNoRows || mfa.Status != db.MFAStatusEnabled {
+		abortWithError(ctx, errMFAEnrollmentRequired)
+		return false
+	}
+
+	if code == "" {
+		abortWithError(ctx, errMFARequired)
+		return false
+	}
+
+	if !server.checkLoginLockout(ctx, user.Username) {
+		return false
+	}
+
+	ok, err := server.useMFACode(ctx, mfa, code, "")
+	if err != nil {
+		abortWithError(ctx, err)
+		return false
+	}
+
+	if !ok {
+		server.loginFailed(ctx, user.Username, errInvalidMFACode)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,410 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrollMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().
+					CreateUserMFA(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateUserMFAParams) (db.UserMfa, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						return db.UserMfa{UserID: arg.UserID, Secret: arg.Secret, Status: db.MFAStatusPending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response enrollMFAResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotEmpty(t, response.Secret)
+				require.True(t, strings.HasPrefix(response.OTPAuthURL, "otpauth://totp/"))
+				require.Contains(t, response.OTPAuthURL, response.Secret)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateUserMFA(gomock.Any(), gomock.Any()).Times(1).Return(db.UserMfa{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFAAlreadyEnabled.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/mfa", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConfirmMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	pending := db.UserMfa{UserID: user.ID, Secret: secret, Status: db.MFAStatusPending}
+
+	step := util.TOTPStep(time.Now())
+	code, err := util.TOTPCode(secret, step)
+	require.NoError(t, err)
+	oldCode, err := util.TOTPCode(secret, step-10)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		code          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().
+					ConfirmUserMFATx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.ConfirmUserMFATxParams) (db.UserMfa, error) {
+						require.Equal(t, user.ID, arg.UserID)
+						require.Equal(t, step, arg.Step)
+						require.Len(t, arg.HashedRecoveryCodes, mfaRecoveryCodes)
+						return db.UserMfa{UserID: user.ID, Status: db.MFAStatusEnabled}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response confirmMFAResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response.RecoveryCodes, mfaRecoveryCodes)
+			},
+		},
+		{
+			name: "InvalidCode",
+			code: oldCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ConfirmUserMFATx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFACode.Code)
+			},
+		},
+		{
+			name: "NotEnrolled",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().ConfirmUserMFATx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFANotEnrolled.Code)
+			},
+		},
+		{
+			name: "AlreadyEnabled",
+			code: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				enabled := pending
+				enabled.Status = db.MFAStatusEnabled
+
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(enabled, nil)
+				store.EXPECT().ConfirmUserMFATx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFAAlreadyEnabled.Code)
+			},
+		},
+		{
+			name: "InvalidRequest",
+			code: "abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"code": tc.code})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/me/mfa/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyLoginMFAAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{UserID: user.ID, Secret: secret, Status: db.MFAStatusEnabled}
+
+	step := util.TOTPStep(time.Now())
+	code, err := util.TOTPCode(secret, step)
+	require.NoError(t, err)
+	oldCode, err := util.TOTPCode(secret, step-10)
+	require.NoError(t, err)
+
+	policy := db.LoginLockoutPolicy{
+		MaxFailedAttempts:  5,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	}
+
+	testCases := []struct {
+		name          string
+		body          func(mfaToken string, accessToken string) gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().
+					UseUserMFAStep(gomock.Any(), gomock.Eq(db.UseUserMFAStepParams{UserID: user.ID, LastUsedStep: step})).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().
+					DeleteLoginThrottle(gomock.Any(), gomock.Eq(db.DeleteLoginThrottleParams{Scope: db.LoginScopeUsername, Subject: user.Username})).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response loginUserResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotEmpty(t, response.AccessToken)
+				require.Equal(t, user.Username, response.User.Username)
+			},
+		},
+		{
+			name: "RecoveryCode",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken, "recovery_code": "ABCD-EFGH"}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().
+					UseMFARecoveryCode(gomock.Any(), gomock.Eq(db.UseMFARecoveryCodeParams{UserID: user.ID, HashedCode: util.HashRecoveryCode("abcdefgh")})).
+					Times(1).
+					Return(db.MfaRecoveryCode{}, nil)
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCodeRecordsFailure",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": oldCode}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().RecordLoginFailureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RecordLoginFailureTxResult{}, nil)
+				store.EXPECT().DeleteLoginThrottle(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFACode.Code)
+			},
+		},
+		{
+			name: "UsedRecoveryCode",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken, "recovery_code": "abcd-efgh"}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().UseMFARecoveryCode(gomock.Any(), gomock.Any()).Times(1).Return(db.MfaRecoveryCode{}, sql.ErrNoRows)
+				store.EXPECT().RecordLoginFailureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RecordLoginFailureTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFACode.Code)
+			},
+		},
+		{
+			name: "Locked",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Now().Add(time.Minute), nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				requireErrorBody(t, recorder.Body, errLoginLocked.Code)
+			},
+		},
+		{
+			name: "PasswordChangedDuringChallenge",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				changed := user
+				changed.PasswordChangedAt = time.Now().Add(time.Minute)
+
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(changed, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFAToken.Code)
+			},
+		},
+		{
+			name: "AccessTokenInsteadOfMFAToken",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": accessToken, "code": code}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFAToken.Code)
+			},
+		},
+		{
+			name: "NoCode",
+			body: func(mfaToken string, accessToken string) gin.H {
+				return gin.H{"mfa_token": mfaToken}
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.loginPolicy = policy
+			recorder := httptest.NewRecorder()
+
+			mfaToken, _, err := server.tokenMaker.CreatePurposeToken(user.Username, token.PurposeMFAChallenge, time.Minute)
+			require.NoError(t, err)
+
+			accessToken, _, err := server.tokenMaker.CreateToken(user.Username, time.Minute)
+			require.NoError(t, err)
+
+			data, err := json.Marshal(tc.body(mfaToken, accessToken))
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
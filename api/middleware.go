@@ -1,48 +1,88 @@
 package api
 
 import (
-	"errors"
+	"database/sql"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/khorsl/simple_bank/db/sqlc"
 	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
 )
 
 const (
 	authorizationHeaderKey  = "authorization"
 	authorizationTypeBearer = "bearer"
 	authorizationPayloadKey = "authorization_payload"
+	authorizationUserKey    = "authorization_user"
+	requestIDHeaderKey      = "X-Request-ID"
+	requestIDKey            = "request_id"
+	maxRequestIDLength      = 64
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// requestIDMiddleware tags every request with an id that is returned in the X-Request-ID header and
+// in error responses. An id sent by the client is kept so a request can be followed across services.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		ctx.Set(requestIDKey, requestID)
+		ctx.Header(requestIDHeaderKey, requestID)
+		ctx.Next()
+	}
+}
+
+// authMiddleware accepts access tokens that were issued after the last password change of their user
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, unauthenticatedError("authorization header is not provided"))
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, unauthenticatedError("authorization header is not provided"))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, unauthenticatedError(fmt.Sprintf("unsupported authorization type %s", authorizationType)))
 			return
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, unauthenticatedError(err.Error()))
+			return
+		}
+
+		if payload.Purpose != token.PurposeAccess {
+			abortWithError(ctx, unauthenticatedError("token is not an access token"))
+			return
+		}
+
+		passwordChangedAt, err := store.GetUserPasswordChangedAt(ctx, payload.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				abortWithError(ctx, unauthenticatedError("user of the token does not exist"))
+				return
+			}
+
+			abortWithError(ctx, err)
+			return
+		}
+
+		if err := payload.ValidSince(passwordChangedAt); err != nil {
+			abortWithError(ctx, unauthenticatedError(err.Error()))
 			return
 		}
 
@@ -50,3 +90,24 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// adminMiddleware must be used after authMiddleware
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		user, err := store.GetUserByUsername(ctx, authPayload.Username)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		if user.Role != util.AdminRole {
+			abortWithError(ctx, forbiddenError("user is not an admin"))
+			return
+		}
+
+		ctx.Set(authorizationUserKey, user)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,438 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+)
+
+var errMoneyRequestNotFound = newAPIError(http.StatusNotFound, "money_request_not_found", "money request not found")
+
+// linkTokenBytes is the amount of randomness in a payment link token
+const linkTokenBytes = 24
+
+func newLinkToken() (string, error) {
+	b := make([]byte, linkTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// payMoneyRequestResponse leaves out the account of the requester
+type payMoneyRequestResponse struct {
+	Request     db.MoneyRequest `json:"request"`
+	Transfer    db.Transfer     `json:"transfer"`
+	FromAccount db.Account      `json:"from_account"`
+	FromEntry   db.Entry        `json:"from_entry"`
+}
+
+// linkMoneyRequestResponse carries the token of a new payment link. Only its hash is stored, so
+// this is the only time the token is shown.
+type linkMoneyRequestResponse struct {
+	db.MoneyRequest
+	LinkToken string `json:"link_token"`
+}
+
+// sentMoneyRequestResponse names the payer by the username it was sent to, their user ID is kept internal
+type sentMoneyRequestResponse struct {
+	ID            int64     `json:"id"`
+	PayerUsername string    `json:"payer_username"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Memo          string    `json:"memo"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// moneyRequestLinkResponse is what anyone holding a payment link sees before paying it
+type moneyRequestLinkResponse struct {
+	ID            int64     `json:"id"`
+	Amount        int64     `json:"amount"`
+	Currency      string    `json:"currency"`
+	Memo          string    `json:"memo"`
+	Status        string    `json:"status"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	RequesterName string    `json:"requester_name"`
+}
+
+// Create Money Request
+
+type createMoneyRequestRequest struct {
+	ToAccountID int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	Memo        string `json:"memo" binding:"max=140"`
+	// the request is either sent to a user or shared as a link
+	PayerUsername string `json:"payer_username" binding:"required_without=Link,excluded_with=Link"`
+	Link          bool   `json:"link"`
+}
+
+func (server *Server) createMoneyRequest(ctx *gin.Context) {
+	var req createMoneyRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, user, ok := server.authorizeAccount(ctx, req.ToAccountID, transferAccountRoles...)
+	if !ok {
+		return
+	}
+
+	if account.Status != db.AccountStatusActive {
+		abortWithError(ctx, &db.AccountNotActiveError{AccountID: account.ID, Status: account.Status})
+		return
+	}
+
+	if account.Currency != req.Currency {
+		abortWithError(ctx, currencyMismatchError(account.ID, account.Currency, req.Currency))
+		return
+	}
+
+	arg := db.CreateMoneyRequestParams{
+		RequesterID: user.ID,
+		ToAccountID: account.ID,
+		Amount:      req.Amount,
+		Currency:    req.Currency,
+		Memo:        req.Memo,
+		ExpiresAt:   time.Now().Add(server.config.MoneyRequestTTL),
+	}
+
+	if req.Link {
+		server.createLinkMoneyRequest(ctx, arg)
+		return
+	}
+
+	if req.PayerUsername == user.Username {
+		abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInvalidRequest, "cannot request money from yourself"))
+		return
+	}
+
+	// unknown payers are reported, the route is rate limited so usernames cannot be enumerated
+	payer, err := server.store.GetUserByUsername(ctx, req.PayerUsername)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg.PayerID = payer.ID
+	request, err := server.store.CreateMoneyRequest(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, sentMoneyRequestResponse{
+		ID:            request.ID,
+		PayerUsername: payer.Username,
+		Amount:        request.Amount,
+		Currency:      request.Currency,
+		Memo:          request.Memo,
+		Status:        request.Status,
+		ExpiresAt:     request.ExpiresAt,
+	})
+}
+
+func (server *Server) createLinkMoneyRequest(ctx *gin.Context, arg db.CreateMoneyRequestParams) {
+	linkToken, err := newLinkToken()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	arg.HashedLinkToken = util.HashToken(linkToken)
+
+	request, err := server.store.CreateMoneyRequest(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, linkMoneyRequestResponse{MoneyRequest: request, LinkToken: linkToken})
+}
+
+// List Money Requests
+
+type listMoneyRequestsRequest struct {
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5,max=10"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending paid declined expired"`
+}
+
+func (server *Server) listIncomingMoneyRequests(ctx *gin.Context) {
+	var req listMoneyRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	requests, err := server.store.ListIncomingMoneyRequests(ctx, db.ListIncomingMoneyRequestsParams{
+		PayerID: user.ID,
+		Status:  req.Status,
+		Limit:   req.PageSize,
+		Offset:  (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+func (server *Server) listOutgoingMoneyRequests(ctx *gin.Context) {
+	var req listMoneyRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	requests, err := server.store.ListOutgoingMoneyRequests(ctx, db.ListOutgoingMoneyRequestsParams{
+		RequesterID: user.ID,
+		Status:      req.Status,
+		Limit:       req.PageSize,
+		Offset:      (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requests)
+}
+
+// getIncomingMoneyRequest writes the error response and returns false when the money request does not
+// exist or was not sent to the user. Both cases look the same so request ids cannot be probed.
+func (server *Server) getIncomingMoneyRequest(ctx *gin.Context, requestID int64, userID int64) (db.MoneyRequest, bool) {
+	request, err := server.store.GetMoneyRequest(ctx, requestID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return db.MoneyRequest{}, false
+	}
+
+	if err == sql.ErrNoRows || request.PayerID != userID {
+		abortWithError(ctx, errMoneyRequestNotFound)
+		return db.MoneyRequest{}, false
+	}
+
+	return request, true
+}
+
+// getLinkMoneyRequest writes the error response and returns false when no money request has the link token
+func (server *Server) getLinkMoneyRequest(ctx *gin.Context, linkToken string) (db.MoneyRequest, bool) {
+	request, err := server.store.GetMoneyRequestByLinkToken(ctx, util.HashToken(linkToken))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errMoneyRequestNotFound)
+			return db.MoneyRequest{}, false
+		}
+
+		abortWithError(ctx, err)
+		return db.MoneyRequest{}, false
+	}
+
+	return request, true
+}
+
+// Pay Money Request
+
+type moneyRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type moneyRequestLinkURI struct {
+	Token string `uri:"token" binding:"required,max=64"`
+}
+
+type payMoneyRequestRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	// MFACode is required when the amount is above the step-up threshold
+	MFACode string `json:"mfa_code" binding:"omitempty,numeric,len=6"`
+}
+
+func (server *Server) payMoneyRequest(ctx *gin.Context) {
+	var uri moneyRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req payMoneyRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	request, ok := server.getIncomingMoneyRequest(ctx, uri.ID, user.ID)
+	if !ok {
+		return
+	}
+
+	server.runPayMoneyRequest(ctx, request, user, req)
+}
+
+func (server *Server) payMoneyRequestLink(ctx *gin.Context) {
+	var uri moneyRequestLinkURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req payMoneyRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	request, ok := server.getLinkMoneyRequest(ctx, uri.Token)
+	if !ok {
+		return
+	}
+
+	server.runPayMoneyRequest(ctx, request, user, req)
+}
+
+// runPayMoneyRequest pays the request with the same checks as a transfer
+func (server *Server) runPayMoneyRequest(ctx *gin.Context, request db.MoneyRequest, user db.User, req payMoneyRequestRequest) {
+	assessment, payee, ok := server.checkImmediateTransfer(ctx, user, transferRequest{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   request.ToAccountID,
+		Amount:        request.Amount,
+		Currency:      request.Currency,
+		MFACode:       req.MFACode,
+	})
+	if !ok {
+		return
+	}
+
+	result, err := server.store.PayMoneyRequestTx(ctx, db.PayMoneyRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: req.FromAccountID,
+		PaidBy:        user.ID,
+		RiskDecision:  string(assessment.Decision),
+		MatchedRules:  assessment.MatchedRules,
+		Payee:         payee,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payMoneyRequestResponse{
+		Request:     result.Request,
+		Transfer:    result.Transfer,
+		FromAccount: result.FromAccount,
+		FromEntry:   result.FromEntry,
+	})
+}
+
+// Decline Money Request
+
+func (server *Server) declineMoneyRequest(ctx *gin.Context) {
+	var uri moneyRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	request, ok := server.getIncomingMoneyRequest(ctx, uri.ID, user.ID)
+	if !ok {
+		return
+	}
+
+	request, err = server.store.DeclineMoneyRequest(ctx, request.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, db.ErrMoneyRequestNotPending)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, request)
+}
+
+// Get Money Request Link
+
+func (server *Server) getMoneyRequestLink(ctx *gin.Context) {
+	var uri moneyRequestLinkURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	request, ok := server.getLinkMoneyRequest(ctx, uri.Token)
+	if !ok {
+		return
+	}
+
+	requester, err := server.store.GetUserById(ctx, request.RequesterID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, moneyRequestLinkResponse{
+		ID:            request.ID,
+		Amount:        request.Amount,
+		Currency:      request.Currency,
+		Memo:          request.Memo,
+		Status:        request.Status,
+		ExpiresAt:     request.ExpiresAt,
+		RequesterName: util.MaskName(requester.FullName),
+	})
+}
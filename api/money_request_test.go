@@ -0,0 +1,478 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/risk"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomMoneyRequest(requester db.User, account db.Account, payer db.User) db.MoneyRequest {
+	return db.MoneyRequest{
+		ID:          util.RandomInt(1, 1000),
+		RequesterID: requester.ID,
+		ToAccountID: account.ID,
+		PayerID:     payer.ID,
+		Amount:      10,
+		Currency:    account.Currency,
+		Memo:        "Dinner",
+		Status:      db.MoneyRequestStatusPending,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateMoneyRequestAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	account := randomAccount(user.ID)
+	account.Currency = util.USD
+	member := newAccountMember(account.ID, user.ID, db.AccountRoleOwner)
+
+	authorize := func(store *mockdb.MockStore, member db.AccountMember) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ToUser",
+			body: gin.H{
+				"to_account_id":  account.ID,
+				"amount":         10,
+				"currency":       account.Currency,
+				"memo":           "Dinner",
+				"payer_username": payer.Username,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, member)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().
+					CreateMoneyRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateMoneyRequestParams) (db.MoneyRequest, error) {
+						require.Equal(t, user.ID, arg.RequesterID)
+						require.Equal(t, payer.ID, arg.PayerID)
+						require.Empty(t, arg.HashedLinkToken)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Minute)
+						return db.MoneyRequest{ID: 1, PayerID: arg.PayerID, Status: db.MoneyRequestStatusPending}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var response sentMoneyRequestResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Equal(t, int64(1), response.ID)
+				require.Equal(t, payer.Username, response.PayerUsername)
+				require.Equal(t, db.MoneyRequestStatusPending, response.Status)
+			},
+		},
+		{
+			name: "AsLink",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        10,
+				"currency":      account.Currency,
+				"link":          true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, member)
+				store.EXPECT().
+					CreateMoneyRequest(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreateMoneyRequestParams) (db.MoneyRequest, error) {
+						require.Zero(t, arg.PayerID)
+						require.Len(t, arg.HashedLinkToken, 64)
+						return db.MoneyRequest{ID: 1, HashedLinkToken: arg.HashedLinkToken}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// the token is returned once and only its hash is stored
+				var response gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.Len(t, response["link_token"], 32)
+				require.NotContains(t, response, "hashed_link_token")
+				require.NotContains(t, recorder.Body.String(), util.HashToken(response["link_token"].(string)))
+			},
+		},
+		{
+			name: "FromYourself",
+			body: gin.H{
+				"to_account_id":  account.ID,
+				"amount":         10,
+				"currency":       account.Currency,
+				"payer_username": user.Username,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PayerNotFound",
+			body: gin.H{
+				"to_account_id":  account.ID,
+				"amount":         10,
+				"currency":       account.Currency,
+				"payer_username": payer.Username,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, member)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorBody(t, recorder.Body, codeUserNotFound)
+			},
+		},
+		{
+			name: "UserAndLink",
+			body: gin.H{
+				"to_account_id":  account.ID,
+				"amount":         10,
+				"currency":       account.Currency,
+				"payer_username": payer.Username,
+				"link":           true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ViewerCannotRequest",
+			body: gin.H{
+				"to_account_id": account.ID,
+				"amount":        10,
+				"currency":      account.Currency,
+				"link":          true,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account.ID, user.ID, db.AccountRoleViewer))
+				store.EXPECT().CreateMoneyRequest(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.MoneyRequestTTL = time.Hour
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/money-requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestPayMoneyRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	other, _ := randomUser(t)
+
+	toAccount := randomAccount(requester.ID)
+	fromAccount := randomAccount(payer.ID)
+	toAccount.Currency = util.USD
+	fromAccount.Currency = util.USD
+	fromAccount.Balance = 100
+
+	moneyRequest := randomMoneyRequest(requester, toAccount, payer)
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{UserID: payer.ID, Secret: secret, Status: db.MFAStatusEnabled}
+	code, err := util.TOTPCode(secret, util.TOTPStep(time.Now()))
+	require.NoError(t, err)
+
+	validTransfer := func(store *mockdb.MockStore) {
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+		store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(3).Return(fromAccount, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(fromAccount.ID, payer.ID, db.AccountRoleOwner), nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	}
+
+	testCases := []struct {
+		name            string
+		stepUpThreshold int64
+		mfaCode         string
+		buildStubs      func(store *mockdb.MockStore)
+		checkResponse   func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+
+				arg := db.PayMoneyRequestTxParams{
+					RequestID:     moneyRequest.ID,
+					FromAccountID: fromAccount.ID,
+					PaidBy:        payer.ID,
+					RiskDecision:  string(risk.Allow),
+					MatchedRules:  []string{},
+					Payee:         &db.PayeeTransferParams{UserID: payer.ID, AccountID: toAccount.ID},
+				}
+				result := db.PayMoneyRequestTxResult{
+					TransferTxResult: db.TransferTxResult{ToAccount: toAccount, FromAccount: fromAccount},
+					Request:          moneyRequest,
+				}
+				store.EXPECT().PayMoneyRequestTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotContains(t, response, "to_account")
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().PayMoneyRequestTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PayMoneyRequestTxResult{}, db.ErrMoneyRequestExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "OverApprovalThreshold",
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				policy := db.AccountApprovalPolicy{AccountID: fromAccount.ID, Threshold: 5, RequiredApprovals: 1}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(policy, nil)
+				store.EXPECT().PayMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:            "StepUpOK",
+			stepUpThreshold: moneyRequest.Amount - 1,
+			mfaCode:         code,
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(1).Return(mfa, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().PayMoneyRequestTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PayMoneyRequestTxResult{Request: moneyRequest}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:            "StepUpCodeRequired",
+			stepUpThreshold: moneyRequest.Amount - 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				validTransfer(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(payer.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().PayMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFARequired.Code)
+			},
+		},
+		{
+			name: "RequestOfAnotherPayer",
+			buildStubs: func(store *mockdb.MockStore) {
+				foreign := moneyRequest
+				foreign.PayerID = other.ID
+
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(foreign, nil)
+				store.EXPECT().PayMoneyRequestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.StepUpTransferThreshold = tc.stepUpThreshold
+			recorder := httptest.NewRecorder()
+
+			body := gin.H{"from_account_id": fromAccount.ID}
+			if tc.mfaCode != "" {
+				body["mfa_code"] = tc.mfaCode
+			}
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/money-requests/%d/pay", moneyRequest.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, payer.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeclineMoneyRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	moneyRequest := randomMoneyRequest(requester, randomAccount(requester.ID), payer)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				declined := moneyRequest
+				declined.Status = db.MoneyRequestStatusDeclined
+
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().DeclineMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(declined, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotPending",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(payer.Username)).Times(1).Return(payer, nil)
+				store.EXPECT().GetMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(moneyRequest, nil)
+				store.EXPECT().DeclineMoneyRequest(gomock.Any(), gomock.Eq(moneyRequest.ID)).Times(1).Return(db.MoneyRequest{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/money-requests/%d/decline", moneyRequest.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, payer.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetMoneyRequestLinkAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	requester.FullName = "Jane Doe"
+	payer, _ := randomUser(t)
+
+	linkToken := util.RandomString(32)
+	moneyRequest := randomMoneyRequest(requester, randomAccount(requester.ID), db.User{})
+	moneyRequest.HashedLinkToken = util.HashToken(linkToken)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetMoneyRequestByLinkToken(gomock.Any(), gomock.Eq(moneyRequest.HashedLinkToken)).Times(1).Return(moneyRequest, nil)
+	store.EXPECT().GetUserById(gomock.Any(), gomock.Eq(requester.ID)).Times(1).Return(requester, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/money-requests/links/%s", linkToken)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, payer.Username, time.Minute)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Equal(t, "J*** D**", response["requester_name"])
+	require.NotContains(t, response, "to_account_id")
+}
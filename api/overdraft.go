@@ -0,0 +1,106 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/interest"
+)
+
+type overdraftResponse struct {
+	AccountID      int64  `json:"account_id"`
+	Currency       string `json:"currency"`
+	OverdraftLimit int64  `json:"overdraft_limit"`
+	AnnualRateBps  int32  `json:"annual_rate_bps"`
+	Used           int64  `json:"used"`
+	Available      int64  `json:"available"`
+	// AccruedInterest is the overdraft interest of the current month, rounded down to the minor unit
+	AccruedInterest       int64 `json:"accrued_interest"`
+	AccruedInterestMicros int64 `json:"accrued_interest_micros"`
+}
+
+// Get Overdraft
+
+func (server *Server) getOverdraft(ctx *gin.Context) {
+	var req accountLimitURI
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, _, ok := server.authorizeAccount(ctx, req.AccountID, viewAccountRoles...)
+	if !ok {
+		return
+	}
+
+	periodStart := interest.MonthStart(time.Now().UTC())
+	accrued, err := server.store.SumOverdraftAccruals(ctx, db.SumOverdraftAccrualsParams{
+		AccountID: account.ID,
+		FromDate:  periodStart,
+		ToDate:    periodStart.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	used := int64(0)
+	if account.Balance < 0 {
+		used = -account.Balance
+	}
+
+	amount, _ := interest.Split(accrued)
+	response := overdraftResponse{
+		AccountID:             account.ID,
+		Currency:              account.Currency,
+		OverdraftLimit:        account.OverdraftLimit,
+		AnnualRateBps:         account.OverdraftRateBps,
+		Used:                  used,
+		Available:             db.AvailableBalance(account),
+		AccruedInterest:       amount,
+		AccruedInterestMicros: accrued,
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Set Overdraft (admin)
+
+type setOverdraftRequest struct {
+	OverdraftLimit int64 `json:"overdraft_limit" binding:"min=0"`
+	AnnualRateBps  int32 `json:"annual_rate_bps" binding:"min=0,max=10000"`
+}
+
+func (server *Server) setOverdraft(ctx *gin.Context) {
+	var uri accountLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req setOverdraftRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.UpdateAccountOverdraft(ctx, db.UpdateAccountOverdraftParams{
+		ID:               uri.AccountID,
+		OverdraftLimit:   req.OverdraftLimit,
+		OverdraftRateBps: req.AnnualRateBps,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errAccountNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/mail"
+	"github.com/khorsl/simple_bank/util"
+)
+
+// Forgot Password
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// forgotPassword emails a password reset link. The response is the same whether or not a user has
+// the email, so it cannot be used to find out who has an account.
+func (server *Server) forgotPassword(ctx *gin.Context) {
+	var req forgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.Status(http.StatusAccepted)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	duration := server.config.PasswordResetTokenDuration
+
+	emailToken, err := server.issueEmailToken(ctx, user, db.UserTokenPurposeResetPassword, duration)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	err = server.mailer.Send(ctx, mail.NewPasswordResetEmail(server.config.AppURL, user.Email, user.FullName, emailToken, duration))
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusAccepted)
+}
+
+// Reset Password
+
+type resetPasswordRequest struct {
+	Token       string `json:"token" binding:"required,max=64"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// resetPassword sets a new password with the token of a reset email. The login lockout of the user
+// is lifted, since whoever reset the password proved they own the email.
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	user, err := server.store.ResetPasswordTx(ctx, db.ResetPasswordTxParams{
+		HashedToken:    util.HashToken(req.Token),
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if server.loginPolicy.Enabled() {
+		err = server.store.DeleteLoginThrottle(ctx, db.DeleteLoginThrottleParams{
+			Scope:   db.LoginScopeUsername,
+			Subject: user.Username,
+		})
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, newUserReponse(user))
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+)
+
+var errPayeeNotFound = newAPIError(http.StatusNotFound, "payee_not_found", "payee not found")
+
+type payeeResponse struct {
+	db.Payee
+	// CoolingOffUntil is set while the payee can only receive a limited amount
+	CoolingOffUntil *time.Time `json:"cooling_off_until,omitempty"`
+}
+
+// payeeCoolingOffLimit returns the cap on the total paid to a payee, zero once its cooling-off
+// period is over or when the rule is disabled. The period starts when the account was first saved
+// as a payee, so deleting and saving it again does not restart it.
+func (server *Server) payeeCoolingOffLimit(payee db.Payee) int64 {
+	if server.config.PayeeCoolingOffLimit <= 0 || time.Since(payee.FirstSavedAt) >= server.config.PayeeCoolingOffPeriod {
+		return 0
+	}
+	return server.config.PayeeCoolingOffLimit
+}
+
+// newPayeeTransfer records a payment of the user to the account for the cooling-off limit, which
+// the store applies to every recipient first saved or paid within the cooling-off period. payee is
+// nil when the account is not a saved payee of the user.
+func (server *Server) newPayeeTransfer(userID int64, accountID int64, payee *db.Payee) *db.PayeeTransferParams {
+	arg := &db.PayeeTransferParams{
+		UserID:           userID,
+		AccountID:        accountID,
+		CoolingOffPeriod: server.config.PayeeCoolingOffPeriod,
+		CoolingOffLimit:  server.config.PayeeCoolingOffLimit,
+	}
+
+	if payee != nil {
+		arg.PayeeID = payee.ID
+		arg.FirstSavedAt = payee.FirstSavedAt
+	}
+
+	return arg
+}
+
+// getPayeeTransfer looks up the saved payee of the user for an account that is paid by its id or
+// alias, so that deleting or never saving a payee does not skip its cooling-off period
+func (server *Server) getPayeeTransfer(ctx *gin.Context, userID int64, accountID int64) (*db.PayeeTransferParams, bool) {
+	payee, err := server.store.GetPayeeByAccount(ctx, db.GetPayeeByAccountParams{
+		UserID:    userID,
+		AccountID: accountID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return server.newPayeeTransfer(userID, accountID, nil), true
+		}
+
+		abortWithError(ctx, err)
+		return nil, false
+	}
+
+	return server.newPayeeTransfer(userID, accountID, &payee), true
+}
+
+func (server *Server) newPayeeResponse(payee db.Payee) payeeResponse {
+	response := payeeResponse{Payee: payee}
+	if server.payeeCoolingOffLimit(payee) > 0 {
+		until := payee.FirstSavedAt.Add(server.config.PayeeCoolingOffPeriod)
+		response.CoolingOffUntil = &until
+	}
+	return response
+}
+
+// getUserPayee writes the error response and returns false when the payee does not exist or was
+// saved by another user. Both cases look the same so payee ids cannot be probed.
+func (server *Server) getUserPayee(ctx *gin.Context, payeeID int64, userID int64) (db.Payee, bool) {
+	payee, err := server.store.GetPayee(ctx, payeeID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return db.Payee{}, false
+	}
+
+	if err == sql.ErrNoRows || payee.UserID != userID {
+		abortWithError(ctx, errPayeeNotFound)
+		return db.Payee{}, false
+	}
+
+	return payee, true
+}
+
+// Create Payee
+
+type createPayeeRequest struct {
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	Nickname  string `json:"nickname" binding:"required,max=50"`
+}
+
+func (server *Server) createPayee(ctx *gin.Context) {
+	var req createPayeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, req.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errAccountNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	if account.Status != db.AccountStatusActive {
+		abortWithError(ctx, &db.AccountNotActiveError{AccountID: account.ID, Status: account.Status})
+		return
+	}
+
+	// the holder name is taken from the account owner so the user can check who they are paying,
+	// it is masked like the recipient of an alias so account ids cannot be used to look up names
+	holder, err := server.store.GetUserById(ctx, account.Owner)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	payee, err := server.store.CreatePayee(ctx, db.CreatePayeeParams{
+		UserID:     user.ID,
+		AccountID:  account.ID,
+		Nickname:   req.Nickname,
+		HolderName: util.MaskName(holder.FullName),
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newPayeeResponse(payee))
+}
+
+// List Payees
+
+func (server *Server) listPayees(ctx *gin.Context) {
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	payees, err := server.store.ListPayees(ctx, user.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	response := make([]payeeResponse, len(payees))
+	for i, payee := range payees {
+		response[i] = server.newPayeeResponse(payee)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Delete Payee
+
+type payeeURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deletePayee(ctx *gin.Context) {
+	var uri payeeURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	payee, ok := server.getUserPayee(ctx, uri.ID, user.ID)
+	if !ok {
+		return
+	}
+
+	err = server.store.DeletePayee(ctx, payee.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
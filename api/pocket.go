@@ -0,0 +1,174 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+var errPocketNotFound = newAPIError(http.StatusNotFound, "pocket_not_found", "pocket not found")
+
+type accountPocketURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+	PocketID  int64 `uri:"pocket_id" binding:"required,min=1"`
+}
+
+// getAccountPocket writes the error response and returns false when the pocket does not exist in the
+// account or the authenticated user is not a member of the account with one of the given roles
+func (server *Server) getAccountPocket(ctx *gin.Context, roles ...string) (db.Pocket, bool) {
+	var uri accountPocketURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return db.Pocket{}, false
+	}
+
+	_, _, ok := server.authorizeAccount(ctx, uri.AccountID, roles...)
+	if !ok {
+		return db.Pocket{}, false
+	}
+
+	pocket, err := server.store.GetPocket(ctx, uri.PocketID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errPocketNotFound)
+			return db.Pocket{}, false
+		}
+
+		abortWithError(ctx, err)
+		return db.Pocket{}, false
+	}
+
+	if pocket.AccountID != uri.AccountID {
+		abortWithError(ctx, errPocketNotFound)
+		return db.Pocket{}, false
+	}
+
+	return pocket, true
+}
+
+// Create Pocket
+
+type createPocketRequest struct {
+	Name         string `json:"name" binding:"required,max=50"`
+	TargetAmount int64  `json:"target_amount" binding:"required,gt=0"`
+	TargetDate   string `json:"target_date" binding:"required,datetime=2006-01-02"`
+}
+
+func (server *Server) createPocket(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req createPocketRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	targetDate, err := time.Parse("2006-01-02", req.TargetDate)
+	if err != nil {
+		abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInvalidRequest, "target_date is not a valid date"))
+		return
+	}
+
+	account, _, ok := server.authorizeAccount(ctx, uri.AccountID, transferAccountRoles...)
+	if !ok {
+		return
+	}
+
+	pocket, err := server.store.CreatePocket(ctx, db.CreatePocketParams{
+		AccountID:    account.ID,
+		Name:         req.Name,
+		TargetAmount: req.TargetAmount,
+		TargetDate:   targetDate,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pocket)
+}
+
+// List Pockets
+
+func (server *Server) listPockets(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, _, ok := server.authorizeAccount(ctx, uri.AccountID, viewAccountRoles...)
+	if !ok {
+		return
+	}
+
+	pockets, err := server.store.ListPockets(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pockets)
+}
+
+// Deposit to / Withdraw from Pocket
+
+type pocketFundsRequest struct {
+	Amount int64 `json:"amount" binding:"required,gt=0"`
+}
+
+func (server *Server) depositToPocket(ctx *gin.Context) {
+	server.movePocketFunds(ctx, 1)
+}
+
+func (server *Server) withdrawFromPocket(ctx *gin.Context) {
+	server.movePocketFunds(ctx, -1)
+}
+
+func (server *Server) movePocketFunds(ctx *gin.Context, direction int64) {
+	var req pocketFundsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	pocket, ok := server.getAccountPocket(ctx, transferAccountRoles...)
+	if !ok {
+		return
+	}
+
+	result, err := server.store.MovePocketFundsTx(ctx, db.MovePocketFundsTxParams{
+		PocketID: pocket.ID,
+		Amount:   direction * req.Amount,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// Delete Pocket
+
+func (server *Server) deletePocket(ctx *gin.Context) {
+	pocket, ok := server.getAccountPocket(ctx, transferAccountRoles...)
+	if !ok {
+		return
+	}
+
+	_, err := server.store.DeletePocketTx(ctx, pocket.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
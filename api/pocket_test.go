@@ -0,0 +1,264 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPocket(accountID int64) db.Pocket {
+	return db.Pocket{
+		ID:           util.RandomInt(1, 1000),
+		AccountID:    accountID,
+		Name:         util.RandomString(6),
+		TargetAmount: util.RandomMoney() + 1,
+		TargetDate:   time.Now().AddDate(1, 0, 0),
+	}
+}
+
+func TestCreatePocketAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.ID)
+	member := newAccountMember(account.ID, user.ID, db.AccountRoleOwner)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":          "Holiday",
+				"target_amount": 500,
+				"target_date":   "2030-06-30",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+
+				arg := db.CreatePocketParams{
+					AccountID:    account.ID,
+					Name:         "Holiday",
+					TargetAmount: 500,
+					TargetDate:   time.Date(2030, time.June, 30, 0, 0, 0, 0, time.UTC),
+				}
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Pocket{ID: 1, AccountID: account.ID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidTargetDate",
+			body: gin.H{
+				"name":          "Holiday",
+				"target_amount": 500,
+				"target_date":   "30/06/2030",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "ViewerCannotCreate",
+			body: gin.H{
+				"name":          "Holiday",
+				"target_amount": 500,
+				"target_date":   "2030-06-30",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleViewer), nil)
+				store.EXPECT().CreatePocket(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pockets", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestMovePocketFundsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.ID)
+	member := newAccountMember(account.ID, user.ID, db.AccountRoleCoOwner)
+	pocket := randomPocket(account.ID)
+
+	authorize := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		action        string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Deposit",
+			action: "deposit",
+			body:   gin.H{"amount": 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().
+					MovePocketFundsTx(gomock.Any(), gomock.Eq(db.MovePocketFundsTxParams{PocketID: pocket.ID, Amount: 50})).
+					Times(1).
+					Return(db.MovePocketFundsTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Withdraw",
+			action: "withdraw",
+			body:   gin.H{"amount": 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().
+					MovePocketFundsTx(gomock.Any(), gomock.Eq(db.MovePocketFundsTxParams{PocketID: pocket.ID, Amount: -50})).
+					Times(1).
+					Return(db.MovePocketFundsTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "InsufficientPocketFunds",
+			action: "withdraw",
+			body:   gin.H{"amount": 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(pocket, nil)
+				store.EXPECT().
+					MovePocketFundsTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.MovePocketFundsTxResult{}, db.ErrInsufficientPocketFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "PocketOfAnotherAccount",
+			action: "deposit",
+			body:   gin.H{"amount": 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+
+				otherPocket := pocket
+				otherPocket.AccountID = account.ID + 1
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(otherPocket, nil)
+				store.EXPECT().MovePocketFundsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "PocketNotFound",
+			action: "deposit",
+			body:   gin.H{"amount": 50},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store)
+				store.EXPECT().GetPocket(gomock.Any(), gomock.Eq(pocket.ID)).Times(1).Return(db.Pocket{}, sql.ErrNoRows)
+				store.EXPECT().MovePocketFundsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InvalidAmount",
+			action: "deposit",
+			body:   gin.H{"amount": -50},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().MovePocketFundsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/pockets/%d/%s", account.ID, pocket.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/token"
+)
+
+var errRateLimited = newAPIError(http.StatusTooManyRequests, "rate_limited", "too many requests, try again later")
+
+// rateLimitMiddleware takes a token from the bucket of the client for the route. Authenticated
+// requests are limited per user and anonymous ones per client IP, so it must come after
+// authMiddleware on authenticated routes.
+func rateLimitMiddleware(limiter ratelimit.Limiter, route string, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !limit.Enabled() {
+			ctx.Next()
+			return
+		}
+
+		key := fmt.Sprintf("%s:ip:%s", route, ctx.ClientIP())
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			key = fmt.Sprintf("%s:user:%s", route, payload.(*token.Payload).Username)
+		}
+
+		result, err := limiter.Allow(ctx, key, limit)
+		if err != nil {
+			// an unavailable backend should not take the API down with it
+			log.Printf("cannot check rate limit of %s: %v", key, err)
+			ctx.Next()
+			return
+		}
+
+		if !result.Allowed {
+			setRetryAfter(ctx, result.RetryAfter)
+			abortWithError(ctx, errRateLimited)
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// setRetryAfter tells the client how many seconds to wait, rounded up so it never retries too early
+func setRetryAfter(ctx *gin.Context, wait time.Duration) {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	ctx.Header("Retry-After", strconv.FormatInt(seconds, 10))
+}
@@ -0,0 +1,247 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:         util.RandomString(32),
+		AccessTokenDuration:       time.Minute,
+		RateLimitLoginRequests:    2,
+		RateLimitLoginPeriod:      time.Minute,
+		RateLimitTransferRequests: 1,
+		RateLimitTransferPeriod:   time.Hour,
+		RateLimitLookupRequests:   1,
+		RateLimitLookupPeriod:     time.Hour,
+		// sign-up is left unlimited to show that password resets have their own bucket
+		RateLimitPasswordResetRequests:     1,
+		RateLimitPasswordResetPeriod:       time.Hour,
+		RateLimitEmailVerificationRequests: 1,
+		RateLimitEmailVerificationPeriod:   time.Hour,
+	}
+
+	server, err := NewServer(config, store, ratelimit.NewMemoryLimiter())
+	require.NoError(t, err)
+
+	stubPasswordChangedAt(store)
+	stubNoSavedPayees(store)
+	return server
+}
+
+func TestRateLimitPerIP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		GetUserByUsername(gomock.Any(), gomock.Any()).
+		Times(3).
+		Return(db.User{}, sql.ErrNoRows)
+
+	server := newRateLimitedTestServer(t, store)
+
+	login := func(remoteAddr string) *httptest.ResponseRecorder {
+		body := bytes.NewBufferString(`{"username": "alice", "password": "secret"}`)
+		request, err := http.NewRequest(http.MethodPost, "/users/login", body)
+		require.NoError(t, err)
+		request.RemoteAddr = remoteAddr
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusUnauthorized, login("192.0.2.1:1000").Code)
+	require.Equal(t, http.StatusUnauthorized, login("192.0.2.1:1001").Code)
+
+	recorder := login("192.0.2.1:1002")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "30", recorder.Header().Get("Retry-After"))
+	requireErrorBody(t, recorder.Body, errRateLimited.Code)
+
+	// another client is not affected
+	require.Equal(t, http.StatusUnauthorized, login("198.51.100.7:1000").Code)
+
+	// a forwarded IP is ignored unless the proxy is trusted
+	request, err := http.NewRequest(http.MethodPost, "/users/login", bytes.NewBufferString(`{}`))
+	require.NoError(t, err)
+	request.RemoteAddr = "192.0.2.1:1003"
+	request.Header.Set("X-Forwarded-For", "203.0.113.9")
+	recorder = httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+}
+
+func TestRateLimitPerUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newRateLimitedTestServer(t, store)
+
+	transfer := func(username string) *httptest.ResponseRecorder {
+		// the body is invalid, requests that get past the limiter stop at validation
+		request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		request.RemoteAddr = "192.0.2.1:1000"
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusBadRequest, transfer("alice").Code)
+
+	recorder := transfer("alice")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "3600", recorder.Header().Get("Retry-After"))
+
+	// users behind the same IP have their own limit
+	require.Equal(t, http.StatusBadRequest, transfer("bob").Code)
+}
+
+func TestRateLimitMovingMoney(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newRateLimitedTestServer(t, mockdb.NewMockStore(ctrl))
+
+	post := func(url string) int {
+		// the body is invalid, requests that get past the limiter stop at validation
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "alice", time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, post("/transfers"))
+
+	// paying a request, funding an escrow or approving a transfer takes from the transfer bucket
+	require.Equal(t, http.StatusTooManyRequests, post("/escrows"))
+	require.Equal(t, http.StatusTooManyRequests, post("/money-requests/1/pay"))
+	require.Equal(t, http.StatusTooManyRequests, post("/money-requests/links/abc/pay"))
+	require.Equal(t, http.StatusTooManyRequests, post("/transfers/1/approve"))
+}
+
+func TestRateLimitLookup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newRateLimitedTestServer(t, mockdb.NewMockStore(ctrl))
+
+	post := func(url string) int {
+		// the body is invalid, requests that get past the limiter stop at validation
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "alice", time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, post("/transfers/recipient"))
+
+	// requesting money from a username or splitting a bill takes from the lookup bucket
+	require.Equal(t, http.StatusTooManyRequests, post("/money-requests"))
+	require.Equal(t, http.StatusTooManyRequests, post("/bill-splits"))
+}
+
+func TestRateLimitPasswordReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newRateLimitedTestServer(t, mockdb.NewMockStore(ctrl))
+
+	post := func(url string) int {
+		request, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		request.RemoteAddr = "192.0.2.1:1000"
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, post("/users/password/forgot"))
+	require.Equal(t, http.StatusTooManyRequests, post("/users/password/forgot"))
+
+	// reset tokens cannot be guessed faster than they are requested
+	require.Equal(t, http.StatusTooManyRequests, post("/users/password/reset"))
+
+	require.Equal(t, http.StatusBadRequest, post("/users/verify-email"))
+	require.Equal(t, http.StatusTooManyRequests, post("/users/verify-email"))
+
+	// the sign-up limit is not shared with password resets
+	require.Equal(t, http.StatusBadRequest, post("/users"))
+	require.Equal(t, http.StatusBadRequest, post("/users"))
+}
+
+func TestRateLimitChangePassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newRateLimitedTestServer(t, mockdb.NewMockStore(ctrl))
+
+	changePassword := func() int {
+		// the body is invalid, requests that get past the limiter stop at validation
+		request, err := http.NewRequest(http.MethodPatch, "/users/me/password", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "alice", time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	// the current password is guessed no faster than at login
+	require.Equal(t, http.StatusBadRequest, changePassword())
+	require.Equal(t, http.StatusBadRequest, changePassword())
+	require.Equal(t, http.StatusTooManyRequests, changePassword())
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("backend is unavailable")
+}
+
+func TestRateLimitBackendError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newRateLimitedTestServer(t, mockdb.NewMockStore(ctrl))
+	server.limiter = failingLimiter{}
+	require.NoError(t, server.setupRouter())
+
+	for i := 0; i < 3; i++ {
+		request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "alice", time.Minute)
+
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+	}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/util"
+)
+
+// errRecipientNotFound is returned both for unknown aliases and for users without an account in the
+// currency, so the lookup cannot tell which users exist
+var errRecipientNotFound = newAPIError(http.StatusNotFound, "recipient_not_found", "no recipient found for this alias and currency")
+
+type recipientRequest struct {
+	// Alias is the username, email or phone number of the recipient
+	Alias    string `json:"alias" binding:"required,max=254"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+type recipientResponse struct {
+	Alias         string `json:"alias"`
+	Currency      string `json:"currency"`
+	RecipientName string `json:"recipient_name"`
+}
+
+// aliasTransferResponse leaves out the recipient account, the sender only sees the masked name
+// they confirmed
+type aliasTransferResponse struct {
+	Transfer      aliasTransfer `json:"transfer"`
+	FromAccount   db.Account    `json:"from_account"`
+	FromEntry     db.Entry      `json:"from_entry"`
+	RecipientName string        `json:"recipient_name"`
+}
+
+// aliasTransfer is a transfer paid to an alias, without the account the alias resolved to
+type aliasTransfer struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	Amount        int64     `json:"amount"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func newAliasTransfer(transfer db.Transfer) aliasTransfer {
+	return aliasTransfer{
+		ID:            transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		Amount:        transfer.Amount,
+		Status:        transfer.Status,
+		CreatedAt:     transfer.CreatedAt,
+	}
+}
+
+// resolveRecipient writes the error response and returns false when no account receives payments
+// for the alias in the currency
+func (server *Server) resolveRecipient(ctx *gin.Context, alias string, currency string) (db.ResolveRecipientAliasRow, bool) {
+	recipient, err := server.store.ResolveRecipientAlias(ctx, db.ResolveRecipientAliasParams{
+		Alias:    alias,
+		Currency: currency,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errRecipientNotFound)
+			return db.ResolveRecipientAliasRow{}, false
+		}
+
+		abortWithError(ctx, err)
+		return db.ResolveRecipientAliasRow{}, false
+	}
+
+	return recipient, true
+}
+
+// Lookup Recipient
+
+// lookupRecipient shows the masked name of the recipient of an alias so the sender can confirm it
+// before paying. The alias is sent in the body to keep emails and phone numbers out of URLs.
+func (server *Server) lookupRecipient(ctx *gin.Context) {
+	var req recipientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	recipient, ok := server.resolveRecipient(ctx, req.Alias, req.Currency)
+	if !ok {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipientResponse{
+		Alias:         req.Alias,
+		Currency:      req.Currency,
+		RecipientName: util.MaskName(recipient.FullName),
+	})
+}
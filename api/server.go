@@ -2,7 +2,11 @@ package api
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/khorsl/simple_bank/mail"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/risk"
 	"github.com/khorsl/simple_bank/token"
 	"github.com/khorsl/simple_bank/util"
 
@@ -16,27 +20,47 @@ import (
 type Server struct {
 	store      db.Store
 	tokenMaker token.Maker
-	config     util.Config
-	router     *gin.Engine
+	riskEngine *risk.Engine
+	limiter    ratelimit.Limiter
+	// loginPolicy locks usernames and client IPs after failed logins
+	loginPolicy db.LoginLockoutPolicy
+	mailer      mail.Mailer
+	config      util.Config
+	router      *gin.Engine
 }
 
-func NewServer(config util.Config, store db.Store) (*Server, error) {
+// NewServer creates the HTTP server. The limiter holds the rate limit buckets and is shared with the
+// gRPC server so that a client has one limit whichever API it calls.
+func NewServer(config util.Config, store db.Store, limiter ratelimit.Limiter) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	mailer, err := mail.NewMailerFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %w", err)
+	}
+
 	server := &Server{
-		config:     config,
-		store:      store,
-		tokenMaker: tokenMaker,
+		config:      config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		riskEngine:  risk.NewEngineFromConfig(config, store),
+		limiter:     limiter,
+		loginPolicy: db.NewLoginLockoutPolicy(config),
+		mailer:      mailer,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("account_type", validAccountType)
+		v.RegisterTagNameFunc(requestFieldName)
 	}
 
-	server.setupRouter()
+	if err := server.setupRouter(); err != nil {
+		return nil, err
+	}
 	return server, nil
 }
 
@@ -44,23 +68,119 @@ func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
 
-func errorResponse(err error) gin.H {
-	return gin.H{"error": err.Error()}
-}
-
-func (server *Server) setupRouter() {
+func (server *Server) setupRouter() error {
 	router := gin.Default()
+	if err := router.SetTrustedProxies(server.config.TrustedProxies); err != nil {
+		return fmt.Errorf("cannot set trusted proxies: %w", err)
+	}
+	router.Use(requestIDMiddleware())
+
+	// every route that checks a password or a code sent to the user is limited, so neither can be guessed
+	loginLimit := server.rateLimit("login", server.config.RateLimitLoginRequests, server.config.RateLimitLoginPeriod)
+	passwordResetLimit := server.rateLimit("password_reset", server.config.RateLimitPasswordResetRequests, server.config.RateLimitPasswordResetPeriod)
+	emailVerificationLimit := server.rateLimit("email_verification", server.config.RateLimitEmailVerificationRequests, server.config.RateLimitEmailVerificationPeriod)
 
-	router.POST("/users", server.createUser)
-	router.POST("/users/login", server.loginUser)
+	router.POST("/users", server.rateLimit("signup", server.config.RateLimitSignupRequests, server.config.RateLimitSignupPeriod), server.createUser)
+	router.POST("/users/login", loginLimit, server.loginUser)
+	router.POST("/users/login/mfa", loginLimit, server.verifyLoginMFA)
+	router.POST("/users/verify-email", emailVerificationLimit, server.verifyEmail)
+	router.POST("/users/password/forgot", passwordResetLimit, server.forgotPassword)
+	router.POST("/users/password/reset", passwordResetLimit, server.resetPassword)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker))
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+	// every route that moves money takes from the same bucket, so limits cannot be dodged by paying
+	// a request, funding an escrow or approving a transfer instead of transferring
+	transferLimit := server.rateLimit("transfer", server.config.RateLimitTransferRequests, server.config.RateLimitTransferPeriod)
+	// routes that take a username or alias share a bucket, so other users cannot be enumerated
+	lookupLimit := server.rateLimit("lookup", server.config.RateLimitLookupRequests, server.config.RateLimitLookupPeriod)
+
+	authRoutes.PATCH("/users/me/password", loginLimit, server.changePassword)
+	authRoutes.POST("/users/me/email/verification", emailVerificationLimit, server.resendVerificationEmail)
+
+	authRoutes.POST("/users/me/mfa", server.enrollMFA)
+	authRoutes.POST("/users/me/mfa/confirm", server.confirmMFA)
 
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/account/:id", server.getAccount)
 	authRoutes.GET("/accounts", server.listAccount)
 
-	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.PATCH("/accounts/:id/status", server.changeAccountStatus)
+
+	authRoutes.GET("/accounts/:id/members", server.listAccountMembers)
+	authRoutes.POST("/accounts/:id/members", server.inviteAccountMember)
+	authRoutes.POST("/accounts/:id/members/accept", server.acceptAccountMember)
+	authRoutes.DELETE("/accounts/:id/members/:user_id", server.removeAccountMember)
+
+	authRoutes.GET("/accounts/:id/pockets", server.listPockets)
+	authRoutes.POST("/accounts/:id/pockets", server.createPocket)
+	authRoutes.POST("/accounts/:id/pockets/:pocket_id/deposit", server.depositToPocket)
+	authRoutes.POST("/accounts/:id/pockets/:pocket_id/withdraw", server.withdrawFromPocket)
+	authRoutes.DELETE("/accounts/:id/pockets/:pocket_id", server.deletePocket)
+
+	authRoutes.GET("/accounts/:id/limits", server.getTransferLimit)
+	authRoutes.GET("/accounts/:id/overdraft", server.getOverdraft)
+
+	authRoutes.PUT("/accounts/:id/approval-policy", server.setApprovalPolicy)
+	authRoutes.POST("/accounts/:id/approvers", server.addApprover)
+	authRoutes.DELETE("/accounts/:id/approvers/:user_id", server.removeApprover)
+
+	authRoutes.GET("/interest-rates", server.listInterestRates)
+
+	authRoutes.GET("/loans/:id", server.getLoan)
+	authRoutes.GET("/loans/:id/schedule", server.getLoanSchedule)
+
+	authRoutes.GET("/payees", server.listPayees)
+	authRoutes.POST("/payees", server.createPayee)
+	authRoutes.DELETE("/payees/:id", server.deletePayee)
+
+	authRoutes.POST("/money-requests", lookupLimit, server.createMoneyRequest)
+	authRoutes.GET("/money-requests/incoming", server.listIncomingMoneyRequests)
+	authRoutes.GET("/money-requests/outgoing", server.listOutgoingMoneyRequests)
+	authRoutes.POST("/money-requests/:id/pay", transferLimit, server.payMoneyRequest)
+	authRoutes.POST("/money-requests/:id/decline", server.declineMoneyRequest)
+	authRoutes.GET("/money-requests/links/:token", server.getMoneyRequestLink)
+	authRoutes.POST("/money-requests/links/:token/pay", transferLimit, server.payMoneyRequestLink)
+
+	authRoutes.POST("/bill-splits", lookupLimit, server.createBillSplit)
+	authRoutes.GET("/bill-splits/:id", server.getBillSplit)
+
+	authRoutes.POST("/escrows", transferLimit, server.createEscrow)
+	authRoutes.GET("/escrows/:id", server.getEscrow)
+	authRoutes.POST("/escrows/:id/release", server.releaseEscrow)
+	authRoutes.POST("/escrows/:id/dispute", server.disputeEscrow)
+
+	authRoutes.POST("/transfers", transferLimit, server.createTransfer)
+	authRoutes.POST("/transfers/recipient", lookupLimit, server.lookupRecipient)
+
+	authRoutes.GET("/approvals", server.listAwaitingApproval)
+	authRoutes.POST("/transfers/:id/approve", transferLimit, server.approveTransferRequest)
+	authRoutes.POST("/transfers/:id/decline", server.declineTransferRequest)
+
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker, server.store), adminMiddleware(server.store))
+
+	adminRoutes.POST("/users/:username/unlock", server.unlockUser)
+
+	adminRoutes.PATCH("/accounts/:id/status", server.adminChangeAccountStatus)
+
+	adminRoutes.PUT("/accounts/:id/limits", server.setTransferLimit)
+	adminRoutes.DELETE("/accounts/:id/limits", server.deleteTransferLimit)
+	adminRoutes.DELETE("/accounts/:id/approval-policy", server.deleteApprovalPolicy)
+	adminRoutes.PUT("/accounts/:id/overdraft", server.setOverdraft)
+
+	adminRoutes.PUT("/interest-rates", server.setInterestRate)
+
+	adminRoutes.POST("/loans", server.originateLoan)
+
+	adminRoutes.GET("/transfers/pending", server.listPendingTransfers)
+	adminRoutes.POST("/transfers/:id/approve", server.approveTransfer)
+	adminRoutes.POST("/transfers/:id/reject", server.rejectTransfer)
+
+	adminRoutes.POST("/escrows/:id/resolve", server.resolveEscrow)
 
 	server.router = router
+	return nil
+}
+
+func (server *Server) rateLimit(route string, requests int64, period time.Duration) gin.HandlerFunc {
+	return rateLimitMiddleware(server.limiter, route, ratelimit.Limit{Requests: requests, Period: period})
 }
@@ -7,20 +7,35 @@ import (
 
 	"github.com/gin-gonic/gin"
 	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/risk"
 	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
 )
 
+var errTransferNotFound = newAPIError(http.StatusNotFound, "transfer_not_found", "transfer not found")
+
 type transferRequest struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	// exactly one of the account, the saved payee or the alias of the recipient is given
+	ToAccountID int64  `json:"to_account_id" binding:"required_without_all=PayeeID ToAlias,excluded_with=PayeeID ToAlias,gte=0"`
+	PayeeID     int64  `json:"payee_id" binding:"omitempty,min=1,excluded_with=ToAlias"`
+	ToAlias     string `json:"to_alias" binding:"max=254"`
+	Amount      int64  `json:"amount" binding:"required,gt=0"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	// MFACode is required when the amount is above the step-up threshold
+	MFACode string `json:"mfa_code" binding:"omitempty,numeric,len=6"`
+
+	// payee is resolved from PayeeID, or from the recipient when it is a saved payee, and recorded
+	// with the transfer
+	payee *db.PayeeTransferParams
+	// recipientName is the masked name of the account resolved from ToAlias
+	recipientName string
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
@@ -28,7 +43,7 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 
 	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -36,40 +51,169 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if req.PayeeID != 0 {
+		payee, ok := server.getUserPayee(ctx, req.PayeeID, user.ID)
+		if !ok {
+			return
+		}
+
+		req.ToAccountID = payee.AccountID
+		req.payee = server.newPayeeTransfer(user.ID, payee.AccountID, &payee)
+	}
+
+	if req.ToAlias != "" {
+		recipient, ok := server.resolveRecipient(ctx, req.ToAlias, req.Currency)
+		if !ok {
+			return
+		}
+
+		req.ToAccountID = recipient.AccountID
+		req.recipientName = util.MaskName(recipient.FullName)
+	}
+
+	if req.payee == nil {
+		var ok bool
+		req.payee, ok = server.getPayeeTransfer(ctx, user.ID, req.ToAccountID)
+		if !ok {
+			return
+		}
+	}
+
 	if !server.isValidTransfer(ctx, req) {
 		return
 	}
 
+	if !server.checkStepUp(ctx, user, req.Amount, req.MFACode) {
+		return
+	}
+
+	assessment, err := server.riskEngine.Assess(ctx, risk.Transfer{
+		UserID:        user.ID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if assessment.Decision == risk.Block {
+		server.flagTransfer(ctx, req, assessment, nil)
+		return
+	}
+
+	policy, err := server.store.GetAccountApprovalPolicy(ctx, req.FromAccountID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return
+	}
+	needsApproval := err == nil && req.Amount > policy.Threshold
+
+	if assessment.Decision == risk.Review {
+		// a reviewed transfer still has to be approved when it is over the threshold
+		var approval *db.PendingApprovalParams
+		if needsApproval {
+			approval = pendingApproval(user, policy)
+		}
+
+		server.flagTransfer(ctx, req, assessment, approval)
+		return
+	}
+
+	if needsApproval {
+		server.requestTransferApproval(ctx, req, user, policy, assessment)
+		return
+	}
+
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
+		EnforceLimits: true,
+		RiskDecision:  string(assessment.Decision),
+		MatchedRules:  assessment.MatchedRules,
+		Payee:         req.payee,
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
+		return
+	}
+
+	if req.ToAlias != "" {
+		ctx.JSON(http.StatusOK, aliasTransferResponse{
+			Transfer:      newAliasTransfer(result.Transfer),
+			FromAccount:   result.FromAccount,
+			FromEntry:     result.FromEntry,
+			RecipientName: req.recipientName,
+		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, result)
 }
 
+// flagTransfer records a transfer that the risk engine blocked or sent for review
+func (server *Server) flagTransfer(ctx *gin.Context, req transferRequest, assessment risk.Assessment, approval *db.PendingApprovalParams) {
+	status := db.TransferStatusBlocked
+	if assessment.Decision == risk.Review {
+		status = db.TransferStatusPending
+	}
+
+	arg := db.FlagTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Status:        status,
+		EnforceLimits: status == db.TransferStatusPending,
+		RiskDecision:  string(assessment.Decision),
+		MatchedRules:  assessment.MatchedRules,
+		Payee:         req.payee,
+		Approval:      approval,
+	}
+
+	result, err := server.store.FlagTransferTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if status == db.TransferStatusBlocked {
+		// matched rules are kept internal so they cannot be probed
+		message := fmt.Sprintf("transfer %d was blocked by risk checks", result.Transfer.ID)
+		abortWithError(ctx, newAPIError(http.StatusForbidden, codeRiskBlocked, message))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"transfer": req.transferView(result.Transfer)})
+}
+
+// transferView hides the recipient account of a transfer paid to an alias
+func (req transferRequest) transferView(transfer db.Transfer) interface{} {
+	if req.ToAlias != "" {
+		return newAliasTransfer(transfer)
+	}
+
+	return transfer
+}
+
 func (server *Server) isValidAccountCurrency(ctx *gin.Context, accountID int64, currency string) bool {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			abortWithError(ctx, errAccountNotFound)
 			return false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return false
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, currencyMismatchError(accountID, account.Currency, currency))
 		return false
 	}
 
@@ -79,12 +223,13 @@ func (server *Server) isValidAccountCurrency(ctx *gin.Context, accountID int64,
 func (server *Server) isSufficientBalance(ctx *gin.Context, accountID int64, amount int64) bool {
 	fromAccount, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
+		abortWithError(ctx, err)
 		return false
 	}
 
-	if fromAccount.Balance < amount {
-		err := fmt.Errorf("accountID %d does not have sufficient funds for %d transfer", accountID, amount)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+	if db.AvailableBalance(fromAccount) < amount {
+		message := fmt.Sprintf("accountID %d does not have sufficient funds for %d transfer", accountID, amount)
+		abortWithError(ctx, newAPIError(http.StatusBadRequest, codeInsufficientFunds, message))
 		return false
 	}
 
@@ -97,23 +242,67 @@ func (server *Server) isValidTransfer(ctx *gin.Context, req transferRequest) boo
 		server.isSufficientBalance(ctx, req.FromAccountID, req.Amount)
 }
 
+// checkImmediateTransfer runs the checks of createTransfer for payments that are either posted right
+// away or refused. Payments that the risk engine or an approval policy would hold are refused, the
+// user has to send them as a regular transfer. The payee records the payment for the cooling-off
+// limit of the recipient.
+func (server *Server) checkImmediateTransfer(ctx *gin.Context, user db.User, req transferRequest) (risk.Assessment, *db.PayeeTransferParams, bool) {
+	if !server.isUserAuthorizedToTransfer(ctx, req.FromAccountID, user.ID) || !server.isValidTransfer(ctx, req) {
+		return risk.Assessment{}, nil, false
+	}
+
+	if !server.checkStepUp(ctx, user, req.Amount, req.MFACode) {
+		return risk.Assessment{}, nil, false
+	}
+
+	payee, ok := server.getPayeeTransfer(ctx, user.ID, req.ToAccountID)
+	if !ok {
+		return risk.Assessment{}, nil, false
+	}
+
+	assessment, err := server.riskEngine.Assess(ctx, risk.Transfer{
+		UserID:        user.ID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return risk.Assessment{}, nil, false
+	}
+
+	if assessment.Decision != risk.Allow {
+		abortWithError(ctx, newAPIError(http.StatusForbidden, codeRiskBlocked, "payment was stopped by risk checks, send it as a transfer instead"))
+		return risk.Assessment{}, nil, false
+	}
+
+	policy, err := server.store.GetAccountApprovalPolicy(ctx, req.FromAccountID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return risk.Assessment{}, nil, false
+	}
+
+	if err == nil && req.Amount > policy.Threshold {
+		message := fmt.Sprintf("amount is over the approval threshold of account %d, send it as a transfer instead", req.FromAccountID)
+		abortWithError(ctx, newAPIError(http.StatusForbidden, codeApprovalRequired, message))
+		return risk.Assessment{}, nil, false
+	}
+
+	return assessment, payee, true
+}
+
 func (server *Server) isUserAuthorizedToTransfer(ctx *gin.Context, accountID int64, userId int64) bool {
-	account, err := server.store.GetAccount(ctx, accountID)
+	_, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			abortWithError(ctx, errAccountNotFound)
 			return false
 		}
 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return false
 	}
 
-	if userId != account.Owner {
-		err := fmt.Errorf("user is unauthorized to transfer money from the account")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return false
-	}
-
-	return true
+	return server.isAccountMemberWithRole(ctx, accountID, userId, transferAccountRoles)
 }
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/risk"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/lib/pq"
+)
+
+var (
+	errApproverNotMember = newAPIError(http.StatusBadRequest, codeInvalidRequest, "approvers must be active members of the account")
+	errLoosenPolicy      = forbiddenError("only an admin can loosen or remove the approval policy")
+)
+
+// Set Approval Policy
+
+type approvalPolicyURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type setApprovalPolicyRequest struct {
+	Threshold             int64 `json:"threshold" binding:"required,gt=0"`
+	RequiredApprovals     int32 `json:"required_approvals" binding:"required,min=1,max=10"`
+	ApprovalWindowMinutes int32 `json:"approval_window_minutes" binding:"required,min=1"`
+}
+
+func (server *Server) setApprovalPolicy(ctx *gin.Context) {
+	var uri approvalPolicyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req setApprovalPolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if _, _, ok := server.authorizeAccount(ctx, uri.AccountID, manageAccountRoles...); !ok {
+		return
+	}
+
+	// an owner may only tighten the policy, otherwise they could approve their own transfers
+	current, err := server.store.GetAccountApprovalPolicy(ctx, uri.AccountID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if err == nil && (req.Threshold > current.Threshold || req.RequiredApprovals < current.RequiredApprovals) {
+		abortWithError(ctx, errLoosenPolicy)
+		return
+	}
+
+	arg := db.UpsertAccountApprovalPolicyParams{
+		AccountID:             uri.AccountID,
+		Threshold:             req.Threshold,
+		RequiredApprovals:     req.RequiredApprovals,
+		ApprovalWindowMinutes: req.ApprovalWindowMinutes,
+	}
+
+	policy, err := server.store.UpsertAccountApprovalPolicy(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, policy)
+}
+
+// Delete Approval Policy (admin only)
+
+func (server *Server) deleteApprovalPolicy(ctx *gin.Context) {
+	var uri approvalPolicyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	err := server.store.DeleteAccountApprovalPolicy(ctx, uri.AccountID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Add Approver
+
+type addApproverRequest struct {
+	Username string `json:"username" binding:"required,alphanum"`
+}
+
+func (server *Server) addApprover(ctx *gin.Context) {
+	var uri approvalPolicyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req addApproverRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if _, _, ok := server.authorizeAccount(ctx, uri.AccountID, manageAccountRoles...); !ok {
+		return
+	}
+
+	approver, err := server.store.GetUserByUsername(ctx, req.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: uri.AccountID,
+		UserID:    approver.ID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if err == sql.ErrNoRows || member.Status != db.MemberStatusActive {
+		abortWithError(ctx, errApproverNotMember)
+		return
+	}
+
+	arg := db.CreateAccountApproverParams{
+		AccountID: uri.AccountID,
+		UserID:    approver.ID,
+	}
+
+	accountApprover, err := server.store.CreateAccountApprover(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountApprover)
+}
+
+// Remove Approver
+
+type removeApproverURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+	UserID    int64 `uri:"user_id" binding:"required,min=1"`
+}
+
+func (server *Server) removeApprover(ctx *gin.Context) {
+	var uri removeApproverURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if _, _, ok := server.authorizeAccount(ctx, uri.AccountID, manageAccountRoles...); !ok {
+		return
+	}
+
+	err := server.store.DeleteAccountApprover(ctx, db.DeleteAccountApproverParams{
+		AccountID: uri.AccountID,
+		UserID:    uri.UserID,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// List transfers awaiting my approval
+
+type listAwaitingApprovalRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listAwaitingApproval(ctx *gin.Context) {
+	var req listAwaitingApprovalRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.ListTransfersAwaitingApprovalParams{
+		ApproverID: user.ID,
+		Limit:      req.PageSize,
+		Offset:     (req.PageID - 1) * req.PageSize,
+	}
+
+	transfers, err := server.store.ListTransfersAwaitingApproval(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// Approve / decline transfers awaiting approval
+
+type decideTransferURI struct {
+	TransferID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) approveTransferRequest(ctx *gin.Context) {
+	server.decideTransfer(ctx, db.ApprovalDecisionApproved)
+}
+
+func (server *Server) declineTransferRequest(ctx *gin.Context) {
+	server.decideTransfer(ctx, db.ApprovalDecisionDeclined)
+}
+
+func (server *Server) decideTransfer(ctx *gin.Context, decision string) {
+	var uri decideTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.DecideTransferApprovalTxParams{
+		TransferID: uri.TransferID,
+		ApproverID: user.ID,
+		Decision:   decision,
+	}
+
+	result, err := server.store.DecideTransferApprovalTx(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			err = conflictError("transfer has already been decided by this user")
+		} else if err == sql.ErrNoRows {
+			err = errTransferNotFound
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// requestTransferApproval holds a transfer that is over the approval threshold of the from account
+func (server *Server) requestTransferApproval(ctx *gin.Context, req transferRequest, user db.User, policy db.AccountApprovalPolicy, assessment risk.Assessment) {
+	arg := db.RequestTransferApprovalTxParams{
+		FromAccountID:     req.FromAccountID,
+		ToAccountID:       req.ToAccountID,
+		Amount:            req.Amount,
+		InitiatedBy:       user.ID,
+		RequiredApprovals: policy.RequiredApprovals,
+		ExpiresAt:         time.Now().Add(time.Duration(policy.ApprovalWindowMinutes) * time.Minute),
+		EnforceLimits:     true,
+		RiskDecision:      string(assessment.Decision),
+		MatchedRules:      assessment.MatchedRules,
+		Payee:             req.payee,
+	}
+
+	result, err := server.store.RequestTransferApprovalTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{
+		"transfer":         req.transferView(result.Transfer),
+		"approval_request": result.ApprovalRequest,
+	})
+}
+
+// pendingApproval holds a transfer sent for review for the approvers of the from account
+func pendingApproval(user db.User, policy db.AccountApprovalPolicy) *db.PendingApprovalParams {
+	return &db.PendingApprovalParams{
+		InitiatedBy:       user.ID,
+		RequiredApprovals: policy.RequiredApprovals,
+		ApprovalWindow:    time.Duration(policy.ApprovalWindowMinutes) * time.Minute,
+	}
+}
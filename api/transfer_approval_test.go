@@ -0,0 +1,578 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/risk"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetApprovalPolicyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	otherUser, _ := randomUser(t)
+	account := randomAccount(user.ID)
+
+	body := gin.H{
+		"threshold":               1000,
+		"required_approvals":      2,
+		"approval_window_minutes": 60,
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, UserID: user.ID})).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+
+				arg := db.UpsertAccountApprovalPolicyParams{
+					AccountID:             account.ID,
+					Threshold:             1000,
+					RequiredApprovals:     2,
+					ApprovalWindowMinutes: 60,
+				}
+				store.EXPECT().
+					UpsertAccountApprovalPolicy(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AccountApprovalPolicy{AccountID: account.ID, Threshold: 1000, RequiredApprovals: 2, ApprovalWindowMinutes: 60}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var policy db.AccountApprovalPolicy
+				err := json.Unmarshal(recorder.Body.Bytes(), &policy)
+				require.NoError(t, err)
+				require.Equal(t, account.ID, policy.AccountID)
+				require.Equal(t, int32(2), policy.RequiredApprovals)
+			},
+		},
+		{
+			name:     "Tighten",
+			username: user.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleOwner), nil)
+
+				current := db.AccountApprovalPolicy{AccountID: account.ID, Threshold: 5000, RequiredApprovals: 1, ApprovalWindowMinutes: 30}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(current, nil)
+				store.EXPECT().UpsertAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "CannotLoosen",
+			username: user.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleOwner), nil)
+
+				current := db.AccountApprovalPolicy{AccountID: account.ID, Threshold: 1000, RequiredApprovals: 3, ApprovalWindowMinutes: 60}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(current, nil)
+				store.EXPECT().UpsertAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorBody(t, recorder.Body, codeForbidden)
+			},
+		},
+		{
+			name:     "CoOwnerCannotManage",
+			username: otherUser.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(otherUser.Username)).Times(1).Return(otherUser, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account.ID, otherUser.ID, db.AccountRoleCoOwner), nil)
+				store.EXPECT().UpsertAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			body:     body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().UpsertAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:     "NoRequiredApprovals",
+			username: user.Username,
+			body: gin.H{
+				"threshold":               1000,
+				"required_approvals":      0,
+				"approval_window_minutes": 60,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpsertAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/approval-policy", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDeleteApprovalPolicyAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	account := randomAccount(user.ID)
+
+	testCases := []struct {
+		name          string
+		url           string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			url:      fmt.Sprintf("/admin/accounts/%d/approval-policy", account.ID),
+			username: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().DeleteAccountApprovalPolicy(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:     "OwnerIsNotAdmin",
+			url:      fmt.Sprintf("/admin/accounts/%d/approval-policy", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().DeleteAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NoOwnerRoute",
+			url:      fmt.Sprintf("/accounts/%d/approval-policy", account.ID),
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DeleteAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, tc.url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAddApproverAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	approver, _ := randomUser(t)
+	account := randomAccount(user.ID)
+
+	authorizeOwner := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, UserID: user.ID})).Times(1).Return(newAccountMember(account.ID, user.ID, db.AccountRoleOwner), nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(approver.Username)).Times(1).Return(approver, nil)
+	}
+	approverMember := db.GetAccountMemberParams{AccountID: account.ID, UserID: approver.ID}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizeOwner(store)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(approverMember)).Times(1).Return(newAccountMember(account.ID, approver.ID, db.AccountRoleCoOwner), nil)
+				store.EXPECT().
+					CreateAccountApprover(gomock.Any(), gomock.Eq(db.CreateAccountApproverParams{AccountID: account.ID, UserID: approver.ID})).
+					Times(1).
+					Return(db.AccountApprover{AccountID: account.ID, UserID: approver.ID}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NotMember",
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizeOwner(store)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(approverMember)).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().CreateAccountApprover(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireErrorBody(t, recorder.Body, codeInvalidRequest)
+			},
+		},
+		{
+			name: "InvitedMember",
+			buildStubs: func(store *mockdb.MockStore) {
+				authorizeOwner(store)
+				member := newAccountMember(account.ID, approver.ID, db.AccountRoleCoOwner)
+				member.Status = db.MemberStatusInvited
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(approverMember)).Times(1).Return(member, nil)
+				store.EXPECT().CreateAccountApprover(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"username": approver.Username})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/approvers", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferApprovalPolicyAPI(t *testing.T) {
+	amount := int64(500)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.ID)
+	account2 := randomAccount(user2.ID)
+
+	account1.Currency = util.USD
+	account1.Balance = 1000
+	account2.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OverThreshold",
+			buildStubs: func(store *mockdb.MockStore) {
+				policy := db.AccountApprovalPolicy{AccountID: account1.ID, Threshold: 100, RequiredApprovals: 2, ApprovalWindowMinutes: 60}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(policy, nil)
+
+				store.EXPECT().
+					RequestTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RequestTransferApprovalTxParams) (db.RequestTransferApprovalTxResult, error) {
+						require.Equal(t, account1.ID, arg.FromAccountID)
+						require.Equal(t, account2.ID, arg.ToAccountID)
+						require.Equal(t, amount, arg.Amount)
+						require.Equal(t, user1.ID, arg.InitiatedBy)
+						require.Equal(t, int32(2), arg.RequiredApprovals)
+						require.True(t, arg.EnforceLimits)
+						require.WithinDuration(t, time.Now().Add(time.Hour), arg.ExpiresAt, time.Minute)
+
+						transfer := db.Transfer{ID: 1, Status: db.TransferStatusAwaitingApproval}
+						return db.RequestTransferApprovalTxResult{Transfer: transfer}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var result db.RequestTransferApprovalTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &result)
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusAwaitingApproval, result.Transfer.Status)
+			},
+		},
+		{
+			name: "AtThreshold",
+			buildStubs: func(store *mockdb.MockStore) {
+				policy := db.AccountApprovalPolicy{AccountID: account1.ID, Threshold: amount, RequiredApprovals: 1, ApprovalWindowMinutes: 60}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(policy, nil)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "GetPolicyError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Any()).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrConnDone)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+			store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.riskEngine = risk.NewEngine(store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDecideTransferAPI(t *testing.T) {
+	approver, _ := randomUser(t)
+	transferID := util.RandomInt(1, 1000)
+
+	testCases := []struct {
+		name          string
+		action        string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "Approve",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DecideTransferApprovalTxParams{
+					TransferID: transferID,
+					ApproverID: approver.ID,
+					Decision:   db.ApprovalDecisionApproved,
+				}
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.DecideTransferApprovalTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Decline",
+			action: "decline",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.DecideTransferApprovalTxParams{
+					TransferID: transferID,
+					ApproverID: approver.ID,
+					Decision:   db.ApprovalDecisionDeclined,
+				}
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.DecideTransferApprovalTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferApprovalTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "NotApprover",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferApprovalTxResult{}, db.ErrNotApprover)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:   "NotAwaitingApproval",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferApprovalTxResult{}, db.ErrTransferNotAwaitingApproval)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "Expired",
+			action: "decline",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferApprovalTxResult{}, db.ErrApprovalExpired)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "AlreadyDecided",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferApprovalTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError",
+			action: "approve",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().DecideTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(db.DecideTransferApprovalTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(approver.Username)).Times(1).Return(approver, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/transfers/%d/%s", transferID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, approver.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+type accountLimitURI struct {
+	AccountID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type transferLimitResponse struct {
+	AccountID           int64  `json:"account_id"`
+	Currency            string `json:"currency"`
+	PerTransactionLimit int64  `json:"per_transaction_limit"`
+	DailyLimit          int64  `json:"daily_limit"`
+	DailyUsed           int64  `json:"daily_used"`
+	RemainingAllowance  int64  `json:"remaining_allowance"`
+}
+
+// Get Transfer Limit
+
+func (server *Server) getTransferLimit(ctx *gin.Context) {
+	var req accountLimitURI
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, _, ok := server.authorizeAccount(ctx, req.AccountID, viewAccountRoles...)
+	if !ok {
+		return
+	}
+
+	limit, err := server.store.GetEffectiveTransferLimit(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	used, err := server.store.GetDailyTransferTotal(ctx, db.GetDailyTransferTotalParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	response := transferLimitResponse{
+		AccountID:           account.ID,
+		Currency:            account.Currency,
+		PerTransactionLimit: limit.PerTransactionLimit,
+		DailyLimit:          limit.DailyLimit,
+		DailyUsed:           used,
+		RemainingAllowance:  db.RemainingAllowance(limit, used),
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// Set Transfer Limit (admin)
+
+type setTransferLimitRequest struct {
+	PerTransactionLimit int64 `json:"per_transaction_limit" binding:"required,gt=0"`
+	DailyLimit          int64 `json:"daily_limit" binding:"required,gtefield=PerTransactionLimit"`
+}
+
+func (server *Server) setTransferLimit(ctx *gin.Context) {
+	var uri accountLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req setTransferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	_, err := server.store.GetAccount(ctx, uri.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errAccountNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	admin := ctx.MustGet(authorizationUserKey).(db.User)
+
+	arg := db.UpsertAccountTransferLimitParams{
+		AccountID:           uri.AccountID,
+		PerTransactionLimit: req.PerTransactionLimit,
+		DailyLimit:          req.DailyLimit,
+		UpdatedBy:           admin.ID,
+	}
+
+	limit, err := server.store.UpsertAccountTransferLimit(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+// Delete Transfer Limit (admin)
+
+func (server *Server) deleteTransferLimit(ctx *gin.Context) {
+	var uri accountLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	err := server.store.DeleteAccountTransferLimit(ctx, uri.AccountID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
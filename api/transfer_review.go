@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+// List pending transfers (admin)
+
+type listPendingTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listPendingTransfers(ctx *gin.Context) {
+	var req listPendingTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg := db.ListPendingTransfersParams{
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	transfers, err := server.store.ListPendingTransfers(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// Approve / reject pending transfers (admin)
+
+type reviewTransferURI struct {
+	TransferID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reviewTransferRequest struct {
+	Comment string `json:"comment" binding:"required"`
+}
+
+func (server *Server) approveTransfer(ctx *gin.Context) {
+	server.reviewTransfer(ctx, server.store.ApproveTransferTx)
+}
+
+func (server *Server) rejectTransfer(ctx *gin.Context) {
+	server.reviewTransfer(ctx, server.store.RejectTransferTx)
+}
+
+type reviewTransferTx func(ctx context.Context, arg db.ReviewTransferTxParams) (db.ReviewTransferTxResult, error)
+
+func (server *Server) reviewTransfer(ctx *gin.Context, reviewTx reviewTransferTx) {
+	var uri reviewTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req reviewTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	reviewer := ctx.MustGet(authorizationUserKey).(db.User)
+
+	arg := db.ReviewTransferTxParams{
+		TransferID: uri.TransferID,
+		ReviewerID: reviewer.ID,
+		Comment:    req.Comment,
+	}
+
+	result, err := reviewTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errTransferNotFound
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/khorsl/simple_bank/db/mock"
 	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/risk"
 	"github.com/khorsl/simple_bank/token"
 	"github.com/khorsl/simple_bank/util"
 	"github.com/stretchr/testify/require"
@@ -56,6 +58,7 @@ func TestTransferAPI(t *testing.T) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				// Check From Account Authorization, Currency, Sufficient Balance
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
 				// Checks Currency of To Account
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
 
@@ -63,7 +66,12 @@ func TestTransferAPI(t *testing.T) {
 					FromAccountID: account1.ID,
 					ToAccountID:   account2.ID,
 					Amount:        amount,
+					EnforceLimits: true,
+					RiskDecision:  string(risk.Allow),
+					MatchedRules:  []string{},
+					Payee:         &db.PayeeTransferParams{UserID: user1.ID, AccountID: account2.ID},
 				}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
 				store.EXPECT().
 					TransferTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1)
@@ -86,11 +94,35 @@ func TestTransferAPI(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user2.Username)).Times(1).Return(user2, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user2.ID})).Times(1).Return(db.AccountMember{}, sql.ErrNoRows)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				// accounts of other users are reported as not found
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				requireErrorBody(t, recorder.Body, codeAccountNotFound)
+			},
+		},
+		{
+			name: "ViewerCannotTransfer",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user2.Username)).Times(1).Return(user2, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user2.ID})).Times(1).Return(newAccountMember(account1.ID, user2.ID, db.AccountRoleViewer), nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
@@ -148,6 +180,7 @@ func TestTransferAPI(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(2).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -170,6 +203,7 @@ func TestTransferAPI(t *testing.T) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user3.Username)).Times(1).Return(user3, nil)
 				// Check Auth, Currency
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(2).Return(account3, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account3.ID, UserID: user3.ID})).Times(1).Return(newAccountMember(account3.ID, user3.ID, db.AccountRoleOwner), nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -191,6 +225,7 @@ func TestTransferAPI(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(2).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -272,13 +307,142 @@ func TestTransferAPI(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrTxDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "LimitExceeded",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				limitErr := &db.LimitExceededError{Limit: db.DailyLimit, Currency: util.USD, Remaining: amount - 1}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, limitErr)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+				apiErr := requireErrorBody(t, recorder.Body, codeLimitExceeded)
+				require.Equal(t, db.DailyLimit, apiErr.Details["limit"])
+				require.Equal(t, float64(amount-1), apiErr.Details["remaining_allowance"])
+			},
+		},
+		{
+			name: "InsufficientAvailableBalance",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				reservedAccount := account1
+				reservedAccount.ReservedBalance = reservedAccount.Balance - amount + 1
+
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(reservedAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "PocketFundsNotAvailable",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				pocketAccount := account1
+				pocketAccount.PocketBalance = pocketAccount.Balance - amount + 1
+
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(pocketAccount, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "TransferTxInsufficientFunds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotActive",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+
+				notActiveErr := &db.AccountNotActiveError{AccountID: account2.ID, Status: db.AccountStatusFrozen}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, notActiveErr)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "InsufficientAmountError",
 			body: gin.H{
@@ -293,6 +457,7 @@ func TestTransferAPI(t *testing.T) {
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+				store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -328,3 +493,384 @@ func TestTransferAPI(t *testing.T) {
 		})
 	}
 }
+
+type stubRule struct {
+	name     string
+	decision risk.Decision
+}
+
+func (rule stubRule) Name() string {
+	return rule.name
+}
+
+func (rule stubRule) Evaluate(ctx context.Context, store db.Querier, transfer risk.Transfer) (risk.Decision, error) {
+	return rule.decision, nil
+}
+
+func TestTransferRiskAPI(t *testing.T) {
+	amount := int64(10)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.ID)
+	account2 := randomAccount(user2.ID)
+
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+
+	transfer := db.Transfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+	}
+
+	testCases := []struct {
+		name          string
+		rules         []risk.Rule
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "Blocked",
+			rules: []risk.Rule{stubRule{"velocity", risk.Block}, stubRule{"new_counterparty", risk.Review}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.FlagTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Status:        db.TransferStatusBlocked,
+					RiskDecision:  string(risk.Block),
+					MatchedRules:  []string{"velocity", "new_counterparty"},
+					Payee:         &db.PayeeTransferParams{UserID: user1.ID, AccountID: account2.ID},
+				}
+				transfer.Status = db.TransferStatusBlocked
+				store.EXPECT().FlagTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.FlagTransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				require.NotContains(t, recorder.Body.String(), "velocity")
+			},
+		},
+		{
+			name:  "Review",
+			rules: []risk.Rule{stubRule{"amount_spike", risk.Review}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.FlagTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Status:        db.TransferStatusPending,
+					EnforceLimits: true,
+					RiskDecision:  string(risk.Review),
+					MatchedRules:  []string{"amount_spike"},
+					Payee:         &db.PayeeTransferParams{UserID: user1.ID, AccountID: account2.ID},
+				}
+				transfer.Status = db.TransferStatusPending
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().FlagTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.FlagTransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Contains(t, recorder.Body.String(), db.TransferStatusPending)
+			},
+		},
+		{
+			name:  "ReviewOverApprovalThreshold",
+			rules: []risk.Rule{stubRule{"amount_spike", risk.Review}},
+			buildStubs: func(store *mockdb.MockStore) {
+				policy := db.AccountApprovalPolicy{AccountID: account1.ID, Threshold: amount - 1, RequiredApprovals: 2, ApprovalWindowMinutes: 60}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(policy, nil)
+
+				arg := db.FlagTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					Status:        db.TransferStatusPending,
+					EnforceLimits: true,
+					RiskDecision:  string(risk.Review),
+					MatchedRules:  []string{"amount_spike"},
+					Payee:         &db.PayeeTransferParams{UserID: user1.ID, AccountID: account2.ID},
+					Approval: &db.PendingApprovalParams{
+						InitiatedBy:       user1.ID,
+						RequiredApprovals: 2,
+						ApprovalWindow:    time.Hour,
+					},
+				}
+				transfer.Status = db.TransferStatusPending
+				store.EXPECT().FlagTransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.FlagTransferTxResult{Transfer: transfer}, nil)
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:  "AllowedWithNoMatch",
+			rules: []risk.Rule{stubRule{"velocity", risk.Allow}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					EnforceLimits: true,
+					RiskDecision:  string(risk.Allow),
+					MatchedRules:  []string{},
+					Payee:         &db.PayeeTransferParams{UserID: user1.ID, AccountID: account2.ID},
+				}
+				store.EXPECT().FlagTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "FlagTransferTxError",
+			rules: []risk.Rule{stubRule{"velocity", risk.Block}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().FlagTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.FlagTransferTxResult{}, sql.ErrTxDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+			store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.riskEngine = risk.NewEngine(store, tc.rules...)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        util.USD,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferStepUpAPI(t *testing.T) {
+	threshold := int64(100)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.ID)
+	account2 := randomAccount(user2.ID)
+	account1.Currency = util.USD
+	account1.Balance = 1000
+	account2.Currency = util.USD
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{UserID: user1.ID, Secret: secret, Status: db.MFAStatusEnabled}
+
+	step := util.TOTPStep(time.Now())
+	code, err := util.TOTPCode(secret, step)
+	require.NoError(t, err)
+	oldCode, err := util.TOTPCode(secret, step-10)
+	require.NoError(t, err)
+
+	policy := db.LoginLockoutPolicy{
+		MaxFailedAttempts:  5,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	}
+
+	// buildTransferStubs expects the lookups that happen before the step-up check
+	buildTransferStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(3).Return(account1, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, UserID: user1.ID})).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		amount        int64
+		mfaCode       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			amount:  500,
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().
+					UseUserMFAStep(gomock.Any(), gomock.Eq(db.UseUserMFAStepParams{UserID: user1.ID, LastUsedStep: step})).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BelowThreshold",
+			amount: threshold,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "CodeRequired",
+			amount: 500,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFARequired.Code)
+			},
+		},
+		{
+			name:    "NotEnrolled",
+			amount:  500,
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireErrorBody(t, recorder.Body, errMFAEnrollmentRequired.Code)
+			},
+		},
+		{
+			name:    "InvalidCode",
+			amount:  500,
+			mfaCode: oldCode,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Eq(db.RecordLoginFailureTxParams{Username: user1.Username, IP: "", Policy: policy})).
+					Times(1).
+					Return(db.RecordLoginFailureTxResult{}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFACode.Code)
+			},
+		},
+		{
+			name:    "ReplayedCode",
+			amount:  500,
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(1).Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().RecordLoginFailureTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RecordLoginFailureTxResult{}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireErrorBody(t, recorder.Body, errInvalidMFACode.Code)
+			},
+		},
+		{
+			name:    "LockedOut",
+			amount:  500,
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Now().Add(time.Minute), nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				requireErrorBody(t, recorder.Body, errLoginLocked.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.StepUpTransferThreshold = threshold
+			server.loginPolicy = policy
+			recorder := httptest.NewRecorder()
+
+			body := gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          tc.amount,
+				"currency":        util.USD,
+			}
+			if tc.mfaCode != "" {
+				body["mfa_code"] = tc.mfaCode
+			}
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -2,13 +2,20 @@ package api
 
 import (
 	"database/sql"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/token"
 	"github.com/khorsl/simple_bank/util"
-	"github.com/lib/pq"
+)
+
+var (
+	errInvalidCredentials = newAPIError(http.StatusUnauthorized, "invalid_credentials", "incorrect username or password")
+	errLoginLocked        = newAPIError(http.StatusTooManyRequests, "login_locked", "too many failed logins, try again later")
+	errIncorrectPassword  = newAPIError(http.StatusForbidden, "incorrect_password", "the current password is incorrect")
 )
 
 type createUserRequest struct {
@@ -16,6 +23,7 @@ type createUserRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 	FullName string `json:"full_name" binding:"required"`
 	Email    string `json:"email" binding:"required,email"`
+	Phone    string `json:"phone" binding:"omitempty,e164"`
 }
 
 type userResponse struct {
@@ -23,6 +31,9 @@ type userResponse struct {
 	Username          string    `json:"username"`
 	FullName          string    `json:"full_name"`
 	Email             string    `json:"email"`
+	Phone             string    `json:"phone,omitempty"`
+	Role              string    `json:"role"`
+	EmailVerified     bool      `json:"email_verified"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
 }
@@ -33,6 +44,9 @@ func newUserReponse(user db.User) userResponse {
 		Username:          user.Username,
 		FullName:          user.FullName,
 		Email:             user.Email,
+		Phone:             user.Phone,
+		Role:              user.Role,
+		EmailVerified:     !user.EmailVerifiedAt.IsZero(),
 		PasswordChangedAt: user.PasswordChangedAt,
 		CreatedAt:         user.CreatedAt,
 	}
@@ -41,13 +55,13 @@ func newUserReponse(user db.User) userResponse {
 func (server *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	hashedPassword, err := util.HashPassword(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -56,21 +70,20 @@ func (server *Server) createUser(ctx *gin.Context) {
 		HashedPassword: hashedPassword,
 		FullName:       req.FullName,
 		Email:          req.Email,
+		Phone:          req.Phone,
 	}
 
 	user, err := server.store.CreateUser(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
+	// the user is created either way, a lost email can be sent again from /users/me/email/verification
+	if err := server.sendVerificationEmail(ctx, user); err != nil {
+		log.Printf("request %s: cannot send verification email to user %d: %v", ctx.GetString(requestIDKey), user.ID, err)
+	}
+
 	response := newUserReponse(user)
 	ctx.JSON(http.StatusOK, response)
 }
@@ -88,30 +101,62 @@ type loginUserResponse struct {
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if !server.checkLoginLockout(ctx, req.Username) {
 		return
 	}
 
 	user, err := server.store.GetUserByUsername(ctx, req.Username)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			util.CheckPassword(req.Password, util.UnknownUserHashedPassword)
+			server.loginFailed(ctx, req.Username, errInvalidCredentials)
 			return
 		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
 	err = util.CheckPassword(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		server.loginFailed(ctx, req.Username, errInvalidCredentials)
 		return
 	}
 
-	accessToken, _, err := server.tokenMaker.CreateToken(req.Username, server.config.AccessTokenDuration)
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		abortWithError(ctx, err)
+		return
+	}
 
+	// failed logins are only forgotten after the second step, or codes could be guessed forever
+	if err == nil && mfa.Status == db.MFAStatusEnabled {
+		server.startMFAChallenge(ctx, user)
+		return
+	}
+
+	server.completeLogin(ctx, user)
+}
+
+// completeLogin forgets the failed logins of the user and writes the access token
+func (server *Server) completeLogin(ctx *gin.Context, user db.User) {
+	if server.loginPolicy.Enabled() {
+		err := server.store.DeleteLoginThrottle(ctx, db.DeleteLoginThrottleParams{
+			Scope:   db.LoginScopeUsername,
+			Subject: user.Username,
+		})
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -122,3 +167,145 @@ func (server *Server) loginUser(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// checkLoginLockout writes the error response and returns false while the username or the client IP
+// is locked. Unknown usernames are locked the same way, so the response does not tell them apart.
+func (server *Server) checkLoginLockout(ctx *gin.Context, username string) bool {
+	if !server.loginPolicy.Enabled() {
+		return true
+	}
+
+	lockedUntil, err := server.store.GetLoginLockedUntil(ctx, db.GetLoginLockedUntilParams{
+		Username: username,
+		Ip:       ctx.ClientIP(),
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return false
+	}
+
+	if wait := time.Until(lockedUntil); wait > 0 {
+		setRetryAfter(ctx, wait)
+		abortWithError(ctx, errLoginLocked)
+		return false
+	}
+
+	return true
+}
+
+// loginFailed counts the failure against the username and the client IP and writes the response
+func (server *Server) loginFailed(ctx *gin.Context, username string, apiErr *apiError) {
+	if server.loginPolicy.Enabled() {
+		_, err := server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username: username,
+			IP:       ctx.ClientIP(),
+			Policy:   server.loginPolicy,
+		})
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+
+	abortWithError(ctx, apiErr)
+}
+
+// Change Password
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required,min=6"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// changePassword sets a new password after checking the current one, which revokes every token
+// issued before. The response carries a new access token, so only the other sessions are logged out.
+// Wrong current passwords count towards the login lockout like failed logins.
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if !server.checkLoginLockout(ctx, authPayload.Username) {
+		return
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if err := util.CheckPassword(req.CurrentPassword, user.HashedPassword); err != nil {
+		server.loginFailed(ctx, user.Username, errIncorrectPassword)
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	user, err = server.store.ChangePasswordTx(ctx, db.ChangePasswordTxParams{
+		UserID:         user.ID,
+		HashedPassword: hashedPassword,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, loginUserResponse{
+		AccessToken: accessToken,
+		User:        newUserReponse(user),
+	})
+}
+
+// Unlock User
+
+type unlockUserURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+// unlockUser lets an admin lift the login lockout of a user before it runs out
+func (server *Server) unlockUser(ctx *gin.Context) {
+	var uri unlockUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	_, err := server.store.GetUserByUsername(ctx, uri.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, errUserNotFound)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	admin := ctx.MustGet(authorizationUserKey).(db.User)
+
+	event, err := server.store.UnlockLoginTx(ctx, db.UnlockLoginTxParams{
+		Username:   uri.Username,
+		UnlockedBy: admin.ID,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
@@ -0,0 +1,48 @@
+// Command interest accrues the daily savings and overdraft interest for a date and capitalizes the
+// savings interest of the month when the date is its last day. Overdraft interest is only accrued,
+// it is not charged to the accounts. Running it again for the same date does nothing.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/khorsl/simple_bank/job"
+	"github.com/khorsl/simple_bank/util"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+
+	_ "github.com/lib/pq"
+)
+
+const dateLayout = "2006-01-02"
+
+func main() {
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(dateLayout)
+	dateFlag := flag.String("date", yesterday, "accrual date (YYYY-MM-DD, UTC)")
+	flag.Parse()
+
+	date, err := time.Parse(dateLayout, *dateFlag)
+	if err != nil {
+		log.Fatal("invalid date:", err)
+	}
+
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		log.Fatal("cannot connect to db:", err)
+	}
+
+	result, err := job.RunInterest(context.Background(), db.NewStore(conn), date)
+	log.Printf("interest for %s: accrued %d accounts, accrued overdraft interest on %d accounts, capitalized %d accounts", *dateFlag, result.Accrued, result.OverdraftAccrued, result.Capitalized)
+	if err != nil {
+		log.Fatal("interest job failed:", err)
+	}
+}
@@ -0,0 +1,31 @@
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'customer';
+
+CREATE TABLE "transfer_limits" (
+  "currency" varchar PRIMARY KEY,
+  "per_transaction_limit" bigint NOT NULL,
+  "daily_limit" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_transfer_limits" (
+  "account_id" bigint PRIMARY KEY,
+  "per_transaction_limit" bigint NOT NULL,
+  "daily_limit" bigint NOT NULL,
+  "updated_by" bigint NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."daily_limit" IS 'rolling 24 hours, per user';
+
+COMMENT ON COLUMN "account_transfer_limits"."updated_by" IS 'admin who set the override';
+
+ALTER TABLE "account_transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_transfer_limits" ADD FOREIGN KEY ("updated_by") REFERENCES "users" ("id");
+
+INSERT INTO "transfer_limits" ("currency", "per_transaction_limit", "daily_limit") VALUES
+  ('USD', 500000, 1000000),
+  ('EUR', 500000, 1000000),
+  ('CAD', 500000, 1000000);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "transfer_risk_assessments";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "status";
@@ -0,0 +1,16 @@
+ALTER TABLE "transfers" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+
+CREATE TABLE "transfer_risk_assessments" (
+  "transfer_id" bigint PRIMARY KEY,
+  "decision" varchar NOT NULL,
+  "matched_rules" varchar[] NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "transfers" ("status");
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending or blocked';
+
+COMMENT ON COLUMN "transfer_risk_assessments"."decision" IS 'allow, review or block';
+
+ALTER TABLE "transfer_risk_assessments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
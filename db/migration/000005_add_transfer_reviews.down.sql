@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "transfer_reviews";
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending or blocked';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "reserved_balance";
@@ -0,0 +1,20 @@
+ALTER TABLE "accounts" ADD COLUMN "reserved_balance" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "transfer_reviews" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint UNIQUE NOT NULL,
+  "reviewer_id" bigint NOT NULL,
+  "decision" varchar NOT NULL,
+  "comment" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "accounts"."reserved_balance" IS 'held by pending transfers, not available to spend';
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending, rejected or blocked';
+
+COMMENT ON COLUMN "transfer_reviews"."decision" IS 'approved or rejected';
+
+ALTER TABLE "transfer_reviews" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_reviews" ADD FOREIGN KEY ("reviewer_id") REFERENCES "users" ("id");
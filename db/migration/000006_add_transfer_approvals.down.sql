@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS "transfer_approvals";
+
+DROP TABLE IF EXISTS "transfer_approval_requests";
+
+DROP TABLE IF EXISTS "account_approvers";
+
+DROP TABLE IF EXISTS "account_approval_policies";
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending, rejected or blocked';
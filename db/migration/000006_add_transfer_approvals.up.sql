@@ -0,0 +1,58 @@
+CREATE TABLE "account_approval_policies" (
+  "account_id" bigint PRIMARY KEY,
+  "threshold" bigint NOT NULL,
+  "required_approvals" int NOT NULL DEFAULT 1,
+  "approval_window_minutes" int NOT NULL DEFAULT 1440,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_approvers" (
+  "account_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "user_id")
+);
+
+CREATE TABLE "transfer_approval_requests" (
+  "transfer_id" bigint PRIMARY KEY,
+  "initiated_by" bigint NOT NULL,
+  "required_approvals" int NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_approvals" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_id" bigint NOT NULL,
+  "approver_id" bigint NOT NULL,
+  "decision" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "account_approvers" ("user_id");
+
+CREATE INDEX ON "transfer_approval_requests" ("expires_at");
+
+CREATE UNIQUE INDEX ON "transfer_approvals" ("transfer_id", "approver_id");
+
+COMMENT ON COLUMN "account_approval_policies"."threshold" IS 'transfers above this amount need approvals';
+
+COMMENT ON COLUMN "account_approval_policies"."required_approvals" IS 'not counting the user who made the transfer';
+
+COMMENT ON COLUMN "transfer_approvals"."decision" IS 'approved or declined';
+
+COMMENT ON COLUMN "transfers"."status" IS 'completed, pending, awaiting_approval, rejected, declined, expired or blocked';
+
+ALTER TABLE "account_approval_policies" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_approvers" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_approvers" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "transfer_approval_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_approval_requests" ADD FOREIGN KEY ("initiated_by") REFERENCES "users" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_approvals" ADD FOREIGN KEY ("approver_id") REFERENCES "users" ("id");
@@ -0,0 +1,26 @@
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "role" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'invited',
+  "invited_by" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "user_id")
+);
+
+CREATE INDEX ON "account_members" ("user_id");
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, co_owner or viewer';
+
+COMMENT ON COLUMN "account_members"."status" IS 'invited or active';
+
+COMMENT ON COLUMN "account_members"."invited_by" IS 'the owner themselves for the membership created with the account';
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("id");
+
+INSERT INTO "account_members" ("account_id", "user_id", "role", "status", "invited_by")
+SELECT "id", "owner", 'owner', 'active', "owner" FROM "accounts";
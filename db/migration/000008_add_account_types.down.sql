@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "accounts_owner_type_idx";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "nickname";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE (owner, currency);
@@ -0,0 +1,19 @@
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "account_id" FROM "system_accounts");
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "account_id" FROM "system_accounts")
+OR "to_account_id" IN (SELECT "account_id" FROM "system_accounts");
+
+CREATE TEMPORARY TABLE "dropped_system_accounts" AS SELECT "account_id" FROM "system_accounts";
+
+DROP TABLE IF EXISTS "system_accounts";
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "users" WHERE "username" = 'system';
+
+DROP TABLE IF EXISTS "interest_rates";
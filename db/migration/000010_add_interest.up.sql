@@ -0,0 +1,69 @@
+CREATE TABLE "interest_rates" (
+  "currency" varchar NOT NULL,
+  "account_type" varchar NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("currency", "account_type")
+);
+
+CREATE TABLE "system_accounts" (
+  "purpose" varchar NOT NULL,
+  "currency" varchar NOT NULL,
+  "account_id" bigint UNIQUE NOT NULL,
+  PRIMARY KEY ("purpose", "currency")
+);
+
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+CREATE TABLE "interest_postings" (
+  "account_id" bigint NOT NULL,
+  "period_start" date NOT NULL,
+  "accrued_micros" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "carry_micros" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "period_start")
+);
+
+COMMENT ON COLUMN "interest_rates"."annual_rate_bps" IS '1 bps = 0.01% per year';
+
+COMMENT ON COLUMN "system_accounts"."purpose" IS 'interest_expense';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'end of day balance';
+
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'millionths of a minor unit';
+
+COMMENT ON COLUMN "interest_postings"."accrued_micros" IS 'accruals of the month plus the carry of the previous month';
+
+COMMENT ON COLUMN "interest_postings"."carry_micros" IS 'remainder below one minor unit, carried to the next month';
+
+ALTER TABLE "system_accounts" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+INSERT INTO "interest_rates" ("currency", "account_type", "annual_rate_bps") VALUES
+  ('USD', 'savings', 200),
+  ('EUR', 'savings', 150),
+  ('CAD', 'savings', 175);
+
+INSERT INTO "users" ("username", "full_name", "hashed_password", "email", "role") VALUES
+  ('system', 'Simple Bank', '', 'system@simplebank.local', 'system');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+SELECT "id", 0, c.currency, 'internal', 'Interest expense'
+FROM "users", (VALUES ('USD'), ('EUR'), ('CAD')) AS c(currency)
+WHERE "username" = 'system';
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'interest_expense', "currency", "id" FROM "accounts"
+WHERE "type" = 'internal' AND "nickname" = 'Interest expense';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "overdraft_accruals";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "overdraft_rate_bps";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
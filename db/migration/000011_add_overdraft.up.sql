@@ -0,0 +1,23 @@
+ALTER TABLE "accounts" ADD COLUMN "overdraft_limit" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "accounts" ADD COLUMN "overdraft_rate_bps" int NOT NULL DEFAULT 0;
+
+CREATE TABLE "overdraft_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "amount_micros" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+COMMENT ON COLUMN "accounts"."overdraft_limit" IS 'how far below zero the available balance may go';
+
+COMMENT ON COLUMN "accounts"."overdraft_rate_bps" IS 'annual interest charged on a negative balance';
+
+COMMENT ON COLUMN "overdraft_accruals"."balance" IS 'end of day balance, negative';
+
+COMMENT ON COLUMN "overdraft_accruals"."amount_micros" IS 'millionths of a minor unit';
+
+ALTER TABLE "overdraft_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS "loan_installments";
+
+DROP TABLE IF EXISTS "loans";
+
+CREATE TEMPORARY TABLE "dropped_system_accounts" AS
+SELECT "account_id" FROM "system_accounts" WHERE "purpose" IN ('loan_funding', 'fee_income');
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "account_id" FROM "dropped_system_accounts")
+OR "to_account_id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "system_accounts" WHERE "account_id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+COMMENT ON COLUMN "system_accounts"."purpose" IS 'interest_expense';
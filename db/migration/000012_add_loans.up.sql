@@ -0,0 +1,62 @@
+CREATE TABLE "loans" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "borrower_id" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "principal" bigint NOT NULL,
+  "outstanding_principal" bigint NOT NULL,
+  "annual_rate_bps" int NOT NULL,
+  "term_months" int NOT NULL,
+  "method" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "created_by" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "loan_installments" (
+  "id" bigserial PRIMARY KEY,
+  "loan_id" bigint NOT NULL,
+  "seq" int NOT NULL,
+  "due_date" date NOT NULL,
+  "principal" bigint NOT NULL,
+  "interest" bigint NOT NULL,
+  "late_fee" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'scheduled',
+  "paid_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z'
+);
+
+CREATE INDEX ON "loans" ("account_id");
+
+CREATE UNIQUE INDEX ON "loan_installments" ("loan_id", "seq");
+
+CREATE INDEX ON "loan_installments" ("status", "due_date");
+
+COMMENT ON COLUMN "loans"."account_id" IS 'disbursed into and repaid from';
+
+COMMENT ON COLUMN "loans"."method" IS 'annuity or equal_principal';
+
+COMMENT ON COLUMN "loans"."status" IS 'active or paid_off';
+
+COMMENT ON COLUMN "loan_installments"."status" IS 'scheduled, overdue or paid';
+
+COMMENT ON COLUMN "system_accounts"."purpose" IS 'interest_expense, loan_funding or fee_income';
+
+ALTER TABLE "loans" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "loans" ADD FOREIGN KEY ("borrower_id") REFERENCES "users" ("id");
+
+ALTER TABLE "loans" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("id");
+
+ALTER TABLE "loan_installments" ADD FOREIGN KEY ("loan_id") REFERENCES "loans" ("id") ON DELETE CASCADE;
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+SELECT u."id", 0, c.currency, 'internal', n.nickname
+FROM "users" u,
+  (VALUES ('USD'), ('EUR'), ('CAD')) AS c(currency),
+  (VALUES ('Loan funding'), ('Fee income')) AS n(nickname)
+WHERE u."username" = 'system';
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT CASE "nickname" WHEN 'Loan funding' THEN 'loan_funding' ELSE 'fee_income' END, "currency", "id"
+FROM "accounts"
+WHERE "type" = 'internal' AND "nickname" IN ('Loan funding', 'Fee income');
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "pocket_movements";
+
+DROP TABLE IF EXISTS "pockets";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "pocket_balance";
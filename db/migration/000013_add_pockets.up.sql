@@ -0,0 +1,30 @@
+ALTER TABLE "accounts" ADD COLUMN "pocket_balance" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE "pockets" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "name" varchar NOT NULL,
+  "target_amount" bigint NOT NULL,
+  "target_date" date NOT NULL,
+  "balance" bigint NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "pocket_movements" (
+  "id" bigserial PRIMARY KEY,
+  "pocket_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "pockets" ("account_id");
+
+CREATE INDEX ON "pocket_movements" ("pocket_id");
+
+COMMENT ON COLUMN "accounts"."pocket_balance" IS 'set aside in pockets, not available to spend';
+
+COMMENT ON COLUMN "pocket_movements"."amount" IS 'positive into the pocket, negative back to the main balance';
+
+ALTER TABLE "pockets" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "pocket_movements" ADD FOREIGN KEY ("pocket_id") REFERENCES "pockets" ("id") ON DELETE CASCADE;
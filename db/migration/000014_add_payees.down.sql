@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "payee_transfers";
+
+DROP TABLE IF EXISTS "payees";
@@ -0,0 +1,27 @@
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "account_id" bigint NOT NULL,
+  "nickname" varchar NOT NULL,
+  "holder_name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payee_transfers" (
+  "transfer_id" bigint PRIMARY KEY,
+  "payee_id" bigint NOT NULL
+);
+
+CREATE UNIQUE INDEX ON "payees" ("user_id", "account_id");
+
+CREATE INDEX ON "payee_transfers" ("payee_id");
+
+COMMENT ON COLUMN "payees"."holder_name" IS 'full name of the account owner when the payee was saved';
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "payee_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payee_transfers" ADD FOREIGN KEY ("payee_id") REFERENCES "payees" ("id") ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "users_phone_idx";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "phone";
@@ -0,0 +1,5 @@
+ALTER TABLE "users" ADD COLUMN "phone" varchar NOT NULL DEFAULT '';
+
+CREATE UNIQUE INDEX ON "users" ("phone") WHERE "phone" <> '';
+
+COMMENT ON COLUMN "users"."phone" IS 'optional E.164 phone number, empty when not given';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "money_request_payments";
+
+DROP TABLE IF EXISTS "money_requests";
@@ -0,0 +1,45 @@
+CREATE TABLE "money_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "payer_id" bigint NOT NULL DEFAULT 0,
+  "link_token" varchar NOT NULL DEFAULT '',
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "money_request_payments" (
+  "request_id" bigint PRIMARY KEY,
+  "transfer_id" bigint UNIQUE NOT NULL,
+  "paid_by" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "money_requests" ("requester_id");
+
+CREATE INDEX ON "money_requests" ("payer_id");
+
+CREATE UNIQUE INDEX ON "money_requests" ("link_token") WHERE "link_token" <> '';
+
+CREATE INDEX ON "money_requests" ("status", "expires_at");
+
+COMMENT ON COLUMN "money_requests"."payer_id" IS '0 for requests shared as a link, anyone with the link can pay them';
+
+COMMENT ON COLUMN "money_requests"."link_token" IS 'empty for requests sent to a user';
+
+COMMENT ON COLUMN "money_requests"."status" IS 'pending, paid, declined or expired';
+
+ALTER TABLE "money_requests" ADD FOREIGN KEY ("requester_id") REFERENCES "users" ("id");
+
+ALTER TABLE "money_requests" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "money_request_payments" ADD FOREIGN KEY ("request_id") REFERENCES "money_requests" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "money_request_payments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "money_request_payments" ADD FOREIGN KEY ("paid_by") REFERENCES "users" ("id");
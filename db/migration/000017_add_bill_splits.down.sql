@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "bill_split_shares";
+
+DROP TABLE IF EXISTS "bill_splits";
@@ -0,0 +1,37 @@
+CREATE TABLE "bill_splits" (
+  "id" bigserial PRIMARY KEY,
+  "creator_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "memo" varchar NOT NULL DEFAULT '',
+  "method" varchar NOT NULL,
+  "creator_share" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "bill_split_shares" (
+  "split_id" bigint NOT NULL,
+  "user_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "request_id" bigint UNIQUE NOT NULL,
+  PRIMARY KEY ("split_id", "user_id")
+);
+
+CREATE INDEX ON "bill_splits" ("creator_id");
+
+CREATE INDEX ON "bill_split_shares" ("user_id");
+
+COMMENT ON COLUMN "bill_splits"."method" IS 'equal, percentage or exact';
+
+COMMENT ON COLUMN "bill_splits"."creator_share" IS 'part of the amount that is not requested: the share of the creator plus the rounding remainder';
+
+ALTER TABLE "bill_splits" ADD FOREIGN KEY ("creator_id") REFERENCES "users" ("id");
+
+ALTER TABLE "bill_splits" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "bill_split_shares" ADD FOREIGN KEY ("split_id") REFERENCES "bill_splits" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "bill_split_shares" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "bill_split_shares" ADD FOREIGN KEY ("request_id") REFERENCES "money_requests" ("id") ON DELETE CASCADE;
@@ -0,0 +1,24 @@
+DROP TABLE IF EXISTS "escrow_settlements";
+
+DROP TABLE IF EXISTS "escrows";
+
+CREATE TEMPORARY TABLE "dropped_system_accounts" AS
+SELECT "account_id" FROM "system_accounts" WHERE "purpose" = 'escrow';
+
+DELETE FROM "entries" WHERE "account_id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "transfer_risk_assessments" WHERE "transfer_id" IN (
+  SELECT "id" FROM "transfers"
+  WHERE "from_account_id" IN (SELECT "account_id" FROM "dropped_system_accounts")
+  OR "to_account_id" IN (SELECT "account_id" FROM "dropped_system_accounts")
+);
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "account_id" FROM "dropped_system_accounts")
+OR "to_account_id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "system_accounts" WHERE "account_id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+DELETE FROM "accounts" WHERE "id" IN (SELECT "account_id" FROM "dropped_system_accounts");
+
+COMMENT ON COLUMN "system_accounts"."purpose" IS 'interest_expense, loan_funding or fee_income';
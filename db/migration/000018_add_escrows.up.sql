@@ -0,0 +1,63 @@
+CREATE TABLE "escrows" (
+  "id" bigserial PRIMARY KEY,
+  "buyer_id" bigint NOT NULL,
+  "buyer_account_id" bigint NOT NULL,
+  "seller_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar NOT NULL,
+  "description" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'held',
+  "dispute_reason" varchar NOT NULL DEFAULT '',
+  "funding_transfer_id" bigint UNIQUE NOT NULL,
+  "release_at" timestamptz NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "escrow_settlements" (
+  "escrow_id" bigint PRIMARY KEY,
+  "transfer_id" bigint UNIQUE NOT NULL,
+  "outcome" varchar NOT NULL,
+  "settled_by" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "escrows" ("buyer_id");
+
+CREATE INDEX ON "escrows" ("seller_account_id");
+
+CREATE INDEX ON "escrows" ("status", "release_at");
+
+COMMENT ON COLUMN "escrows"."status" IS 'held, disputed, released or refunded';
+
+COMMENT ON COLUMN "escrows"."release_at" IS 'held funds are released to the seller at this time unless a dispute was opened';
+
+COMMENT ON COLUMN "escrow_settlements"."outcome" IS 'released or refunded';
+
+COMMENT ON COLUMN "escrow_settlements"."settled_by" IS 'user who settled the escrow, the system user for automatic releases';
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("buyer_id") REFERENCES "users" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("buyer_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("seller_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "escrows" ADD FOREIGN KEY ("funding_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrow_settlements" ADD FOREIGN KEY ("escrow_id") REFERENCES "escrows" ("id");
+
+ALTER TABLE "escrow_settlements" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "escrow_settlements" ADD FOREIGN KEY ("settled_by") REFERENCES "users" ("id");
+
+COMMENT ON COLUMN "system_accounts"."purpose" IS 'interest_expense, loan_funding, fee_income or escrow';
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "type", "nickname")
+SELECT u."id", 0, c.currency, 'internal', 'Escrow'
+FROM "users" u, (VALUES ('USD'), ('EUR'), ('CAD')) AS c(currency)
+WHERE u."username" = 'system';
+
+INSERT INTO "system_accounts" ("purpose", "currency", "account_id")
+SELECT 'escrow', "currency", "id"
+FROM "accounts"
+WHERE "type" = 'internal' AND "nickname" = 'Escrow';
@@ -0,0 +1,33 @@
+CREATE TABLE "login_throttles" (
+  "scope" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "failed_attempts" bigint NOT NULL DEFAULT 0,
+  "lockouts" bigint NOT NULL DEFAULT 0,
+  "locked_until" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("scope", "subject")
+);
+
+CREATE TABLE "login_lockout_events" (
+  "id" bigserial PRIMARY KEY,
+  "scope" varchar NOT NULL,
+  "subject" varchar NOT NULL,
+  "event" varchar NOT NULL,
+  "locked_until" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "changed_by" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "login_lockout_events" ("scope", "subject", "created_at");
+
+COMMENT ON COLUMN "login_throttles"."scope" IS 'username or ip';
+
+COMMENT ON COLUMN "login_throttles"."subject" IS 'the username tried, whether or not it exists, or the client IP';
+
+COMMENT ON COLUMN "login_throttles"."lockouts" IS 'lockouts in a row, each one lasts twice as long as the previous one';
+
+COMMENT ON COLUMN "login_lockout_events"."event" IS 'locked or unlocked';
+
+COMMENT ON COLUMN "login_lockout_events"."changed_by" IS 'the system user for lockouts, the admin for unlocks';
+
+ALTER TABLE "login_lockout_events" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("id");
@@ -0,0 +1,30 @@
+CREATE TABLE "user_mfa" (
+  "user_id" bigint PRIMARY KEY,
+  "secret" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "last_used_step" bigint NOT NULL DEFAULT 0,
+  "confirmed_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "mfa_recovery_codes" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "hashed_code" varchar NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "mfa_recovery_codes" ("user_id", "hashed_code");
+
+COMMENT ON COLUMN "user_mfa"."secret" IS 'base32 TOTP secret shared with the authenticator app';
+
+COMMENT ON COLUMN "user_mfa"."status" IS 'pending until the first code is verified, then enabled';
+
+COMMENT ON COLUMN "user_mfa"."last_used_step" IS 'TOTP time step of the last accepted code, codes of this step or older are rejected';
+
+COMMENT ON COLUMN "mfa_recovery_codes"."hashed_code" IS 'sha256 of the recovery code, each code can be used once';
+
+ALTER TABLE "user_mfa" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "mfa_recovery_codes" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
@@ -0,0 +1,24 @@
+ALTER TABLE "users" ADD COLUMN "email_verified_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+CREATE TABLE "user_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "user_id" bigint NOT NULL,
+  "purpose" varchar NOT NULL,
+  "hashed_token" varchar UNIQUE NOT NULL,
+  "email" varchar NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "user_tokens" ("user_id", "purpose");
+
+COMMENT ON COLUMN "users"."email_verified_at" IS 'when the email was last verified, zero while it is not verified';
+
+COMMENT ON COLUMN "user_tokens"."purpose" IS 'verify_email or reset_password';
+
+COMMENT ON COLUMN "user_tokens"."hashed_token" IS 'sha256 of the token sent by email, the token itself is never stored';
+
+COMMENT ON COLUMN "user_tokens"."email" IS 'address the token was sent to, a verification only counts while the user still has it';
+
+ALTER TABLE "user_tokens" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
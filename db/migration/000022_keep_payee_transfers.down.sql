@@ -0,0 +1,16 @@
+DELETE FROM "payee_transfers" t
+WHERE NOT EXISTS (SELECT 1 FROM "payees" p WHERE p."id" = t."payee_id");
+
+ALTER TABLE IF EXISTS "payee_transfers" DROP CONSTRAINT IF EXISTS "payee_transfers_user_id_fkey";
+
+ALTER TABLE IF EXISTS "payee_transfers" DROP CONSTRAINT IF EXISTS "payee_transfers_account_id_fkey";
+
+ALTER TABLE IF EXISTS "payee_transfers" DROP COLUMN IF EXISTS "first_saved_at";
+
+ALTER TABLE IF EXISTS "payee_transfers" DROP COLUMN IF EXISTS "account_id";
+
+ALTER TABLE IF EXISTS "payee_transfers" DROP COLUMN IF EXISTS "user_id";
+
+ALTER TABLE IF EXISTS "payees" DROP COLUMN IF EXISTS "first_saved_at";
+
+ALTER TABLE "payee_transfers" ADD FOREIGN KEY ("payee_id") REFERENCES "payees" ("id") ON DELETE CASCADE;
@@ -0,0 +1,40 @@
+ALTER TABLE "payees" ADD COLUMN "first_saved_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "payee_transfers" ADD COLUMN "user_id" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "payee_transfers" ADD COLUMN "account_id" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "payee_transfers" ADD COLUMN "first_saved_at" timestamptz NOT NULL DEFAULT '0001-01-01 00:00:00Z';
+
+UPDATE "payees" SET "first_saved_at" = "created_at";
+
+UPDATE "payee_transfers" t
+SET "user_id" = p."user_id", "account_id" = p."account_id", "first_saved_at" = p."created_at"
+FROM "payees" p
+WHERE p."id" = t."payee_id";
+
+UPDATE "payees" SET "holder_name" = (
+  SELECT COALESCE(string_agg(left(w.word, 1) || repeat('*', char_length(w.word) - 1), ' ' ORDER BY w.n), '')
+  FROM regexp_split_to_table(btrim("holder_name"), '\s+') WITH ORDINALITY AS w(word, n)
+  WHERE w.word <> ''
+);
+
+ALTER TABLE "payee_transfers" ALTER COLUMN "user_id" DROP DEFAULT;
+
+ALTER TABLE "payee_transfers" ALTER COLUMN "account_id" DROP DEFAULT;
+
+ALTER TABLE "payee_transfers" DROP CONSTRAINT IF EXISTS "payee_transfers_payee_id_fkey";
+
+CREATE INDEX ON "payee_transfers" ("user_id", "account_id");
+
+COMMENT ON COLUMN "payees"."holder_name" IS 'masked full name of the account owner when the payee was saved';
+
+COMMENT ON COLUMN "payees"."first_saved_at" IS 'when the user first saved the account as a payee, deleting and saving it again does not restart the cooling-off period';
+
+COMMENT ON COLUMN "payee_transfers"."payee_id" IS 'payee the transfer was paid to, the row is kept when the payee is deleted';
+
+COMMENT ON COLUMN "payee_transfers"."first_saved_at" IS 'first_saved_at of the payee when the transfer was made';
+
+ALTER TABLE "payee_transfers" ADD FOREIGN KEY ("user_id") REFERENCES "users" ("id");
+
+ALTER TABLE "payee_transfers" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "money_requests" RENAME COLUMN "hashed_link_token" TO "link_token";
+
+COMMENT ON COLUMN "money_requests"."link_token" IS 'empty for requests sent to a user';
@@ -0,0 +1,7 @@
+ALTER TABLE "money_requests" RENAME COLUMN "link_token" TO "hashed_link_token";
+
+UPDATE "money_requests"
+SET "hashed_link_token" = encode(sha256(convert_to("hashed_link_token", 'UTF8')), 'hex')
+WHERE "hashed_link_token" <> '';
+
+COMMENT ON COLUMN "money_requests"."hashed_link_token" IS 'sha256 of the token of the payment link, empty for requests sent to a user';
@@ -0,0 +1,5 @@
+DELETE FROM "payee_transfers" WHERE "payee_id" = 0;
+
+COMMENT ON COLUMN "payee_transfers"."payee_id" IS 'payee the transfer was paid to, the row is kept when the payee is deleted';
+
+COMMENT ON COLUMN "payee_transfers"."account_id" IS NULL;
@@ -0,0 +1,13 @@
+INSERT INTO "payee_transfers" ("transfer_id", "payee_id", "user_id", "account_id", "first_saved_at")
+SELECT t."id", 0, f."owner", COALESCE(e."seller_account_id", t."to_account_id"), '0001-01-01 00:00:00Z'
+FROM "transfers" t
+JOIN "accounts" f ON f."id" = t."from_account_id"
+JOIN "accounts" r ON r."id" = t."to_account_id"
+LEFT JOIN "escrows" e ON e."funding_transfer_id" = t."id"
+WHERE f."type" <> 'internal'
+AND (r."type" <> 'internal' OR e."id" IS NOT NULL)
+AND NOT EXISTS (SELECT 1 FROM "payee_transfers" p WHERE p."transfer_id" = t."id");
+
+COMMENT ON COLUMN "payee_transfers"."payee_id" IS 'payee the transfer was paid to, zero when the recipient was not a saved payee, the row is kept when the payee is deleted';
+
+COMMENT ON COLUMN "payee_transfers"."account_id" IS 'account that receives the money, the seller account of an escrow';
@@ -2,9 +2,11 @@
 INSERT INTO ACCOUNTS (
   OWNER,
   BALANCE,
-  CURRENCY
+  CURRENCY,
+  TYPE,
+  NICKNAME
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetAccount :one
@@ -17,11 +19,19 @@ WHERE ID = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
 -- name: ListAccounts :many
-SELECT * FROM ACCOUNTS
-WHERE OWNER = $1
-ORDER BY ID
-LIMIT $2
-OFFSET $3;
+SELECT A.* FROM ACCOUNTS A
+JOIN ACCOUNT_MEMBERS M ON M.ACCOUNT_ID = A.ID
+WHERE M.USER_ID = sqlc.arg(user_id) AND M.STATUS = 'active'
+AND (sqlc.arg(type)::varchar = '' OR A.TYPE = sqlc.arg(type))
+AND (sqlc.arg(currency)::varchar = '' OR A.CURRENCY = sqlc.arg(currency))
+ORDER BY A.ID
+LIMIT sqlc.arg(limit_)
+OFFSET sqlc.arg(offset_);
+
+-- name: CountAccountsByType :one
+SELECT COUNT(*) FROM ACCOUNTS
+WHERE OWNER = $1 AND TYPE = $2
+AND STATUS <> 'closed';
 
 -- name: UpdateAccount :one
 UPDATE ACCOUNTS 
@@ -35,6 +45,31 @@ SET BALANCE = BALANCE + sqlc.arg(amount)
 WHERE ID = sqlc.arg(id)
 RETURNING *;
 
+-- name: AddAccountReservedBalance :one
+UPDATE ACCOUNTS
+SET RESERVED_BALANCE = RESERVED_BALANCE + sqlc.arg(amount)
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE ACCOUNTS
+SET STATUS = sqlc.arg(status)
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteAccount :exec
 DELETE FROM ACCOUNTS
-WHERE ID = $1;
\ No newline at end of file
+WHERE ID = $1;
+
+-- name: UpdateAccountOverdraft :one
+UPDATE ACCOUNTS
+SET OVERDRAFT_LIMIT = sqlc.arg(overdraft_limit),
+    OVERDRAFT_RATE_BPS = sqlc.arg(overdraft_rate_bps)
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddAccountPocketBalance :one
+UPDATE ACCOUNTS
+SET POCKET_BALANCE = POCKET_BALANCE + sqlc.arg(amount)
+WHERE ID = sqlc.arg(id)
+RETURNING *;
\ No newline at end of file
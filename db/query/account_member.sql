@@ -0,0 +1,29 @@
+-- name: CreateAccountMember :one
+INSERT INTO ACCOUNT_MEMBERS (
+  ACCOUNT_ID,
+  USER_ID,
+  ROLE,
+  STATUS,
+  INVITED_BY
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM ACCOUNT_MEMBERS
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2 LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM ACCOUNT_MEMBERS
+WHERE ACCOUNT_ID = $1
+ORDER BY CREATED_AT;
+
+-- name: AcceptAccountMember :one
+UPDATE ACCOUNT_MEMBERS
+SET STATUS = 'active'
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2 AND STATUS = 'invited'
+RETURNING *;
+
+-- name: DeleteAccountMember :exec
+DELETE FROM ACCOUNT_MEMBERS
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2;
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- name: CreateAccountStatusChange :one
+INSERT INTO ACCOUNT_STATUS_CHANGES (
+  ACCOUNT_ID,
+  FROM_STATUS,
+  TO_STATUS,
+  CHANGED_BY,
+  ACTOR_ROLE,
+  REASON
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetLastAccountStatusChange :one
+SELECT * FROM ACCOUNT_STATUS_CHANGES
+WHERE ACCOUNT_ID = $1 AND TO_STATUS = $2
+ORDER BY ID DESC
+LIMIT 1;
+
+-- name: ListAccountStatusChanges :many
+SELECT * FROM ACCOUNT_STATUS_CHANGES
+WHERE ACCOUNT_ID = $1
+ORDER BY ID;
\ No newline at end of file
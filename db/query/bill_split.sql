@@ -0,0 +1,34 @@
+-- name: CreateBillSplit :one
+INSERT INTO BILL_SPLITS (
+  CREATOR_ID,
+  TO_ACCOUNT_ID,
+  AMOUNT,
+  CURRENCY,
+  MEMO,
+  METHOD,
+  CREATOR_SHARE
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetBillSplit :one
+SELECT * FROM BILL_SPLITS
+WHERE ID = $1 LIMIT 1;
+
+-- name: CreateBillSplitShare :one
+INSERT INTO BILL_SPLIT_SHARES (
+  SPLIT_ID,
+  USER_ID,
+  AMOUNT,
+  REQUEST_ID
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListBillSplitShares :many
+-- Shares of a split with the status of their money request, which tells whether they were paid
+SELECT S.SPLIT_ID, S.USER_ID, U.USERNAME, S.AMOUNT, S.REQUEST_ID, R.STATUS FROM BILL_SPLIT_SHARES S
+JOIN USERS U ON U.ID = S.USER_ID
+JOIN MONEY_REQUESTS R ON R.ID = S.REQUEST_ID
+WHERE S.SPLIT_ID = $1
+ORDER BY U.USERNAME;
\ No newline at end of file
@@ -0,0 +1,49 @@
+-- name: CreateEscrow :one
+INSERT INTO ESCROWS (
+  BUYER_ID,
+  BUYER_ACCOUNT_ID,
+  SELLER_ACCOUNT_ID,
+  AMOUNT,
+  CURRENCY,
+  DESCRIPTION,
+  FUNDING_TRANSFER_ID,
+  RELEASE_AT
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetEscrow :one
+SELECT * FROM ESCROWS
+WHERE ID = $1 LIMIT 1;
+
+-- name: GetEscrowForUpdate :one
+SELECT * FROM ESCROWS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateEscrowStatus :one
+UPDATE ESCROWS
+SET STATUS = sqlc.arg(status), DISPUTE_REASON = sqlc.arg(dispute_reason), UPDATED_AT = now()
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListDueEscrows :many
+-- Held escrows whose release time has passed, disputed escrows wait for an admin
+SELECT ID FROM ESCROWS
+WHERE STATUS = 'held' AND RELEASE_AT <= sqlc.arg(release_at) AND ID > sqlc.arg(after_id)
+ORDER BY ID
+LIMIT sqlc.arg(limit_);
+
+-- name: CreateEscrowSettlement :one
+INSERT INTO ESCROW_SETTLEMENTS (
+  ESCROW_ID,
+  TRANSFER_ID,
+  OUTCOME,
+  SETTLED_BY
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetEscrowSettlement :one
+SELECT * FROM ESCROW_SETTLEMENTS
+WHERE ESCROW_ID = $1 LIMIT 1;
\ No newline at end of file
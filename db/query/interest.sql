@@ -0,0 +1,128 @@
+-- name: UpsertInterestRate :one
+INSERT INTO INTEREST_RATES (
+  CURRENCY,
+  ACCOUNT_TYPE,
+  ANNUAL_RATE_BPS
+) VALUES (
+  $1, $2, $3
+) ON CONFLICT (CURRENCY, ACCOUNT_TYPE) DO UPDATE
+SET ANNUAL_RATE_BPS = EXCLUDED.ANNUAL_RATE_BPS,
+    UPDATED_AT = now()
+RETURNING *;
+
+-- name: ListInterestRates :many
+SELECT * FROM INTEREST_RATES
+ORDER BY CURRENCY, ACCOUNT_TYPE;
+
+-- name: GetSystemAccount :one
+SELECT A.* FROM ACCOUNTS A
+JOIN SYSTEM_ACCOUNTS S ON S.ACCOUNT_ID = A.ID
+WHERE S.PURPOSE = $1 AND S.CURRENCY = $2
+LIMIT 1;
+
+-- name: ListInterestBearingAccounts :many
+-- An account accrues for a day when it was not closed at the end of that day, so a run for a past
+-- date still credits an account that has been closed since. Its status then is the last status it
+-- changed to before the end of the day, or the status it had before its first change.
+SELECT
+  A.ID,
+  R.ANNUAL_RATE_BPS,
+  (A.BALANCE - COALESCE((
+    SELECT SUM(E.AMOUNT) FROM ENTRIES E
+    WHERE E.ACCOUNT_ID = A.ID AND E.CREATED_AT >= sqlc.arg(end_of_day)
+  ), 0))::bigint AS END_OF_DAY_BALANCE
+FROM ACCOUNTS A
+JOIN INTEREST_RATES R ON R.CURRENCY = A.CURRENCY AND R.ACCOUNT_TYPE = A.TYPE
+WHERE A.ID > sqlc.arg(after_id)
+AND A.CREATED_AT < sqlc.arg(end_of_day)
+AND COALESCE(
+  (SELECT C.TO_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID AND C.CREATED_AT < sqlc.arg(end_of_day)
+   ORDER BY C.CREATED_AT DESC, C.ID DESC LIMIT 1),
+  (SELECT C.FROM_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID
+   ORDER BY C.CREATED_AT, C.ID LIMIT 1),
+  A.STATUS
+) <> 'closed'
+AND R.ANNUAL_RATE_BPS > 0
+ORDER BY A.ID
+LIMIT sqlc.arg(limit_);
+
+-- name: CreateInterestAccrual :execrows
+INSERT INTO INTEREST_ACCRUALS (
+  ACCOUNT_ID,
+  ACCRUAL_DATE,
+  BALANCE,
+  ANNUAL_RATE_BPS,
+  AMOUNT_MICROS
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (ACCOUNT_ID, ACCRUAL_DATE) DO NOTHING;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM INTEREST_ACCRUALS
+WHERE ACCOUNT_ID = sqlc.arg(account_id)
+AND ACCRUAL_DATE >= sqlc.arg(from_date)
+AND ACCRUAL_DATE < sqlc.arg(to_date)
+ORDER BY ACCRUAL_DATE;
+
+-- name: SumInterestAccruals :one
+SELECT COALESCE(SUM(AMOUNT_MICROS), 0)::bigint FROM INTEREST_ACCRUALS
+WHERE ACCOUNT_ID = sqlc.arg(account_id)
+AND ACCRUAL_DATE >= sqlc.arg(from_date)
+AND ACCRUAL_DATE < sqlc.arg(to_date);
+
+-- name: ListUnpostedInterestAccounts :many
+-- Like ListInterestBearingAccounts, accruals count when the account was not closed at the end of
+-- their day (UTC)
+SELECT DISTINCT I.ACCOUNT_ID FROM INTEREST_ACCRUALS I
+JOIN ACCOUNTS A ON A.ID = I.ACCOUNT_ID
+WHERE I.ACCRUAL_DATE >= sqlc.arg(period_start)
+AND I.ACCRUAL_DATE < sqlc.arg(period_end)
+AND I.ACCOUNT_ID > sqlc.arg(after_id)
+AND COALESCE(
+  (SELECT C.TO_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID AND C.CREATED_AT < (I.ACCRUAL_DATE + 1)::timestamp AT TIME ZONE 'UTC'
+   ORDER BY C.CREATED_AT DESC, C.ID DESC LIMIT 1),
+  (SELECT C.FROM_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID
+   ORDER BY C.CREATED_AT, C.ID LIMIT 1),
+  A.STATUS
+) <> 'closed'
+AND NOT EXISTS (
+  SELECT 1 FROM INTEREST_POSTINGS P
+  WHERE P.ACCOUNT_ID = I.ACCOUNT_ID AND P.PERIOD_START = sqlc.arg(period_start)
+)
+ORDER BY I.ACCOUNT_ID
+LIMIT sqlc.arg(limit_);
+
+-- name: ListUnpostedInterestPeriods :many
+SELECT DISTINCT date_trunc('month', I.ACCRUAL_DATE)::date AS PERIOD_START FROM INTEREST_ACCRUALS I
+WHERE I.ACCOUNT_ID = $1
+AND NOT EXISTS (
+  SELECT 1 FROM INTEREST_POSTINGS P
+  WHERE P.ACCOUNT_ID = I.ACCOUNT_ID AND P.PERIOD_START = date_trunc('month', I.ACCRUAL_DATE)::date
+)
+ORDER BY PERIOD_START;
+
+-- name: CreateInterestPosting :one
+INSERT INTO INTEREST_POSTINGS (
+  ACCOUNT_ID,
+  PERIOD_START,
+  ACCRUED_MICROS,
+  AMOUNT,
+  CARRY_MICROS
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetInterestPosting :one
+SELECT * FROM INTEREST_POSTINGS
+WHERE ACCOUNT_ID = $1 AND PERIOD_START = $2
+LIMIT 1;
+
+-- name: GetLastInterestPosting :one
+SELECT * FROM INTEREST_POSTINGS
+WHERE ACCOUNT_ID = $1 AND PERIOD_START < $2
+ORDER BY PERIOD_START DESC
+LIMIT 1;
\ No newline at end of file
@@ -0,0 +1,85 @@
+-- name: CreateLoan :one
+INSERT INTO LOANS (
+  ACCOUNT_ID,
+  BORROWER_ID,
+  CURRENCY,
+  PRINCIPAL,
+  OUTSTANDING_PRINCIPAL,
+  ANNUAL_RATE_BPS,
+  TERM_MONTHS,
+  METHOD,
+  CREATED_BY
+) VALUES (
+  $1, $2, $3, $4, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetLoan :one
+SELECT * FROM LOANS
+WHERE ID = $1 LIMIT 1;
+
+-- name: GetLoanForUpdate :one
+SELECT * FROM LOANS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListAccountLoans :many
+SELECT * FROM LOANS
+WHERE ACCOUNT_ID = $1
+ORDER BY ID;
+
+-- name: RepayLoanPrincipal :one
+UPDATE LOANS
+SET OUTSTANDING_PRINCIPAL = OUTSTANDING_PRINCIPAL - sqlc.arg(amount),
+    STATUS = CASE WHEN OUTSTANDING_PRINCIPAL - sqlc.arg(amount) = 0 THEN 'paid_off' ELSE STATUS END
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateLoanInstallment :one
+INSERT INTO LOAN_INSTALLMENTS (
+  LOAN_ID,
+  SEQ,
+  DUE_DATE,
+  PRINCIPAL,
+  INTEREST
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetLoanInstallmentForUpdate :one
+SELECT * FROM LOAN_INSTALLMENTS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListLoanInstallments :many
+SELECT * FROM LOAN_INSTALLMENTS
+WHERE LOAN_ID = $1
+ORDER BY SEQ;
+
+-- name: GetNextLoanInstallment :one
+SELECT * FROM LOAN_INSTALLMENTS
+WHERE LOAN_ID = $1 AND STATUS <> 'paid'
+ORDER BY SEQ
+LIMIT 1;
+
+-- name: ListDueLoanInstallments :many
+SELECT * FROM LOAN_INSTALLMENTS
+WHERE STATUS <> 'paid'
+AND DUE_DATE <= sqlc.arg(due_date)
+AND ID > sqlc.arg(after_id)
+ORDER BY ID
+LIMIT sqlc.arg(limit_);
+
+-- name: MarkLoanInstallmentsOverdue :many
+UPDATE LOAN_INSTALLMENTS
+SET STATUS = 'overdue',
+    LATE_FEE = sqlc.arg(late_fee)
+WHERE STATUS = 'scheduled'
+AND DUE_DATE < sqlc.arg(due_before)
+RETURNING *;
+
+-- name: MarkLoanInstallmentPaid :one
+UPDATE LOAN_INSTALLMENTS
+SET STATUS = 'paid',
+    PAID_AT = now()
+WHERE ID = $1
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,47 @@
+-- name: GetLoginThrottleForUpdate :one
+SELECT * FROM LOGIN_THROTTLES
+WHERE SCOPE = $1 AND SUBJECT = $2
+FOR NO KEY UPDATE;
+
+-- name: UpsertLoginThrottle :one
+INSERT INTO LOGIN_THROTTLES (
+  SCOPE,
+  SUBJECT,
+  FAILED_ATTEMPTS,
+  LOCKOUTS,
+  LOCKED_UNTIL,
+  UPDATED_AT
+) VALUES (
+  $1, $2, $3, $4, $5, now()
+) ON CONFLICT (SCOPE, SUBJECT) DO UPDATE SET
+  FAILED_ATTEMPTS = EXCLUDED.FAILED_ATTEMPTS,
+  LOCKOUTS = EXCLUDED.LOCKOUTS,
+  LOCKED_UNTIL = EXCLUDED.LOCKED_UNTIL,
+  UPDATED_AT = EXCLUDED.UPDATED_AT
+RETURNING *;
+
+-- name: DeleteLoginThrottle :exec
+DELETE FROM LOGIN_THROTTLES
+WHERE SCOPE = $1 AND SUBJECT = $2;
+
+-- name: GetLoginLockedUntil :one
+SELECT COALESCE(MAX(LOCKED_UNTIL), '0001-01-01 00:00:00Z')::timestamptz AS LOCKED_UNTIL
+FROM LOGIN_THROTTLES
+WHERE (SCOPE = 'username' AND SUBJECT = sqlc.arg(username)::varchar)
+   OR (SCOPE = 'ip' AND SUBJECT = sqlc.arg(ip)::varchar);
+
+-- name: CreateLoginLockoutEvent :one
+INSERT INTO LOGIN_LOCKOUT_EVENTS (
+  SCOPE,
+  SUBJECT,
+  EVENT,
+  LOCKED_UNTIL,
+  CHANGED_BY
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListLoginLockoutEvents :many
+SELECT * FROM LOGIN_LOCKOUT_EVENTS
+WHERE SCOPE = $1 AND SUBJECT = $2
+ORDER BY ID;
\ No newline at end of file
@@ -0,0 +1,72 @@
+-- name: CreateMoneyRequest :one
+INSERT INTO MONEY_REQUESTS (
+  REQUESTER_ID,
+  TO_ACCOUNT_ID,
+  PAYER_ID,
+  HASHED_LINK_TOKEN,
+  AMOUNT,
+  CURRENCY,
+  MEMO,
+  EXPIRES_AT
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetMoneyRequest :one
+SELECT * FROM MONEY_REQUESTS
+WHERE ID = $1 LIMIT 1;
+
+-- name: GetMoneyRequestByLinkToken :one
+SELECT * FROM MONEY_REQUESTS
+WHERE HASHED_LINK_TOKEN = sqlc.arg(hashed_link_token) AND HASHED_LINK_TOKEN <> '' LIMIT 1;
+
+-- name: GetMoneyRequestForUpdate :one
+SELECT * FROM MONEY_REQUESTS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingMoneyRequests :many
+SELECT * FROM MONEY_REQUESTS
+WHERE PAYER_ID = sqlc.arg(payer_id)
+AND (sqlc.arg(status)::varchar = '' OR STATUS = sqlc.arg(status))
+ORDER BY ID DESC
+LIMIT sqlc.arg(limit_)
+OFFSET sqlc.arg(offset_);
+
+-- name: ListOutgoingMoneyRequests :many
+SELECT * FROM MONEY_REQUESTS
+WHERE REQUESTER_ID = sqlc.arg(requester_id)
+AND (sqlc.arg(status)::varchar = '' OR STATUS = sqlc.arg(status))
+ORDER BY ID DESC
+LIMIT sqlc.arg(limit_)
+OFFSET sqlc.arg(offset_);
+
+-- name: UpdateMoneyRequestStatus :one
+UPDATE MONEY_REQUESTS
+SET STATUS = sqlc.arg(status), UPDATED_AT = now()
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeclineMoneyRequest :one
+UPDATE MONEY_REQUESTS
+SET STATUS = 'declined', UPDATED_AT = now()
+WHERE ID = $1 AND STATUS = 'pending' AND EXPIRES_AT > now()
+RETURNING *;
+
+-- name: ExpireMoneyRequests :execrows
+UPDATE MONEY_REQUESTS
+SET STATUS = 'expired', UPDATED_AT = now()
+WHERE STATUS = 'pending' AND EXPIRES_AT <= now();
+
+-- name: CreateMoneyRequestPayment :one
+INSERT INTO MONEY_REQUEST_PAYMENTS (
+  REQUEST_ID,
+  TRANSFER_ID,
+  PAID_BY
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetMoneyRequestPayment :one
+SELECT * FROM MONEY_REQUEST_PAYMENTS
+WHERE REQUEST_ID = $1 LIMIT 1;
\ No newline at end of file
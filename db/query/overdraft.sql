@@ -0,0 +1,31 @@
+-- name: ListOverdraftAccounts :many
+SELECT
+  A.ID,
+  A.OVERDRAFT_RATE_BPS,
+  (A.BALANCE - COALESCE((
+    SELECT SUM(E.AMOUNT) FROM ENTRIES E
+    WHERE E.ACCOUNT_ID = A.ID AND E.CREATED_AT >= sqlc.arg(end_of_day)
+  ), 0))::bigint AS END_OF_DAY_BALANCE
+FROM ACCOUNTS A
+WHERE A.ID > sqlc.arg(after_id)
+AND A.CREATED_AT < sqlc.arg(end_of_day)
+AND A.OVERDRAFT_RATE_BPS > 0
+ORDER BY A.ID
+LIMIT sqlc.arg(limit_);
+
+-- name: CreateOverdraftAccrual :execrows
+INSERT INTO OVERDRAFT_ACCRUALS (
+  ACCOUNT_ID,
+  ACCRUAL_DATE,
+  BALANCE,
+  ANNUAL_RATE_BPS,
+  AMOUNT_MICROS
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (ACCOUNT_ID, ACCRUAL_DATE) DO NOTHING;
+
+-- name: SumOverdraftAccruals :one
+SELECT COALESCE(SUM(AMOUNT_MICROS), 0)::bigint FROM OVERDRAFT_ACCRUALS
+WHERE ACCOUNT_ID = sqlc.arg(account_id)
+AND ACCRUAL_DATE >= sqlc.arg(from_date)
+AND ACCRUAL_DATE < sqlc.arg(to_date);
\ No newline at end of file
@@ -0,0 +1,64 @@
+-- name: CreatePayee :one
+INSERT INTO PAYEES (
+  USER_ID,
+  ACCOUNT_ID,
+  NICKNAME,
+  HOLDER_NAME,
+  FIRST_SAVED_AT
+) VALUES (
+  sqlc.arg(user_id), sqlc.arg(account_id), sqlc.arg(nickname), sqlc.arg(holder_name), COALESCE((
+    SELECT MIN(T.FIRST_SAVED_AT) FROM PAYEE_TRANSFERS T
+    WHERE T.USER_ID = sqlc.arg(user_id) AND T.ACCOUNT_ID = sqlc.arg(account_id)
+  ), now())
+) RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM PAYEES
+WHERE ID = $1 LIMIT 1;
+
+-- name: GetPayeeByAccount :one
+SELECT * FROM PAYEES
+WHERE USER_ID = $1 AND ACCOUNT_ID = $2 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT * FROM PAYEES
+WHERE USER_ID = $1
+ORDER BY NICKNAME, ID;
+
+-- name: DeletePayee :exec
+DELETE FROM PAYEES
+WHERE ID = $1;
+
+-- name: CreatePayeeTransfer :exec
+INSERT INTO PAYEE_TRANSFERS (
+  TRANSFER_ID,
+  PAYEE_ID,
+  USER_ID,
+  ACCOUNT_ID,
+  FIRST_SAVED_AT
+) VALUES (
+  $1, $2, $3, $4, $5
+);
+
+-- name: GetRecipientFirstSeenAt :one
+-- The cooling-off period of a recipient starts when the user first saved the account as a payee or
+-- first paid it, whichever came first. It starts now for an account the user never saved nor paid.
+SELECT COALESCE(MIN(S.SEEN_AT), now())::timestamptz FROM (
+  SELECT P.FIRST_SAVED_AT AS SEEN_AT FROM PAYEES P
+  WHERE P.USER_ID = sqlc.arg(user_id) AND P.ACCOUNT_ID = sqlc.arg(account_id)
+  UNION ALL
+  SELECT P.FIRST_SAVED_AT FROM PAYEE_TRANSFERS P
+  WHERE P.USER_ID = sqlc.arg(user_id) AND P.ACCOUNT_ID = sqlc.arg(account_id)
+  AND P.FIRST_SAVED_AT > '0001-01-01 00:00:00Z'
+  UNION ALL
+  SELECT T.CREATED_AT FROM PAYEE_TRANSFERS P
+  JOIN TRANSFERS T ON T.ID = P.TRANSFER_ID
+  WHERE P.USER_ID = sqlc.arg(user_id) AND P.ACCOUNT_ID = sqlc.arg(account_id)
+  AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+) S;
+
+-- name: GetPayeeTransferTotal :one
+SELECT COALESCE(SUM(T.AMOUNT), 0)::bigint FROM PAYEE_TRANSFERS P
+JOIN TRANSFERS T ON T.ID = P.TRANSFER_ID
+WHERE P.USER_ID = $1 AND P.ACCOUNT_ID = $2
+AND T.STATUS IN ('completed', 'pending', 'awaiting_approval');
\ No newline at end of file
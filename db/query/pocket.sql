@@ -0,0 +1,41 @@
+-- name: CreatePocket :one
+INSERT INTO POCKETS (
+  ACCOUNT_ID,
+  NAME,
+  TARGET_AMOUNT,
+  TARGET_DATE
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetPocket :one
+SELECT * FROM POCKETS
+WHERE ID = $1 LIMIT 1;
+
+-- name: GetPocketForUpdate :one
+SELECT * FROM POCKETS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPockets :many
+SELECT * FROM POCKETS
+WHERE ACCOUNT_ID = $1
+ORDER BY ID;
+
+-- name: AddPocketBalance :one
+UPDATE POCKETS
+SET BALANCE = BALANCE + sqlc.arg(amount)
+WHERE ID = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeletePocket :exec
+DELETE FROM POCKETS
+WHERE ID = $1;
+
+-- name: CreatePocketMovement :one
+INSERT INTO POCKET_MOVEMENTS (
+  POCKET_ID,
+  AMOUNT
+) VALUES (
+  $1, $2
+) RETURNING *;
\ No newline at end of file
@@ -0,0 +1,12 @@
+-- name: CreateTransferRiskAssessment :one
+INSERT INTO TRANSFER_RISK_ASSESSMENTS (
+  TRANSFER_ID,
+  DECISION,
+  MATCHED_RULES
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetTransferRiskAssessment :one
+SELECT * FROM TRANSFER_RISK_ASSESSMENTS
+WHERE TRANSFER_ID = $1 LIMIT 1;
@@ -2,9 +2,10 @@
 INSERT INTO TRANSFERS (
   FROM_ACCOUNT_ID,
   TO_ACCOUNT_ID,
-  AMOUNT
+  AMOUNT,
+  STATUS
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -12,10 +13,61 @@ SELECT * FROM TRANSFERS
 WHERE ID = $1
 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM TRANSFERS
+WHERE ID = $1
+LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateTransferStatus :one
+UPDATE TRANSFERS
+SET STATUS = $2
+WHERE ID = $1
+RETURNING *;
+
 -- name: ListTransfers :many
 SELECT * FROM TRANSFERS
 WHERE FROM_ACCOUNT_ID = $1
 OR TO_ACCOUNT_ID = $2
 ORDER BY ID
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: CountUserTransfersSince :one
+-- Like GetDailyTransferTotal, postings the bank collects are left out
+SELECT COUNT(*) FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = sqlc.arg(owner)
+AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+AND NOT EXISTS (
+  SELECT 1 FROM SYSTEM_ACCOUNTS S
+  WHERE S.ACCOUNT_ID = T.TO_ACCOUNT_ID AND S.PURPOSE <> 'escrow'
+)
+AND T.CREATED_AT > sqlc.arg(since);
+
+-- name: CountUserTransfersToAccount :one
+SELECT COUNT(*) FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = sqlc.arg(owner)
+AND T.TO_ACCOUNT_ID = sqlc.arg(to_account_id)
+AND T.STATUS = 'completed';
+
+-- name: GetUserAverageTransferAmount :one
+SELECT COALESCE(AVG(T.AMOUNT), 0)::bigint AS AVERAGE FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = sqlc.arg(owner)
+AND A.CURRENCY = sqlc.arg(currency)
+AND T.STATUS = 'completed'
+AND NOT EXISTS (
+  SELECT 1 FROM SYSTEM_ACCOUNTS S
+  WHERE S.ACCOUNT_ID = T.TO_ACCOUNT_ID AND S.PURPOSE <> 'escrow'
+)
+AND T.CREATED_AT > sqlc.arg(since);
+
+-- name: ListPendingTransfers :many
+SELECT T.*, R.MATCHED_RULES FROM TRANSFERS T
+LEFT JOIN TRANSFER_RISK_ASSESSMENTS R ON R.TRANSFER_ID = T.ID
+WHERE T.STATUS = 'pending'
+ORDER BY T.ID
+LIMIT $1
+OFFSET $2;
\ No newline at end of file
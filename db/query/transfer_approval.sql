@@ -0,0 +1,99 @@
+-- name: UpsertAccountApprovalPolicy :one
+INSERT INTO ACCOUNT_APPROVAL_POLICIES (
+  ACCOUNT_ID,
+  THRESHOLD,
+  REQUIRED_APPROVALS,
+  APPROVAL_WINDOW_MINUTES
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (ACCOUNT_ID) DO UPDATE
+SET THRESHOLD = EXCLUDED.THRESHOLD,
+  REQUIRED_APPROVALS = EXCLUDED.REQUIRED_APPROVALS,
+  APPROVAL_WINDOW_MINUTES = EXCLUDED.APPROVAL_WINDOW_MINUTES,
+  UPDATED_AT = now()
+RETURNING *;
+
+-- name: GetAccountApprovalPolicy :one
+SELECT * FROM ACCOUNT_APPROVAL_POLICIES
+WHERE ACCOUNT_ID = $1 LIMIT 1;
+
+-- name: DeleteAccountApprovalPolicy :exec
+DELETE FROM ACCOUNT_APPROVAL_POLICIES
+WHERE ACCOUNT_ID = $1;
+
+-- name: CreateAccountApprover :one
+INSERT INTO ACCOUNT_APPROVERS (
+  ACCOUNT_ID,
+  USER_ID
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: IsAccountApprover :one
+SELECT EXISTS (
+  SELECT 1 FROM ACCOUNT_APPROVERS AP
+  JOIN ACCOUNT_MEMBERS M ON M.ACCOUNT_ID = AP.ACCOUNT_ID AND M.USER_ID = AP.USER_ID
+  WHERE AP.ACCOUNT_ID = $1 AND AP.USER_ID = $2
+  AND M.STATUS = 'active'
+);
+
+-- name: DeleteAccountApprover :exec
+DELETE FROM ACCOUNT_APPROVERS
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2;
+
+-- name: CreateTransferApprovalRequest :one
+INSERT INTO TRANSFER_APPROVAL_REQUESTS (
+  TRANSFER_ID,
+  INITIATED_BY,
+  REQUIRED_APPROVALS,
+  EXPIRES_AT
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetTransferApprovalRequest :one
+SELECT * FROM TRANSFER_APPROVAL_REQUESTS
+WHERE TRANSFER_ID = $1 LIMIT 1;
+
+-- name: RestartTransferApprovalWindow :one
+UPDATE TRANSFER_APPROVAL_REQUESTS
+SET EXPIRES_AT = now() + (EXPIRES_AT - CREATED_AT)
+WHERE TRANSFER_ID = $1
+RETURNING *;
+
+-- name: CreateTransferApproval :one
+INSERT INTO TRANSFER_APPROVALS (
+  TRANSFER_ID,
+  APPROVER_ID,
+  DECISION
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CountTransferApprovals :one
+SELECT COUNT(*) FROM TRANSFER_APPROVALS
+WHERE TRANSFER_ID = $1 AND DECISION = 'approved';
+
+-- name: ListTransfersAwaitingApproval :many
+SELECT T.*, R.REQUIRED_APPROVALS, R.EXPIRES_AT FROM TRANSFERS T
+JOIN TRANSFER_APPROVAL_REQUESTS R ON R.TRANSFER_ID = T.ID
+JOIN ACCOUNT_APPROVERS AP ON AP.ACCOUNT_ID = T.FROM_ACCOUNT_ID
+WHERE AP.USER_ID = sqlc.arg(approver_id)
+AND R.INITIATED_BY <> sqlc.arg(approver_id)
+AND T.STATUS = 'awaiting_approval'
+AND R.EXPIRES_AT > now()
+AND NOT EXISTS (
+  SELECT 1 FROM TRANSFER_APPROVALS A
+  WHERE A.TRANSFER_ID = T.ID AND A.APPROVER_ID = sqlc.arg(approver_id)
+)
+ORDER BY T.ID
+LIMIT sqlc.arg(limit_)
+OFFSET sqlc.arg(offset_);
+
+-- name: ListExpiredTransferApprovals :many
+SELECT T.ID FROM TRANSFERS T
+JOIN TRANSFER_APPROVAL_REQUESTS R ON R.TRANSFER_ID = T.ID
+WHERE T.STATUS = 'awaiting_approval'
+AND R.EXPIRES_AT <= now()
+ORDER BY T.ID
+LIMIT $1;
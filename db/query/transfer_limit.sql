@@ -0,0 +1,50 @@
+-- name: GetTransferLimit :one
+SELECT * FROM TRANSFER_LIMITS
+WHERE CURRENCY = $1 LIMIT 1;
+
+-- name: GetAccountTransferLimit :one
+SELECT * FROM ACCOUNT_TRANSFER_LIMITS
+WHERE ACCOUNT_ID = $1 LIMIT 1;
+
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO ACCOUNT_TRANSFER_LIMITS (
+  ACCOUNT_ID,
+  PER_TRANSACTION_LIMIT,
+  DAILY_LIMIT,
+  UPDATED_BY
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (ACCOUNT_ID) DO UPDATE
+SET PER_TRANSACTION_LIMIT = EXCLUDED.PER_TRANSACTION_LIMIT,
+  DAILY_LIMIT = EXCLUDED.DAILY_LIMIT,
+  UPDATED_BY = EXCLUDED.UPDATED_BY,
+  UPDATED_AT = now()
+RETURNING *;
+
+-- name: DeleteAccountTransferLimit :exec
+DELETE FROM ACCOUNT_TRANSFER_LIMITS
+WHERE ACCOUNT_ID = $1;
+
+-- name: GetEffectiveTransferLimit :one
+SELECT
+  COALESCE(ATL.PER_TRANSACTION_LIMIT, TL.PER_TRANSACTION_LIMIT)::bigint AS PER_TRANSACTION_LIMIT,
+  COALESCE(ATL.DAILY_LIMIT, TL.DAILY_LIMIT)::bigint AS DAILY_LIMIT
+FROM ACCOUNTS A
+JOIN TRANSFER_LIMITS TL ON TL.CURRENCY = A.CURRENCY
+LEFT JOIN ACCOUNT_TRANSFER_LIMITS ATL ON ATL.ACCOUNT_ID = A.ID
+WHERE A.ID = $1 LIMIT 1;
+
+-- name: GetDailyTransferTotal :one
+-- Loan repayments and fees the bank collects are not payments of the user. Funding an escrow is, its
+-- money only passes through the escrow account.
+SELECT COALESCE(SUM(T.AMOUNT), 0)::bigint AS TOTAL
+FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = sqlc.arg(owner)
+AND A.CURRENCY = sqlc.arg(currency)
+AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+AND NOT EXISTS (
+  SELECT 1 FROM SYSTEM_ACCOUNTS S
+  WHERE S.ACCOUNT_ID = T.TO_ACCOUNT_ID AND S.PURPOSE <> 'escrow'
+)
+AND T.CREATED_AT > now() - INTERVAL '24 hours';
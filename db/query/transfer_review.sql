@@ -0,0 +1,13 @@
+-- name: CreateTransferReview :one
+INSERT INTO TRANSFER_REVIEWS (
+  TRANSFER_ID,
+  REVIEWER_ID,
+  DECISION,
+  COMMENT
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetTransferReview :one
+SELECT * FROM TRANSFER_REVIEWS
+WHERE TRANSFER_ID = $1 LIMIT 1;
@@ -3,9 +3,10 @@ INSERT INTO USERS (
   USERNAME,
   HASHED_PASSWORD,
   FULL_NAME,
-  EMAIL
+  EMAIL,
+  PHONE
 ) VALUES (
-  $1, $2, $3, $4
+  $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetUserById :one
@@ -15,3 +16,45 @@ WHERE ID = $1 LIMIT 1;
 -- name: GetUserByUsername :one
 SELECT * FROM USERS
 WHERE USERNAME = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM USERS
+WHERE EMAIL = $1 LIMIT 1;
+
+-- name: VerifyUserEmail :one
+-- Only verifies the email the token was sent to, a changed email stays unverified.
+UPDATE USERS
+SET EMAIL_VERIFIED_AT = now()
+WHERE ID = $1 AND EMAIL = $2
+RETURNING *;
+
+-- name: UpdateUserPassword :one
+-- The time comes from the server like the issue times of tokens, which are compared with it.
+UPDATE USERS
+SET HASHED_PASSWORD = $2, PASSWORD_CHANGED_AT = $3
+WHERE ID = $1
+RETURNING *;
+
+-- name: GetUserPasswordChangedAt :one
+SELECT PASSWORD_CHANGED_AT FROM USERS
+WHERE USERNAME = $1 LIMIT 1;
+
+-- name: GetUserForUpdate :one
+SELECT * FROM USERS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ResolveRecipientAlias :one
+-- Finds the account that receives payments sent to a username, email or phone number in a currency.
+-- Checking accounts are preferred over savings accounts, then the oldest account. Emails only match
+-- once verified, anyone can sign up with an address they do not own.
+SELECT A.ID AS ACCOUNT_ID, U.FULL_NAME FROM ACCOUNTS A
+JOIN USERS U ON U.ID = A.OWNER
+WHERE (U.USERNAME = sqlc.arg(alias)
+  OR (U.EMAIL = sqlc.arg(alias) AND U.EMAIL_VERIFIED_AT > '0001-01-01 00:00:00Z')
+  OR (U.PHONE <> '' AND U.PHONE = sqlc.arg(alias)))
+AND A.CURRENCY = sqlc.arg(currency)
+AND A.STATUS = 'active'
+AND A.TYPE <> 'internal'
+ORDER BY A.TYPE = 'checking' DESC, A.ID
+LIMIT 1;
\ No newline at end of file
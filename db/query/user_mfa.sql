@@ -0,0 +1,48 @@
+-- name: CreateUserMFA :one
+-- Starts an enrolment, or restarts one that was never confirmed. Enabled MFA is left alone.
+INSERT INTO USER_MFA (
+  USER_ID,
+  SECRET
+) VALUES (
+  $1, $2
+) ON CONFLICT (USER_ID) DO UPDATE SET
+  SECRET = EXCLUDED.SECRET,
+  LAST_USED_STEP = 0,
+  CREATED_AT = now()
+WHERE USER_MFA.STATUS = 'pending'
+RETURNING *;
+
+-- name: GetUserMFA :one
+SELECT * FROM USER_MFA
+WHERE USER_ID = $1 LIMIT 1;
+
+-- name: EnableUserMFA :one
+UPDATE USER_MFA
+SET STATUS = 'enabled', LAST_USED_STEP = $2, CONFIRMED_AT = now()
+WHERE USER_ID = $1 AND STATUS = 'pending'
+RETURNING *;
+
+-- name: UseUserMFAStep :one
+-- Fails when the step was already used, so each code is accepted once.
+UPDATE USER_MFA
+SET LAST_USED_STEP = $2
+WHERE USER_ID = $1 AND STATUS = 'enabled' AND LAST_USED_STEP < $2
+RETURNING *;
+
+-- name: CreateMFARecoveryCode :one
+INSERT INTO MFA_RECOVERY_CODES (
+  USER_ID,
+  HASHED_CODE
+) VALUES (
+  $1, $2
+) RETURNING *;
+
+-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM MFA_RECOVERY_CODES
+WHERE USER_ID = $1;
+
+-- name: UseMFARecoveryCode :one
+UPDATE MFA_RECOVERY_CODES
+SET USED_AT = now()
+WHERE USER_ID = $1 AND HASHED_CODE = $2 AND USED_AT = '0001-01-01 00:00:00Z'
+RETURNING *;
\ No newline at end of file
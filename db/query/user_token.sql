@@ -0,0 +1,22 @@
+-- name: CreateUserToken :one
+INSERT INTO USER_TOKENS (
+  USER_ID,
+  PURPOSE,
+  HASHED_TOKEN,
+  EMAIL,
+  EXPIRES_AT
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: UseUserToken :one
+-- Fails when the token is unknown, expired or already used, so each token is accepted once.
+UPDATE USER_TOKENS
+SET USED_AT = now()
+WHERE HASHED_TOKEN = $1 AND PURPOSE = $2 AND USED_AT = '0001-01-01 00:00:00Z' AND EXPIRES_AT > now()
+RETURNING *;
+
+-- name: InvalidateUserTokens :exec
+UPDATE USER_TOKENS
+SET USED_AT = now()
+WHERE USER_ID = $1 AND PURPOSE = $2 AND USED_AT = '0001-01-01 00:00:00Z';
\ No newline at end of file
@@ -13,7 +13,7 @@ const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE ACCOUNTS 
 SET BALANCE = BALANCE + $1
 WHERE ID = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -30,28 +30,127 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
 	)
 	return i, err
 }
 
+const addAccountPocketBalance = `-- name: AddAccountPocketBalance :one
+UPDATE ACCOUNTS
+SET POCKET_BALANCE = POCKET_BALANCE + $1
+WHERE ID = $2
+RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
+`
+
+type AddAccountPocketBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountPocketBalance(ctx context.Context, arg AddAccountPocketBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountPocketBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
+	)
+	return i, err
+}
+
+const addAccountReservedBalance = `-- name: AddAccountReservedBalance :one
+UPDATE ACCOUNTS
+SET RESERVED_BALANCE = RESERVED_BALANCE + $1
+WHERE ID = $2
+RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
+`
+
+type AddAccountReservedBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountReservedBalance(ctx context.Context, arg AddAccountReservedBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountReservedBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
+	)
+	return i, err
+}
+
+const countAccountsByType = `-- name: CountAccountsByType :one
+SELECT COUNT(*) FROM ACCOUNTS
+WHERE OWNER = $1 AND TYPE = $2
+AND STATUS <> 'closed'
+`
+
+type CountAccountsByTypeParams struct {
+	Owner int64  `json:"owner"`
+	Type  string `json:"type"`
+}
+
+func (q *Queries) CountAccountsByType(ctx context.Context, arg CountAccountsByTypeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countAccountsByType, arg.Owner, arg.Type)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO ACCOUNTS (
   OWNER,
   BALANCE,
-  CURRENCY
+  CURRENCY,
+  TYPE,
+  NICKNAME
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+  $1, $2, $3, $4, $5
+) RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
 `
 
 type CreateAccountParams struct {
 	Owner    int64  `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Type     string `json:"type"`
+	Nickname string `json:"nickname"`
 }
 
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+		arg.Nickname,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -59,6 +158,13 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
 	)
 	return i, err
 }
@@ -74,7 +180,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM ACCOUNTS
+SELECT id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance FROM ACCOUNTS
 WHERE ID = $1 LIMIT 1
 `
 
@@ -87,12 +193,19 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM ACCOUNTS
+SELECT id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance FROM ACCOUNTS
 WHERE ID = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -106,26 +219,44 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM ACCOUNTS
-WHERE OWNER = $1
-ORDER BY ID
-LIMIT $2
-OFFSET $3
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.reserved_balance, a.type, a.nickname, a.status, a.overdraft_limit, a.overdraft_rate_bps, a.pocket_balance FROM ACCOUNTS A
+JOIN ACCOUNT_MEMBERS M ON M.ACCOUNT_ID = A.ID
+WHERE M.USER_ID = $1 AND M.STATUS = 'active'
+AND ($2::varchar = '' OR A.TYPE = $2)
+AND ($3::varchar = '' OR A.CURRENCY = $3)
+ORDER BY A.ID
+LIMIT $5
+OFFSET $4
 `
 
 type ListAccountsParams struct {
-	Owner  int64 `json:"owner"`
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	UserID   int64  `json:"user_id"`
+	Type     string `json:"type"`
+	Currency string `json:"currency"`
+	Offset   int32  `json:"offset_"`
+	Limit    int32  `json:"limit_"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts,
+		arg.UserID,
+		arg.Type,
+		arg.Currency,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -139,6 +270,13 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.ReservedBalance,
+			&i.Type,
+			&i.Nickname,
+			&i.Status,
+			&i.OverdraftLimit,
+			&i.OverdraftRateBps,
+			&i.PocketBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -157,7 +295,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE ACCOUNTS 
 SET BALANCE = $2
 WHERE ID = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
 `
 
 type UpdateAccountParams struct {
@@ -174,6 +312,79 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
+	)
+	return i, err
+}
+
+const updateAccountOverdraft = `-- name: UpdateAccountOverdraft :one
+UPDATE ACCOUNTS
+SET OVERDRAFT_LIMIT = $1,
+    OVERDRAFT_RATE_BPS = $2
+WHERE ID = $3
+RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
+`
+
+type UpdateAccountOverdraftParams struct {
+	OverdraftLimit   int64 `json:"overdraft_limit"`
+	OverdraftRateBps int32 `json:"overdraft_rate_bps"`
+	ID               int64 `json:"id"`
+}
+
+func (q *Queries) UpdateAccountOverdraft(ctx context.Context, arg UpdateAccountOverdraftParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountOverdraft, arg.OverdraftLimit, arg.OverdraftRateBps, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE ACCOUNTS
+SET STATUS = $1
+WHERE ID = $2
+RETURNING id, owner, balance, currency, created_at, reserved_balance, type, nickname, status, overdraft_limit, overdraft_rate_bps, pocket_balance
+`
+
+type UpdateAccountStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.Status, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
 	)
 	return i, err
 }
@@ -0,0 +1,151 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: account_member.sql
+
+package db
+
+import (
+	"context"
+)
+
+const acceptAccountMember = `-- name: AcceptAccountMember :one
+UPDATE ACCOUNT_MEMBERS
+SET STATUS = 'active'
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2 AND STATUS = 'invited'
+RETURNING account_id, user_id, role, status, invited_by, created_at
+`
+
+type AcceptAccountMemberParams struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) AcceptAccountMember(ctx context.Context, arg AcceptAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, acceptAccountMember, arg.AccountID, arg.UserID)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.UserID,
+		&i.Role,
+		&i.Status,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAccountMember = `-- name: CreateAccountMember :one
+INSERT INTO ACCOUNT_MEMBERS (
+  ACCOUNT_ID,
+  USER_ID,
+  ROLE,
+  STATUS,
+  INVITED_BY
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING account_id, user_id, role, status, invited_by, created_at
+`
+
+type CreateAccountMemberParams struct {
+	AccountID int64  `json:"account_id"`
+	UserID    int64  `json:"user_id"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	InvitedBy int64  `json:"invited_by"`
+}
+
+func (q *Queries) CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, createAccountMember,
+		arg.AccountID,
+		arg.UserID,
+		arg.Role,
+		arg.Status,
+		arg.InvitedBy,
+	)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.UserID,
+		&i.Role,
+		&i.Status,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAccountMember = `-- name: DeleteAccountMember :exec
+DELETE FROM ACCOUNT_MEMBERS
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2
+`
+
+type DeleteAccountMemberParams struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountMember, arg.AccountID, arg.UserID)
+	return err
+}
+
+const getAccountMember = `-- name: GetAccountMember :one
+SELECT account_id, user_id, role, status, invited_by, created_at FROM ACCOUNT_MEMBERS
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2 LIMIT 1
+`
+
+type GetAccountMemberParams struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error) {
+	row := q.db.QueryRowContext(ctx, getAccountMember, arg.AccountID, arg.UserID)
+	var i AccountMember
+	err := row.Scan(
+		&i.AccountID,
+		&i.UserID,
+		&i.Role,
+		&i.Status,
+		&i.InvitedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountMembers = `-- name: ListAccountMembers :many
+SELECT account_id, user_id, role, status, invited_by, created_at FROM ACCOUNT_MEMBERS
+WHERE ACCOUNT_ID = $1
+ORDER BY CREATED_AT
+`
+
+func (q *Queries) ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountMembers, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountMember{}
+	for rows.Next() {
+		var i AccountMember
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.UserID,
+			&i.Role,
+			&i.Status,
+			&i.InvitedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountMembers(t *testing.T) {
+	account := createRandomAccountWithCurrency(t, util.USD, 0)
+	user := createRandomUser(t)
+
+	arg := CreateAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+		Role:      AccountRoleCoOwner,
+		Status:    MemberStatusInvited,
+		InvitedBy: account.Owner,
+	}
+
+	member, err := testQueries.CreateAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Role, member.Role)
+	require.Equal(t, MemberStatusInvited, member.Status)
+	require.Equal(t, account.Owner, member.InvitedBy)
+
+	// invited users do not see the account until they accept
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{UserID: user.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, accounts)
+
+	member, err = testQueries.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, MemberStatusActive, member.Status)
+
+	_, err = testQueries.AcceptAccountMember(context.Background(), AcceptAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	accounts, err = testQueries.ListAccounts(context.Background(), ListAccountsParams{UserID: user.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+
+	members, err := testQueries.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	require.Equal(t, AccountRoleOwner, members[0].Role)
+
+	err = testQueries.DeleteAccountMember(context.Background(), DeleteAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
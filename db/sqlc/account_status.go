@@ -0,0 +1,206 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid account status transition")
+	ErrFrozenByAdmin           = errors.New("account was frozen by an admin")
+	ErrAccountHasHeldFunds     = errors.New("account has transfers that are still being held")
+	ErrNonZeroBalance          = errors.New("account balance must be zero or swept to another account")
+	ErrInvalidSweepAccount     = errors.New("sweep account must be another active account of the same owner and currency")
+)
+
+// AccountNotActiveError is returned when money would move in or out of a frozen or closed account
+type AccountNotActiveError struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+}
+
+func (e *AccountNotActiveError) Error() string {
+	return fmt.Sprintf("account %d is %s", e.AccountID, e.Status)
+}
+
+func checkAccountsActive(accounts ...Account) error {
+	for _, account := range accounts {
+		if account.Status != AccountStatusActive {
+			return &AccountNotActiveError{AccountID: account.ID, Status: account.Status}
+		}
+	}
+	return nil
+}
+
+// checkTransferAccountsActive is used by transfers that are held instead of posted, posted
+// transfers check the account rows they have locked
+func checkTransferAccountsActive(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64) error {
+	for _, id := range []int64{fromAccountID, toAccountID} {
+		account, err := q.GetAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = checkAccountsActive(account)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// accountStatusTransitions lists the statuses each status can move to, closed accounts stay closed
+var accountStatusTransitions = map[string][]string{
+	AccountStatusActive: {AccountStatusFrozen, AccountStatusClosed},
+	AccountStatusFrozen: {AccountStatusActive, AccountStatusClosed},
+}
+
+func isValidStatusTransition(from string, to string) bool {
+	for _, status := range accountStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	ChangedBy int64  `json:"changed_by"`
+	// ActorRole is either owner or admin, owners cannot undo a freeze made by an admin
+	ActorRole string `json:"actor_role"`
+	Reason    string `json:"reason"`
+	// SweepAccountID receives the remaining balance when the account is closed
+	SweepAccountID int64 `json:"sweep_account_id"`
+}
+
+type ChangeAccountStatusTxResult struct {
+	Account      Account             `json:"account"`
+	StatusChange AccountStatusChange `json:"status_change"`
+	// Interest holds the interest that was accrued but not yet posted when the account was closed
+	Interest []CapitalizeInterestTxResult `json:"interest,omitempty"`
+	// Sweep is only set when the balance of a closed account was moved to the sweep account
+	Sweep *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// ChangeAccountStatusTx moves an account to another status and records who did it
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if !isValidStatusTransition(account.Status, arg.Status) {
+			return ErrInvalidStatusTransition
+		}
+
+		if account.Status == AccountStatusFrozen && arg.ActorRole != AccountStatusChangeByAdmin {
+			freeze, err := q.GetLastAccountStatusChange(ctx, GetLastAccountStatusChangeParams{
+				AccountID: account.ID,
+				ToStatus:  AccountStatusFrozen,
+			})
+			if err != nil {
+				return err
+			}
+
+			if freeze.ActorRole == AccountStatusChangeByAdmin {
+				return ErrFrozenByAdmin
+			}
+		}
+
+		if arg.Status == AccountStatusClosed {
+			// interest is owed up to the day of closing and is swept with the balance
+			result.Interest, err = capitalizeUnpostedInterest(ctx, q, account)
+			if err != nil {
+				return err
+			}
+
+			if len(result.Interest) > 0 {
+				account, err = q.GetAccountForUpdate(ctx, account.ID)
+				if err != nil {
+					return err
+				}
+			}
+
+			result.Sweep, err = sweepAccount(ctx, q, account, arg.SweepAccountID)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     account.ID,
+			Status: arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.StatusChange, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  account.ID,
+			FromStatus: account.Status,
+			ToStatus:   arg.Status,
+			ChangedBy:  arg.ChangedBy,
+			ActorRole:  arg.ActorRole,
+			Reason:     arg.Reason,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// sweepAccount empties an account that is being closed into another account of the same owner
+func sweepAccount(ctx context.Context, q *Queries, account Account, sweepAccountID int64) (*TransferTxResult, error) {
+	if account.ReservedBalance != 0 {
+		return nil, ErrAccountHasHeldFunds
+	}
+
+	if account.PocketBalance != 0 {
+		return nil, ErrAccountHasPocketFunds
+	}
+
+	if account.Balance == 0 {
+		return nil, nil
+	}
+
+	if account.Balance < 0 || sweepAccountID == 0 {
+		return nil, ErrNonZeroBalance
+	}
+
+	target, err := q.GetAccount(ctx, sweepAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.ID == account.ID ||
+		target.Owner != account.Owner ||
+		target.Currency != account.Currency ||
+		target.Status != AccountStatusActive {
+		return nil, ErrInvalidSweepAccount
+	}
+
+	var result TransferTxResult
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: account.ID,
+		ToAccountID:   target.ID,
+		Amount:        account.Balance,
+		Status:        TransferStatusCompleted,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = postTransfer(ctx, q, result.Transfer, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,121 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: account_status.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAccountStatusChange = `-- name: CreateAccountStatusChange :one
+INSERT INTO ACCOUNT_STATUS_CHANGES (
+  ACCOUNT_ID,
+  FROM_STATUS,
+  TO_STATUS,
+  CHANGED_BY,
+  ACTOR_ROLE,
+  REASON
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING id, account_id, from_status, to_status, changed_by, actor_role, reason, created_at
+`
+
+type CreateAccountStatusChangeParams struct {
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedBy  int64  `json:"changed_by"`
+	ActorRole  string `json:"actor_role"`
+	Reason     string `json:"reason"`
+}
+
+func (q *Queries) CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, createAccountStatusChange,
+		arg.AccountID,
+		arg.FromStatus,
+		arg.ToStatus,
+		arg.ChangedBy,
+		arg.ActorRole,
+		arg.Reason,
+	)
+	var i AccountStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedBy,
+		&i.ActorRole,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastAccountStatusChange = `-- name: GetLastAccountStatusChange :one
+SELECT id, account_id, from_status, to_status, changed_by, actor_role, reason, created_at FROM ACCOUNT_STATUS_CHANGES
+WHERE ACCOUNT_ID = $1 AND TO_STATUS = $2
+ORDER BY ID DESC
+LIMIT 1
+`
+
+type GetLastAccountStatusChangeParams struct {
+	AccountID int64  `json:"account_id"`
+	ToStatus  string `json:"to_status"`
+}
+
+func (q *Queries) GetLastAccountStatusChange(ctx context.Context, arg GetLastAccountStatusChangeParams) (AccountStatusChange, error) {
+	row := q.db.QueryRowContext(ctx, getLastAccountStatusChange, arg.AccountID, arg.ToStatus)
+	var i AccountStatusChange
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.FromStatus,
+		&i.ToStatus,
+		&i.ChangedBy,
+		&i.ActorRole,
+		&i.Reason,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAccountStatusChanges = `-- name: ListAccountStatusChanges :many
+SELECT id, account_id, from_status, to_status, changed_by, actor_role, reason, created_at FROM ACCOUNT_STATUS_CHANGES
+WHERE ACCOUNT_ID = $1
+ORDER BY ID
+`
+
+func (q *Queries) ListAccountStatusChanges(ctx context.Context, accountID int64) ([]AccountStatusChange, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountStatusChanges, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AccountStatusChange{}
+	for rows.Next() {
+		var i AccountStatusChange
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.FromStatus,
+			&i.ToStatus,
+			&i.ChangedBy,
+			&i.ActorRole,
+			&i.Reason,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
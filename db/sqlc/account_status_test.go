@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/interest"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeAccount(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 100)
+
+	result, err := store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account2.ID,
+		Status:    AccountStatusFrozen,
+		ChangedBy: admin.ID,
+		ActorRole: AccountStatusChangeByAdmin,
+		Reason:    "suspicious activity",
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, result.Account.Status)
+	require.Equal(t, AccountStatusActive, result.StatusChange.FromStatus)
+	require.Equal(t, AccountStatusFrozen, result.StatusChange.ToStatus)
+
+	// frozen accounts can neither send nor receive money
+	for _, arg := range []TransferTxParams{
+		{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 10},
+		{FromAccountID: account2.ID, ToAccountID: account1.ID, Amount: 10},
+	} {
+		_, err = store.TransferTx(context.Background(), arg)
+		var notActiveErr *AccountNotActiveError
+		require.True(t, errors.As(err, &notActiveErr))
+		require.Equal(t, account2.ID, notActiveErr.AccountID)
+	}
+
+	// the owner cannot undo a freeze made by an admin
+	_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account2.ID,
+		Status:    AccountStatusActive,
+		ChangedBy: account2.Owner,
+		ActorRole: AccountStatusChangeByOwner,
+	})
+	require.ErrorIs(t, err, ErrFrozenByAdmin)
+
+	result, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account2.ID,
+		Status:    AccountStatusActive,
+		ChangedBy: admin.ID,
+		ActorRole: AccountStatusChangeByAdmin,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, result.Account.Status)
+
+	changes, err := testQueries.ListAccountStatusChanges(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+}
+
+func TestCloseAccount(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+
+	sweepAccount, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    account1.Owner,
+			Currency: util.USD,
+			Type:     util.Savings,
+		},
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+
+	arg := ChangeAccountStatusTxParams{
+		AccountID: account1.ID,
+		Status:    AccountStatusClosed,
+		ChangedBy: account1.Owner,
+		ActorRole: AccountStatusChangeByOwner,
+	}
+
+	_, err = store.ChangeAccountStatusTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrNonZeroBalance)
+
+	other := createRandomAccountWithCurrency(t, util.USD, 0)
+	arg.SweepAccountID = other.ID
+	_, err = store.ChangeAccountStatusTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidSweepAccount)
+
+	arg.SweepAccountID = sweepAccount.ID
+	result, err := store.ChangeAccountStatusTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, int64(100), result.Sweep.Transfer.Amount)
+	require.Equal(t, int64(100), result.Sweep.ToAccount.Balance)
+
+	// closed accounts stay closed
+	arg.Status = AccountStatusActive
+	_, err = store.ChangeAccountStatusTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInvalidStatusTransition)
+
+	// but they no longer count towards the account limit of the owner
+	account2, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    account1.Owner,
+			Currency: util.USD,
+			Type:     util.Checking,
+		},
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusActive, account2.Status)
+}
+
+func TestCloseAccountCapitalizesInterest(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomSavingsAccount(t, 100)
+	periodStart := interest.MonthStart(time.Now())
+
+	sweepAccount, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    account.Owner,
+			Currency: util.USD,
+			Type:     util.Checking,
+		},
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+
+	for _, date := range []time.Time{periodStart.AddDate(0, -1, 0), periodStart} {
+		_, err = testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:     account.ID,
+			AccrualDate:   date,
+			Balance:       100,
+			AnnualRateBps: 200,
+			AmountMicros:  1_500_000,
+		})
+		require.NoError(t, err)
+	}
+
+	result, err := store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID:      account.ID,
+		Status:         AccountStatusClosed,
+		ChangedBy:      account.Owner,
+		ActorRole:      AccountStatusChangeByOwner,
+		SweepAccountID: sweepAccount.ID,
+	})
+	require.NoError(t, err)
+
+	// both months are posted, the carry of the first is added to the second
+	require.Len(t, result.Interest, 2)
+	require.Equal(t, int64(1), result.Interest[0].Posting.Amount)
+	require.Equal(t, int64(2), result.Interest[1].Posting.Amount)
+
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, int64(103), result.Sweep.Transfer.Amount)
+	require.Zero(t, result.Account.Balance)
+}
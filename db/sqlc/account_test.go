@@ -18,13 +18,35 @@ func createRandomAccount(t *testing.T) (Account, CreateAccountParams, error) {
 		Owner:    user.ID,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Type:     util.Checking,
+		Nickname: util.RandomString(6),
 	}
 
-	account, err := testQueries.CreateAccount(context.Background(), arg)
+	account, err := NewStore(testDB).CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: arg,
+		MaxAccounts:         1,
+	})
 
 	return account, arg, err
 }
 
+func createRandomAccountWithCurrency(t *testing.T, currency string, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := NewStore(testDB).CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.ID,
+			Balance:  balance,
+			Currency: currency,
+			Type:     util.Checking,
+		},
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
 func TestCreateAccount(t *testing.T) {
 	account, expected, err := createRandomAccount(t)
 
@@ -34,9 +56,19 @@ func TestCreateAccount(t *testing.T) {
 	require.Equal(t, expected.Owner, account.Owner)
 	require.Equal(t, expected.Balance, account.Balance)
 	require.Equal(t, expected.Currency, account.Currency)
+	require.Equal(t, expected.Type, account.Type)
+	require.Equal(t, expected.Nickname, account.Nickname)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
+
+	member, err := testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountRoleOwner, member.Role)
+	require.Equal(t, MemberStatusActive, member.Status)
 }
 
 func TestGetAccount(t *testing.T) {
@@ -92,7 +124,7 @@ func TestListAccounts(t *testing.T) {
 	}
 
 	arg := ListAccountsParams{
-		Owner:  lastAccount.Owner,
+		UserID: lastAccount.Owner,
 		Limit:  5,
 		Offset: 0,
 	}
@@ -107,3 +139,66 @@ func TestListAccounts(t *testing.T) {
 		require.Equal(t, lastAccount.Owner, account.Owner)
 	}
 }
+
+func TestCreateAccountTxLimit(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.ID,
+			Currency: util.USD,
+			Type:     util.Savings,
+		},
+		MaxAccounts: 2,
+	}
+
+	// several accounts of the same currency are allowed up to the limit of the type
+	for _, nickname := range []string{"Savings", "Bills"} {
+		arg.Nickname = nickname
+		account, err := store.CreateAccountTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Equal(t, nickname, account.Nickname)
+	}
+
+	arg.Nickname = "Holiday"
+	_, err := store.CreateAccountTx(context.Background(), arg)
+	var limitErr *AccountLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, util.Savings, limitErr.Type)
+
+	// other types have their own limit
+	arg.Type = util.Checking
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		UserID:   user.ID,
+		Type:     util.Savings,
+		Currency: util.USD,
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	for _, account := range accounts {
+		require.Equal(t, util.Savings, account.Type)
+	}
+}
+
+func TestCreateAccountTxNoLimit(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.ID,
+			Currency: util.USD,
+			Type:     util.Checking,
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := store.CreateAccountTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+}
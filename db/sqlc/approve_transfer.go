@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrTransferNotAwaitingApproval = errors.New("transfer is not awaiting approval")
+	ErrApprovalExpired             = errors.New("approval window of the transfer has expired")
+	ErrNotApprover                 = errors.New("user cannot approve transfers of this account")
+)
+
+type RequestTransferApprovalTxParams struct {
+	ToAccountID   int64 `json:"to_account_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	Amount        int64 `json:"amount"`
+	// InitiatedBy is the user who made the transfer, they cannot approve it themselves
+	InitiatedBy       int64     `json:"initiated_by"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	EnforceLimits     bool      `json:"enforce_limits"`
+	RiskDecision      string    `json:"risk_decision"`
+	MatchedRules      []string  `json:"matched_rules"`
+	// Payee records the transfer against its recipient for the cooling-off limit
+	Payee *PayeeTransferParams `json:"payee,omitempty"`
+}
+
+type RequestTransferApprovalTxResult struct {
+	Transfer        Transfer                `json:"transfer"`
+	ApprovalRequest TransferApprovalRequest `json:"approval_request"`
+}
+
+// RequestTransferApprovalTx records a transfer that has to be approved by the approvers of the
+// from account before it is posted. Its amount is reserved until then.
+func (store *SQLStore) RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (RequestTransferApprovalTxResult, error) {
+	var result RequestTransferApprovalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.EnforceLimits {
+			err = checkTransferLimits(ctx, q, arg.FromAccountID, arg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Status:        TransferStatusAwaitingApproval,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordPayeeTransfer(ctx, q, arg.Payee, result.Transfer)
+		if err != nil {
+			return err
+		}
+
+		if arg.RiskDecision != "" {
+			_, err = q.CreateTransferRiskAssessment(ctx, CreateTransferRiskAssessmentParams{
+				TransferID:   result.Transfer.ID,
+				Decision:     arg.RiskDecision,
+				MatchedRules: arg.MatchedRules,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = checkTransferAccountsActive(ctx, q, arg.FromAccountID, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		err = reserveBalance(ctx, q, arg.FromAccountID, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.ApprovalRequest, err = q.CreateTransferApprovalRequest(ctx, CreateTransferApprovalRequestParams{
+			TransferID:        result.Transfer.ID,
+			InitiatedBy:       arg.InitiatedBy,
+			RequiredApprovals: arg.RequiredApprovals,
+			ExpiresAt:         arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type DecideTransferApprovalTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	ApproverID int64 `json:"approver_id"`
+	// Decision is either approved or declined
+	Decision string `json:"decision"`
+}
+
+type DecideTransferApprovalTxResult struct {
+	TransferTxResult
+	Approval TransferApproval `json:"approval"`
+}
+
+// DecideTransferApprovalTx records the decision of an approver. A single decline releases the
+// transfer, it is posted as soon as the required number of approvals is reached.
+func (store *SQLStore) DecideTransferApprovalTx(ctx context.Context, arg DecideTransferApprovalTxParams) (DecideTransferApprovalTxResult, error) {
+	var result DecideTransferApprovalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		transfer, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if transfer.Status != TransferStatusAwaitingApproval {
+			return ErrTransferNotAwaitingApproval
+		}
+
+		request, err := q.GetTransferApprovalRequest(ctx, transfer.ID)
+		if err != nil {
+			return err
+		}
+
+		// the transfer is expired by ExpireTransferApprovalTx, it is only refused here
+		if !time.Now().Before(request.ExpiresAt) {
+			return ErrApprovalExpired
+		}
+
+		if request.InitiatedBy == arg.ApproverID {
+			return ErrNotApprover
+		}
+
+		isApprover, err := q.IsAccountApprover(ctx, IsAccountApproverParams{
+			AccountID: transfer.FromAccountID,
+			UserID:    arg.ApproverID,
+		})
+		if err != nil {
+			return err
+		}
+		if !isApprover {
+			return ErrNotApprover
+		}
+
+		result.Approval, err = q.CreateTransferApproval(ctx, CreateTransferApprovalParams{
+			TransferID: transfer.ID,
+			ApproverID: arg.ApproverID,
+			Decision:   arg.Decision,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.Decision == ApprovalDecisionDeclined {
+			result.Transfer, result.FromAccount, err = releaseHeldTransfer(ctx, q, transfer.ID, TransferStatusDeclined)
+			return err
+		}
+
+		approvals, err := q.CountTransferApprovals(ctx, transfer.ID)
+		if err != nil {
+			return err
+		}
+
+		if approvals < int64(request.RequiredApprovals) {
+			result.Transfer = transfer
+			return nil
+		}
+
+		return completeHeldTransfer(ctx, q, transfer.ID, &result.TransferTxResult)
+	})
+
+	return result, err
+}
+
+// ExpireTransferApprovalTx releases a transfer whose approval window has passed. Transfers that
+// have been decided in the meantime are returned unchanged.
+func (store *SQLStore) ExpireTransferApprovalTx(ctx context.Context, transferID int64) (Transfer, error) {
+	var transfer Transfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		transfer, err = q.GetTransferForUpdate(ctx, transferID)
+		if err != nil {
+			return err
+		}
+
+		if transfer.Status != TransferStatusAwaitingApproval {
+			return nil
+		}
+
+		transfer, _, err = releaseHeldTransfer(ctx, q, transferID, TransferStatusExpired)
+		return err
+	})
+
+	return transfer, err
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTransferAwaitingApproval(t *testing.T, store Store, fromAccount Account, toAccount Account, amount int64, requiredApprovals int32, expiresAt time.Time) Transfer {
+	result, err := store.RequestTransferApprovalTx(context.Background(), RequestTransferApprovalTxParams{
+		FromAccountID:     fromAccount.ID,
+		ToAccountID:       toAccount.ID,
+		Amount:            amount,
+		InitiatedBy:       fromAccount.Owner,
+		RequiredApprovals: requiredApprovals,
+		ExpiresAt:         expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusAwaitingApproval, result.Transfer.Status)
+	require.Equal(t, result.Transfer.ID, result.ApprovalRequest.TransferID)
+	require.Equal(t, fromAccount.Owner, result.ApprovalRequest.InitiatedBy)
+
+	return result.Transfer
+}
+
+func createRandomApprover(t *testing.T, account Account) User {
+	user := createRandomUser(t)
+
+	// only active members of the account can approve its transfers
+	_, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+		Role:      AccountRoleCoOwner,
+		Status:    MemberStatusActive,
+		InvitedBy: account.Owner,
+	})
+	require.NoError(t, err)
+
+	approver, err := testQueries.CreateAccountApprover(context.Background(), CreateAccountApproverParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, approver.AccountID)
+	require.Equal(t, user.ID, approver.UserID)
+
+	return user
+}
+
+func TestDecideTransferApprovalTxQuorum(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+	approver1 := createRandomApprover(t, account1)
+	approver2 := createRandomApprover(t, account1)
+	outsider := createRandomUser(t)
+	amount := int64(60)
+
+	transfer := createTransferAwaitingApproval(t, store, account1, account2, amount, 2, time.Now().Add(time.Hour))
+
+	awaiting, err := testQueries.ListTransfersAwaitingApproval(context.Background(), ListTransfersAwaitingApprovalParams{
+		ApproverID: approver1.ID,
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Len(t, awaiting, 1)
+	require.Equal(t, transfer.ID, awaiting[0].ID)
+
+	// the user who made the transfer and users outside of the approvers cannot approve it
+	for _, userID := range []int64{account1.Owner, outsider.ID} {
+		_, err = store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+			TransferID: transfer.ID,
+			ApproverID: userID,
+			Decision:   ApprovalDecisionApproved,
+		})
+		require.ErrorIs(t, err, ErrNotApprover)
+	}
+
+	result, err := store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: transfer.ID,
+		ApproverID: approver1.ID,
+		Decision:   ApprovalDecisionApproved,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusAwaitingApproval, result.Transfer.Status)
+	require.Equal(t, approver1.ID, result.Approval.ApproverID)
+
+	// an approver can only decide once
+	_, err = store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: transfer.ID,
+		ApproverID: approver1.ID,
+		Decision:   ApprovalDecisionApproved,
+	})
+	require.Error(t, err)
+
+	result, err = store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: transfer.ID,
+		ApproverID: approver2.ID,
+		Decision:   ApprovalDecisionApproved,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.ReservedBalance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, amount, result.ToEntry.Amount)
+}
+
+func TestDecideTransferApprovalTxDecline(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+	approver := createRandomApprover(t, account1)
+
+	transfer := createTransferAwaitingApproval(t, store, account1, account2, 60, 1, time.Now().Add(time.Hour))
+
+	result, err := store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: transfer.ID,
+		ApproverID: approver.ID,
+		Decision:   ApprovalDecisionDeclined,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusDeclined, result.Transfer.Status)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.ReservedBalance)
+
+	_, err = store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: transfer.ID,
+		ApproverID: approver.ID,
+		Decision:   ApprovalDecisionApproved,
+	})
+	require.ErrorIs(t, err, ErrTransferNotAwaitingApproval)
+}
+
+func TestExpireTransferApprovalTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+	approver := createRandomApprover(t, account1)
+
+	transfer := createTransferAwaitingApproval(t, store, account1, account2, 60, 1, time.Now().Add(-time.Minute))
+
+	_, err := store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: transfer.ID,
+		ApproverID: approver.ID,
+		Decision:   ApprovalDecisionApproved,
+	})
+	require.ErrorIs(t, err, ErrApprovalExpired)
+
+	expired, err := testQueries.ListExpiredTransferApprovals(context.Background(), 1000)
+	require.NoError(t, err)
+	require.Contains(t, expired, transfer.ID)
+
+	transfer, err = store.ExpireTransferApprovalTx(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusExpired, transfer.Status)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Zero(t, updatedAccount1.ReservedBalance)
+
+	// expiring twice leaves the transfer unchanged
+	transfer, err = store.ExpireTransferApprovalTx(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusExpired, transfer.Status)
+}
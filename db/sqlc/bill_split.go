@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// BillSplitShareParams is the part of a split requested from one participant
+type BillSplitShareParams struct {
+	UserID int64 `json:"user_id"`
+	Amount int64 `json:"amount"`
+}
+
+type CreateBillSplitTxParams struct {
+	CreatorID   int64  `json:"creator_id"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Memo        string `json:"memo"`
+	Method      string `json:"method"`
+	// CreatorShare is not requested from anyone, it includes the rounding remainder
+	CreatorShare int64                  `json:"creator_share"`
+	Shares       []BillSplitShareParams `json:"shares"`
+	// ExpiresAt is when the money requests of the participants expire
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CreateBillSplitTxResult struct {
+	Split    BillSplit        `json:"split"`
+	Shares   []BillSplitShare `json:"shares"`
+	Requests []MoneyRequest   `json:"requests"`
+}
+
+// CreateBillSplitTx records a split and sends a money request for the share of every participant
+func (store *SQLStore) CreateBillSplitTx(ctx context.Context, arg CreateBillSplitTxParams) (CreateBillSplitTxResult, error) {
+	var result CreateBillSplitTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Split, err = q.CreateBillSplit(ctx, CreateBillSplitParams{
+			CreatorID:    arg.CreatorID,
+			ToAccountID:  arg.ToAccountID,
+			Amount:       arg.Amount,
+			Currency:     arg.Currency,
+			Memo:         arg.Memo,
+			Method:       arg.Method,
+			CreatorShare: arg.CreatorShare,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Shares = make([]BillSplitShare, len(arg.Shares))
+		result.Requests = make([]MoneyRequest, len(arg.Shares))
+
+		for i, share := range arg.Shares {
+			result.Requests[i], err = q.CreateMoneyRequest(ctx, CreateMoneyRequestParams{
+				RequesterID: arg.CreatorID,
+				ToAccountID: arg.ToAccountID,
+				PayerID:     share.UserID,
+				Amount:      share.Amount,
+				Currency:    arg.Currency,
+				Memo:        arg.Memo,
+				ExpiresAt:   arg.ExpiresAt,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.Shares[i], err = q.CreateBillSplitShare(ctx, CreateBillSplitShareParams{
+				SplitID:   result.Split.ID,
+				UserID:    share.UserID,
+				Amount:    share.Amount,
+				RequestID: result.Requests[i].ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,164 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: bill_split.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createBillSplit = `-- name: CreateBillSplit :one
+INSERT INTO BILL_SPLITS (
+  CREATOR_ID,
+  TO_ACCOUNT_ID,
+  AMOUNT,
+  CURRENCY,
+  MEMO,
+  METHOD,
+  CREATOR_SHARE
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, creator_id, to_account_id, amount, currency, memo, method, creator_share, created_at
+`
+
+type CreateBillSplitParams struct {
+	CreatorID    int64  `json:"creator_id"`
+	ToAccountID  int64  `json:"to_account_id"`
+	Amount       int64  `json:"amount"`
+	Currency     string `json:"currency"`
+	Memo         string `json:"memo"`
+	Method       string `json:"method"`
+	CreatorShare int64  `json:"creator_share"`
+}
+
+func (q *Queries) CreateBillSplit(ctx context.Context, arg CreateBillSplitParams) (BillSplit, error) {
+	row := q.db.QueryRowContext(ctx, createBillSplit,
+		arg.CreatorID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.Memo,
+		arg.Method,
+		arg.CreatorShare,
+	)
+	var i BillSplit
+	err := row.Scan(
+		&i.ID,
+		&i.CreatorID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Method,
+		&i.CreatorShare,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createBillSplitShare = `-- name: CreateBillSplitShare :one
+INSERT INTO BILL_SPLIT_SHARES (
+  SPLIT_ID,
+  USER_ID,
+  AMOUNT,
+  REQUEST_ID
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING split_id, user_id, amount, request_id
+`
+
+type CreateBillSplitShareParams struct {
+	SplitID   int64 `json:"split_id"`
+	UserID    int64 `json:"user_id"`
+	Amount    int64 `json:"amount"`
+	RequestID int64 `json:"request_id"`
+}
+
+func (q *Queries) CreateBillSplitShare(ctx context.Context, arg CreateBillSplitShareParams) (BillSplitShare, error) {
+	row := q.db.QueryRowContext(ctx, createBillSplitShare,
+		arg.SplitID,
+		arg.UserID,
+		arg.Amount,
+		arg.RequestID,
+	)
+	var i BillSplitShare
+	err := row.Scan(
+		&i.SplitID,
+		&i.UserID,
+		&i.Amount,
+		&i.RequestID,
+	)
+	return i, err
+}
+
+const getBillSplit = `-- name: GetBillSplit :one
+SELECT id, creator_id, to_account_id, amount, currency, memo, method, creator_share, created_at FROM BILL_SPLITS
+WHERE ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetBillSplit(ctx context.Context, id int64) (BillSplit, error) {
+	row := q.db.QueryRowContext(ctx, getBillSplit, id)
+	var i BillSplit
+	err := row.Scan(
+		&i.ID,
+		&i.CreatorID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Method,
+		&i.CreatorShare,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listBillSplitShares = `-- name: ListBillSplitShares :many
+SELECT S.SPLIT_ID, S.USER_ID, U.USERNAME, S.AMOUNT, S.REQUEST_ID, R.STATUS FROM BILL_SPLIT_SHARES S
+JOIN USERS U ON U.ID = S.USER_ID
+JOIN MONEY_REQUESTS R ON R.ID = S.REQUEST_ID
+WHERE S.SPLIT_ID = $1
+ORDER BY U.USERNAME
+`
+
+type ListBillSplitSharesRow struct {
+	SplitID   int64  `json:"split_id"`
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Amount    int64  `json:"amount"`
+	RequestID int64  `json:"request_id"`
+	Status    string `json:"status"`
+}
+
+// Shares of a split with the status of their money request, which tells whether they were paid
+func (q *Queries) ListBillSplitShares(ctx context.Context, splitID int64) ([]ListBillSplitSharesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listBillSplitShares, splitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListBillSplitSharesRow{}
+	for rows.Next() {
+		var i ListBillSplitSharesRow
+		if err := rows.Scan(
+			&i.SplitID,
+			&i.UserID,
+			&i.Username,
+			&i.Amount,
+			&i.RequestID,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
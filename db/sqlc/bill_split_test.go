@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBillSplitTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, util.USD, 0)
+	friend1 := createRandomAccountWithCurrency(t, util.USD, 1000)
+	friend2 := createRandomAccountWithCurrency(t, util.USD, 1000)
+
+	result, err := store.CreateBillSplitTx(context.Background(), CreateBillSplitTxParams{
+		CreatorID:    account.Owner,
+		ToAccountID:  account.ID,
+		Amount:       1000,
+		Currency:     util.USD,
+		Memo:         "Dinner",
+		Method:       "equal",
+		CreatorShare: 334,
+		Shares: []BillSplitShareParams{
+			{UserID: friend1.Owner, Amount: 333},
+			{UserID: friend2.Owner, Amount: 333},
+		},
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Shares, 2)
+	require.Len(t, result.Requests, 2)
+
+	for i, request := range result.Requests {
+		require.Equal(t, account.Owner, request.RequesterID)
+		require.Equal(t, result.Shares[i].UserID, request.PayerID)
+		require.Equal(t, int64(333), request.Amount)
+		require.Equal(t, "Dinner", request.Memo)
+	}
+
+	// shares are settled by paying their money request
+	_, err = store.PayMoneyRequestTx(context.Background(), PayMoneyRequestTxParams{
+		RequestID:     result.Requests[0].ID,
+		FromAccountID: friend1.ID,
+		PaidBy:        friend1.Owner,
+	})
+	require.NoError(t, err)
+
+	shares, err := testQueries.ListBillSplitShares(context.Background(), result.Split.ID)
+	require.NoError(t, err)
+	require.Len(t, shares, 2)
+
+	statuses := map[int64]string{}
+	for _, share := range shares {
+		statuses[share.UserID] = share.Status
+	}
+	require.Equal(t, MoneyRequestStatusPaid, statuses[friend1.Owner])
+	require.Equal(t, MoneyRequestStatusPending, statuses[friend2.Owner])
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/khorsl/simple_bank/interest"
+)
+
+var ErrInterestAlreadyPosted = errors.New("interest has already been posted for this period")
+
+type CapitalizeInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// PeriodStart is the first day of the month being capitalized
+	PeriodStart time.Time `json:"period_start"`
+}
+
+type CapitalizeInterestTxResult struct {
+	Posting InterestPosting `json:"posting"`
+	// Transfer is nil when the accrued interest is below one minor unit and is carried over
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+}
+
+// CapitalizeInterestTx posts the interest accrued by an account during a month, plus the carry of
+// the previous posting, from the interest expense account of its currency. Only whole minor units
+// are posted, the remainder is carried to the next month.
+func (store *SQLStore) CapitalizeInterestTx(ctx context.Context, arg CapitalizeInterestTxParams) (CapitalizeInterestTxResult, error) {
+	var result CapitalizeInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result, err = capitalizeInterest(ctx, q, account, arg.PeriodStart)
+		return err
+	})
+
+	return result, err
+}
+
+// capitalizeInterest must run inside a transaction that locked the account
+func capitalizeInterest(ctx context.Context, q *Queries, account Account, periodStart time.Time) (CapitalizeInterestTxResult, error) {
+	var result CapitalizeInterestTxResult
+
+	_, err := q.GetInterestPosting(ctx, GetInterestPostingParams{
+		AccountID:   account.ID,
+		PeriodStart: periodStart,
+	})
+	if err == nil {
+		return result, ErrInterestAlreadyPosted
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return result, err
+	}
+
+	accrued, err := q.SumInterestAccruals(ctx, SumInterestAccrualsParams{
+		AccountID: account.ID,
+		FromDate:  periodStart,
+		ToDate:    periodStart.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	last, err := q.GetLastInterestPosting(ctx, GetLastInterestPostingParams{
+		AccountID:   account.ID,
+		PeriodStart: periodStart,
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return result, err
+	}
+	accrued += last.CarryMicros
+
+	amount, carry := interest.Split(accrued)
+	result.Posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+		AccountID:     account.ID,
+		PeriodStart:   periodStart,
+		AccruedMicros: accrued,
+		Amount:        amount,
+		CarryMicros:   carry,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if amount == 0 {
+		return result, nil
+	}
+
+	expense, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+		Purpose:  SystemAccountInterestExpense,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// the expense account records the interest paid out, its balance is expected to go negative
+	result.Transfer = &TransferTxResult{}
+	return result, postSystemTransfer(ctx, q, expense.ID, account.ID, amount, result.Transfer)
+}
+
+// capitalizeUnpostedInterest posts the interest of every month the account accrued for and was not
+// credited yet, oldest first so that carries add up. A carry left below one minor unit is forfeited.
+func capitalizeUnpostedInterest(ctx context.Context, q *Queries, account Account) ([]CapitalizeInterestTxResult, error) {
+	periods, err := q.ListUnpostedInterestPeriods(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]CapitalizeInterestTxResult, 0, len(periods))
+	for _, periodStart := range periods {
+		result, err := capitalizeInterest(ctx, q, account, periodStart)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// AccountLimitError is returned by CreateAccountTx when the owner already has the maximum number
+// of accounts of a type
+type AccountLimitError struct {
+	Type        string `json:"type"`
+	MaxAccounts int64  `json:"max_accounts"`
+}
+
+func (e *AccountLimitError) Error() string {
+	return fmt.Sprintf("cannot have more than %d %s accounts", e.MaxAccounts, e.Type)
+}
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// MaxAccounts is the number of accounts of the same type the owner may have, zero means no limit
+	MaxAccounts int64 `json:"max_accounts"`
+}
+
+// CreateAccountTx creates an account together with the membership of its owner. The owner is
+// locked first so that parallel requests cannot go over the account limit.
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetUserForUpdate(ctx, arg.Owner)
+		if err != nil {
+			return err
+		}
+
+		if arg.MaxAccounts > 0 {
+			count, err := q.CountAccountsByType(ctx, CountAccountsByTypeParams{
+				Owner: arg.Owner,
+				Type:  arg.Type,
+			})
+			if err != nil {
+				return err
+			}
+
+			if count >= arg.MaxAccounts {
+				return &AccountLimitError{Type: arg.Type, MaxAccounts: arg.MaxAccounts}
+			}
+		}
+
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateAccountMember(ctx, CreateAccountMemberParams{
+			AccountID: account.ID,
+			UserID:    arg.Owner,
+			Role:      AccountRoleOwner,
+			Status:    MemberStatusActive,
+			InvitedBy: arg.Owner,
+		})
+		return err
+	})
+
+	return account, err
+}
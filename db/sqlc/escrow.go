@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidEscrowTransition = errors.New("invalid escrow status transition")
+	ErrEscrowStatusChanged     = errors.New("escrow status has changed")
+	ErrEscrowNotDue            = errors.New("escrow release time has not passed")
+	ErrEscrowDisputeClosed     = errors.New("escrow release time has passed, it can no longer be disputed")
+)
+
+// escrowStatusTransitions lists the statuses each escrow status can move to, released and refunded
+// escrows are settled for good
+var escrowStatusTransitions = map[string][]string{
+	EscrowStatusHeld:     {EscrowStatusReleased, EscrowStatusDisputed},
+	EscrowStatusDisputed: {EscrowStatusReleased, EscrowStatusRefunded},
+}
+
+func isValidEscrowTransition(from string, to string) bool {
+	for _, status := range escrowStatusTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateEscrowTxParams struct {
+	BuyerID         int64     `json:"buyer_id"`
+	BuyerAccountID  int64     `json:"buyer_account_id"`
+	SellerAccountID int64     `json:"seller_account_id"`
+	Amount          int64     `json:"amount"`
+	Description     string    `json:"description"`
+	ReleaseAt       time.Time `json:"release_at"`
+	// RiskDecision and MatchedRules are persisted with the funding transfer when it has been assessed
+	RiskDecision string   `json:"risk_decision"`
+	MatchedRules []string `json:"matched_rules"`
+	// Payee records the funding against the seller account for the cooling-off limit, its
+	// AccountID is set to the seller account
+	Payee *PayeeTransferParams `json:"payee,omitempty"`
+}
+
+type CreateEscrowTxResult struct {
+	Escrow  Escrow           `json:"escrow"`
+	Funding TransferTxResult `json:"funding"`
+}
+
+// CreateEscrowTx moves the amount from the buyer account into the escrow account of its currency,
+// where it is held until the escrow is released or refunded
+func (store *SQLStore) CreateEscrowTx(ctx context.Context, arg CreateEscrowTxParams) (CreateEscrowTxResult, error) {
+	var result CreateEscrowTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		seller, err := q.GetAccount(ctx, arg.SellerAccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkAccountsActive(seller)
+		if err != nil {
+			return err
+		}
+
+		escrowAccount, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Purpose:  SystemAccountEscrow,
+			Currency: seller.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		// the seller is the recipient of the funding, not the escrow account
+		payee := arg.Payee
+		if payee != nil {
+			sellerPayee := *payee
+			sellerPayee.AccountID = seller.ID
+			payee = &sellerPayee
+		}
+
+		err = runTransfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.BuyerAccountID,
+			ToAccountID:   escrowAccount.ID,
+			Amount:        arg.Amount,
+			EnforceLimits: true,
+			RiskDecision:  arg.RiskDecision,
+			MatchedRules:  arg.MatchedRules,
+			Payee:         payee,
+		}, &result.Funding)
+		if err != nil {
+			return err
+		}
+
+		result.Escrow, err = q.CreateEscrow(ctx, CreateEscrowParams{
+			BuyerID:           arg.BuyerID,
+			BuyerAccountID:    arg.BuyerAccountID,
+			SellerAccountID:   seller.ID,
+			Amount:            arg.Amount,
+			Currency:          seller.Currency,
+			Description:       arg.Description,
+			FundingTransferID: result.Funding.Transfer.ID,
+			ReleaseAt:         arg.ReleaseAt,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type ChangeEscrowStatusTxParams struct {
+	EscrowID int64 `json:"escrow_id"`
+	// FromStatus is the status the caller saw, the change is refused if the escrow moved on since
+	FromStatus string `json:"from_status"`
+	Status     string `json:"status"`
+	ChangedBy  int64  `json:"changed_by"`
+	// DisputeReason is only used when a dispute is opened
+	DisputeReason string `json:"dispute_reason"`
+	// OnlyIfDue refuses to release the escrow before its release time
+	OnlyIfDue bool `json:"only_if_due"`
+}
+
+type ChangeEscrowStatusTxResult struct {
+	Escrow Escrow `json:"escrow"`
+	// Settlement is set when the held funds were paid out to the seller or back to the buyer
+	Settlement *TransferTxResult `json:"settlement,omitempty"`
+}
+
+// ChangeEscrowStatusTx moves an escrow to another status. Releasing pays the held funds to the
+// seller and refunding pays them back to the buyer.
+func (store *SQLStore) ChangeEscrowStatusTx(ctx context.Context, arg ChangeEscrowStatusTxParams) (ChangeEscrowStatusTxResult, error) {
+	var result ChangeEscrowStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		escrow, err := q.GetEscrowForUpdate(ctx, arg.EscrowID)
+		if err != nil {
+			return err
+		}
+
+		if escrow.Status != arg.FromStatus {
+			return ErrEscrowStatusChanged
+		}
+
+		if !isValidEscrowTransition(escrow.Status, arg.Status) {
+			return ErrInvalidEscrowTransition
+		}
+
+		if arg.OnlyIfDue && time.Now().Before(escrow.ReleaseAt) {
+			return ErrEscrowNotDue
+		}
+
+		if arg.Status == EscrowStatusDisputed && !time.Now().Before(escrow.ReleaseAt) {
+			return ErrEscrowDisputeClosed
+		}
+
+		disputeReason := escrow.DisputeReason
+		if arg.Status == EscrowStatusDisputed {
+			disputeReason = arg.DisputeReason
+		}
+
+		var payeeAccountID int64
+		switch arg.Status {
+		case EscrowStatusReleased:
+			payeeAccountID = escrow.SellerAccountID
+		case EscrowStatusRefunded:
+			payeeAccountID = escrow.BuyerAccountID
+		}
+
+		if payeeAccountID != 0 {
+			result.Settlement, err = settleEscrow(ctx, q, escrow, payeeAccountID, arg.Status, arg.ChangedBy)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Escrow, err = q.UpdateEscrowStatus(ctx, UpdateEscrowStatusParams{
+			ID:            escrow.ID,
+			Status:        arg.Status,
+			DisputeReason: disputeReason,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// settleEscrow pays the held funds out of the escrow account. A frozen or closed receiving account
+// cannot be paid, the escrow stays as it is until the account is active again.
+func settleEscrow(ctx context.Context, q *Queries, escrow Escrow, toAccountID int64, outcome string, settledBy int64) (*TransferTxResult, error) {
+	escrowAccount, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+		Purpose:  SystemAccountEscrow,
+		Currency: escrow.Currency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result TransferTxResult
+
+	err = postSystemTransfer(ctx, q, escrowAccount.ID, toAccountID, escrow.Amount, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkAccountsActive(result.ToAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = q.CreateEscrowSettlement(ctx, CreateEscrowSettlementParams{
+		EscrowID:   escrow.ID,
+		TransferID: result.Transfer.ID,
+		Outcome:    outcome,
+		SettledBy:  settledBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
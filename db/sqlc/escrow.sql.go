@@ -0,0 +1,245 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: escrow.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createEscrow = `-- name: CreateEscrow :one
+INSERT INTO ESCROWS (
+  BUYER_ID,
+  BUYER_ACCOUNT_ID,
+  SELLER_ACCOUNT_ID,
+  AMOUNT,
+  CURRENCY,
+  DESCRIPTION,
+  FUNDING_TRANSFER_ID,
+  RELEASE_AT
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, buyer_id, buyer_account_id, seller_account_id, amount, currency, description, status, dispute_reason, funding_transfer_id, release_at, updated_at, created_at
+`
+
+type CreateEscrowParams struct {
+	BuyerID           int64     `json:"buyer_id"`
+	BuyerAccountID    int64     `json:"buyer_account_id"`
+	SellerAccountID   int64     `json:"seller_account_id"`
+	Amount            int64     `json:"amount"`
+	Currency          string    `json:"currency"`
+	Description       string    `json:"description"`
+	FundingTransferID int64     `json:"funding_transfer_id"`
+	ReleaseAt         time.Time `json:"release_at"`
+}
+
+func (q *Queries) CreateEscrow(ctx context.Context, arg CreateEscrowParams) (Escrow, error) {
+	row := q.db.QueryRowContext(ctx, createEscrow,
+		arg.BuyerID,
+		arg.BuyerAccountID,
+		arg.SellerAccountID,
+		arg.Amount,
+		arg.Currency,
+		arg.Description,
+		arg.FundingTransferID,
+		arg.ReleaseAt,
+	)
+	var i Escrow
+	err := row.Scan(
+		&i.ID,
+		&i.BuyerID,
+		&i.BuyerAccountID,
+		&i.SellerAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Description,
+		&i.Status,
+		&i.DisputeReason,
+		&i.FundingTransferID,
+		&i.ReleaseAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createEscrowSettlement = `-- name: CreateEscrowSettlement :one
+INSERT INTO ESCROW_SETTLEMENTS (
+  ESCROW_ID,
+  TRANSFER_ID,
+  OUTCOME,
+  SETTLED_BY
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING escrow_id, transfer_id, outcome, settled_by, created_at
+`
+
+type CreateEscrowSettlementParams struct {
+	EscrowID   int64  `json:"escrow_id"`
+	TransferID int64  `json:"transfer_id"`
+	Outcome    string `json:"outcome"`
+	SettledBy  int64  `json:"settled_by"`
+}
+
+func (q *Queries) CreateEscrowSettlement(ctx context.Context, arg CreateEscrowSettlementParams) (EscrowSettlement, error) {
+	row := q.db.QueryRowContext(ctx, createEscrowSettlement,
+		arg.EscrowID,
+		arg.TransferID,
+		arg.Outcome,
+		arg.SettledBy,
+	)
+	var i EscrowSettlement
+	err := row.Scan(
+		&i.EscrowID,
+		&i.TransferID,
+		&i.Outcome,
+		&i.SettledBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEscrow = `-- name: GetEscrow :one
+SELECT id, buyer_id, buyer_account_id, seller_account_id, amount, currency, description, status, dispute_reason, funding_transfer_id, release_at, updated_at, created_at FROM ESCROWS
+WHERE ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetEscrow(ctx context.Context, id int64) (Escrow, error) {
+	row := q.db.QueryRowContext(ctx, getEscrow, id)
+	var i Escrow
+	err := row.Scan(
+		&i.ID,
+		&i.BuyerID,
+		&i.BuyerAccountID,
+		&i.SellerAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Description,
+		&i.Status,
+		&i.DisputeReason,
+		&i.FundingTransferID,
+		&i.ReleaseAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEscrowForUpdate = `-- name: GetEscrowForUpdate :one
+SELECT id, buyer_id, buyer_account_id, seller_account_id, amount, currency, description, status, dispute_reason, funding_transfer_id, release_at, updated_at, created_at FROM ESCROWS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetEscrowForUpdate(ctx context.Context, id int64) (Escrow, error) {
+	row := q.db.QueryRowContext(ctx, getEscrowForUpdate, id)
+	var i Escrow
+	err := row.Scan(
+		&i.ID,
+		&i.BuyerID,
+		&i.BuyerAccountID,
+		&i.SellerAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Description,
+		&i.Status,
+		&i.DisputeReason,
+		&i.FundingTransferID,
+		&i.ReleaseAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getEscrowSettlement = `-- name: GetEscrowSettlement :one
+SELECT escrow_id, transfer_id, outcome, settled_by, created_at FROM ESCROW_SETTLEMENTS
+WHERE ESCROW_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetEscrowSettlement(ctx context.Context, escrowID int64) (EscrowSettlement, error) {
+	row := q.db.QueryRowContext(ctx, getEscrowSettlement, escrowID)
+	var i EscrowSettlement
+	err := row.Scan(
+		&i.EscrowID,
+		&i.TransferID,
+		&i.Outcome,
+		&i.SettledBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listDueEscrows = `-- name: ListDueEscrows :many
+SELECT ID FROM ESCROWS
+WHERE STATUS = 'held' AND RELEASE_AT <= $1 AND ID > $2
+ORDER BY ID
+LIMIT $3
+`
+
+type ListDueEscrowsParams struct {
+	ReleaseAt time.Time `json:"release_at"`
+	AfterID   int64     `json:"after_id"`
+	Limit     int32     `json:"limit_"`
+}
+
+// Held escrows whose release time has passed, disputed escrows wait for an admin
+func (q *Queries) ListDueEscrows(ctx context.Context, arg ListDueEscrowsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listDueEscrows, arg.ReleaseAt, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateEscrowStatus = `-- name: UpdateEscrowStatus :one
+UPDATE ESCROWS
+SET STATUS = $1, DISPUTE_REASON = $2, UPDATED_AT = now()
+WHERE ID = $3
+RETURNING id, buyer_id, buyer_account_id, seller_account_id, amount, currency, description, status, dispute_reason, funding_transfer_id, release_at, updated_at, created_at
+`
+
+type UpdateEscrowStatusParams struct {
+	Status        string `json:"status"`
+	DisputeReason string `json:"dispute_reason"`
+	ID            int64  `json:"id"`
+}
+
+func (q *Queries) UpdateEscrowStatus(ctx context.Context, arg UpdateEscrowStatusParams) (Escrow, error) {
+	row := q.db.QueryRowContext(ctx, updateEscrowStatus, arg.Status, arg.DisputeReason, arg.ID)
+	var i Escrow
+	err := row.Scan(
+		&i.ID,
+		&i.BuyerID,
+		&i.BuyerAccountID,
+		&i.SellerAccountID,
+		&i.Amount,
+		&i.Currency,
+		&i.Description,
+		&i.Status,
+		&i.DisputeReason,
+		&i.FundingTransferID,
+		&i.ReleaseAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,197 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createTestEscrow(t *testing.T, store Store, buyerAccount Account, sellerAccount Account, releaseAt time.Time) Escrow {
+	result, err := store.CreateEscrowTx(context.Background(), CreateEscrowTxParams{
+		BuyerID:         buyerAccount.Owner,
+		BuyerAccountID:  buyerAccount.ID,
+		SellerAccountID: sellerAccount.ID,
+		Amount:          40,
+		Description:     "Vintage camera",
+		ReleaseAt:       releaseAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusHeld, result.Escrow.Status)
+	require.Equal(t, result.Funding.Transfer.ID, result.Escrow.FundingTransferID)
+	require.Equal(t, buyerAccount.Balance-40, result.Funding.FromAccount.Balance)
+
+	return result.Escrow
+}
+
+func TestEscrowReleasedByBuyer(t *testing.T) {
+	store := NewStore(testDB)
+	buyerAccount := createRandomAccountWithCurrency(t, util.USD, 100)
+	sellerAccount := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	escrow := createTestEscrow(t, store, buyerAccount, sellerAccount, time.Now().Add(time.Hour))
+
+	// the seller is not paid before the buyer confirms
+	seller, err := testQueries.GetAccount(context.Background(), sellerAccount.ID)
+	require.NoError(t, err)
+	require.Zero(t, seller.Balance)
+
+	result, err := store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+		EscrowID:   escrow.ID,
+		FromStatus: EscrowStatusHeld,
+		Status:     EscrowStatusReleased,
+		ChangedBy:  buyerAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusReleased, result.Escrow.Status)
+	require.NotNil(t, result.Settlement)
+	require.Equal(t, int64(40), result.Settlement.ToAccount.Balance)
+
+	settlement, err := testQueries.GetEscrowSettlement(context.Background(), escrow.ID)
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusReleased, settlement.Outcome)
+	require.Equal(t, result.Settlement.Transfer.ID, settlement.TransferID)
+
+	// a released escrow is settled for good
+	_, err = store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+		EscrowID:   escrow.ID,
+		FromStatus: EscrowStatusReleased,
+		Status:     EscrowStatusRefunded,
+		ChangedBy:  buyerAccount.Owner,
+	})
+	require.ErrorIs(t, err, ErrInvalidEscrowTransition)
+
+	_, err = store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+		EscrowID:   escrow.ID,
+		FromStatus: EscrowStatusHeld,
+		Status:     EscrowStatusDisputed,
+		ChangedBy:  buyerAccount.Owner,
+	})
+	require.ErrorIs(t, err, ErrEscrowStatusChanged)
+}
+
+func TestEscrowReleasedWhenDue(t *testing.T) {
+	store := NewStore(testDB)
+	buyerAccount := createRandomAccountWithCurrency(t, util.USD, 100)
+	sellerAccount := createRandomAccountWithCurrency(t, util.USD, 0)
+	systemUser, err := testQueries.GetUserByUsername(context.Background(), SystemUsername)
+	require.NoError(t, err)
+
+	notDue := createTestEscrow(t, store, buyerAccount, sellerAccount, time.Now().Add(time.Hour))
+
+	_, err = store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+		EscrowID:   notDue.ID,
+		FromStatus: EscrowStatusHeld,
+		Status:     EscrowStatusReleased,
+		ChangedBy:  systemUser.ID,
+		OnlyIfDue:  true,
+	})
+	require.ErrorIs(t, err, ErrEscrowNotDue)
+
+	due := createTestEscrow(t, store, buyerAccount, sellerAccount, time.Now().Add(-time.Minute))
+
+	ids, err := testQueries.ListDueEscrows(context.Background(), ListDueEscrowsParams{
+		ReleaseAt: time.Now(),
+		AfterID:   due.ID - 1,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Contains(t, ids, due.ID)
+	require.NotContains(t, ids, notDue.ID)
+
+	// the buyer can no longer dispute once the release time has passed
+	_, err = store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+		EscrowID:      due.ID,
+		FromStatus:    EscrowStatusHeld,
+		Status:        EscrowStatusDisputed,
+		ChangedBy:     buyerAccount.Owner,
+		DisputeReason: "Item never arrived",
+	})
+	require.ErrorIs(t, err, ErrEscrowDisputeClosed)
+
+	result, err := store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+		EscrowID:   due.ID,
+		FromStatus: EscrowStatusHeld,
+		Status:     EscrowStatusReleased,
+		ChangedBy:  systemUser.ID,
+		OnlyIfDue:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, EscrowStatusReleased, result.Escrow.Status)
+	require.Equal(t, int64(40), result.Settlement.ToAccount.Balance)
+}
+
+func TestEscrowDisputeResolved(t *testing.T) {
+	store := NewStore(testDB)
+	buyerAccount := createRandomAccountWithCurrency(t, util.USD, 100)
+	sellerAccount := createRandomAccountWithCurrency(t, util.USD, 0)
+	systemUser, err := testQueries.GetUserByUsername(context.Background(), SystemUsername)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		status  string
+		payeeID int64
+	}{
+		{
+			name:    "ForBuyer",
+			status:  EscrowStatusRefunded,
+			payeeID: buyerAccount.ID,
+		},
+		{
+			name:    "ForSeller",
+			status:  EscrowStatusReleased,
+			payeeID: sellerAccount.ID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			escrow := createTestEscrow(t, store, buyerAccount, sellerAccount, time.Now().Add(time.Hour))
+
+			disputed, err := store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+				EscrowID:      escrow.ID,
+				FromStatus:    EscrowStatusHeld,
+				Status:        EscrowStatusDisputed,
+				ChangedBy:     buyerAccount.Owner,
+				DisputeReason: "Item never arrived",
+			})
+			require.NoError(t, err)
+			require.Equal(t, EscrowStatusDisputed, disputed.Escrow.Status)
+			require.Equal(t, "Item never arrived", disputed.Escrow.DisputeReason)
+			require.Nil(t, disputed.Settlement)
+
+			// a disputed escrow is no longer released automatically
+			_, err = store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+				EscrowID:   escrow.ID,
+				FromStatus: EscrowStatusHeld,
+				Status:     EscrowStatusReleased,
+				ChangedBy:  systemUser.ID,
+				OnlyIfDue:  true,
+			})
+			require.ErrorIs(t, err, ErrEscrowStatusChanged)
+
+			payee, err := testQueries.GetAccount(context.Background(), tc.payeeID)
+			require.NoError(t, err)
+
+			result, err := store.ChangeEscrowStatusTx(context.Background(), ChangeEscrowStatusTxParams{
+				EscrowID:   escrow.ID,
+				FromStatus: EscrowStatusDisputed,
+				Status:     tc.status,
+				ChangedBy:  systemUser.ID,
+			})
+			require.NoError(t, err)
+			require.Equal(t, tc.status, result.Escrow.Status)
+			require.Equal(t, "Item never arrived", result.Escrow.DisputeReason)
+			require.Equal(t, tc.payeeID, result.Settlement.ToAccount.ID)
+			require.Equal(t, payee.Balance+40, result.Settlement.ToAccount.Balance)
+
+			settlement, err := testQueries.GetEscrowSettlement(context.Background(), escrow.ID)
+			require.NoError(t, err)
+			require.Equal(t, tc.status, settlement.Outcome)
+			require.Equal(t, systemUser.ID, settlement.SettledBy)
+		})
+	}
+}
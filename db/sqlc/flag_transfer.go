@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type FlagTransferTxParams struct {
+	ToAccountID   int64 `json:"to_account_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	Amount        int64 `json:"amount"`
+	// Status is either pending, for transfers waiting on a review, or blocked
+	Status        string   `json:"status"`
+	EnforceLimits bool     `json:"enforce_limits"`
+	RiskDecision  string   `json:"risk_decision"`
+	MatchedRules  []string `json:"matched_rules"`
+	// Payee records the transfer against its recipient for the cooling-off limit
+	Payee *PayeeTransferParams `json:"payee,omitempty"`
+	// Approval is set when a pending transfer is over the approval threshold of the from account
+	Approval *PendingApprovalParams `json:"approval,omitempty"`
+}
+
+// PendingApprovalParams holds a pending transfer for the approvers of the from account once it
+// passes review. The approval window starts when the review approves the transfer.
+type PendingApprovalParams struct {
+	InitiatedBy       int64         `json:"initiated_by"`
+	RequiredApprovals int32         `json:"required_approvals"`
+	ApprovalWindow    time.Duration `json:"approval_window"`
+}
+
+type FlagTransferTxResult struct {
+	Transfer       Transfer               `json:"transfer"`
+	RiskAssessment TransferRiskAssessment `json:"risk_assessment"`
+}
+
+// FlagTransferTx records a transfer that was stopped by the risk engine. No money is moved,
+// but the amount of a pending transfer is reserved on the from account until it is reviewed.
+func (store *SQLStore) FlagTransferTx(ctx context.Context, arg FlagTransferTxParams) (FlagTransferTxResult, error) {
+	var result FlagTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		if arg.EnforceLimits {
+			err = checkTransferLimits(ctx, q, arg.FromAccountID, arg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Status:        arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordPayeeTransfer(ctx, q, arg.Payee, result.Transfer)
+		if err != nil {
+			return err
+		}
+
+		if arg.Status == TransferStatusPending {
+			err = checkTransferAccountsActive(ctx, q, arg.FromAccountID, arg.ToAccountID)
+			if err != nil {
+				return err
+			}
+
+			err = reserveBalance(ctx, q, arg.FromAccountID, arg.Amount)
+			if err != nil {
+				return err
+			}
+
+			if arg.Approval != nil {
+				_, err = q.CreateTransferApprovalRequest(ctx, CreateTransferApprovalRequestParams{
+					TransferID:        result.Transfer.ID,
+					InitiatedBy:       arg.Approval.InitiatedBy,
+					RequiredApprovals: arg.Approval.RequiredApprovals,
+					ExpiresAt:         time.Now().Add(arg.Approval.ApprovalWindow),
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		result.RiskAssessment, err = q.CreateTransferRiskAssessment(ctx, CreateTransferRiskAssessmentParams{
+			TransferID:   result.Transfer.ID,
+			Decision:     arg.RiskDecision,
+			MatchedRules: arg.MatchedRules,
+		})
+		return err
+	})
+
+	return result, err
+}
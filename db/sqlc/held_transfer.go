@@ -0,0 +1,67 @@
+package db
+
+import "context"
+
+// Held transfers are created with a status other than completed and keep their amount reserved on
+// the from account until they are either completed or released.
+
+func reserveBalance(ctx context.Context, q *Queries, accountID int64, amount int64) error {
+	account, err := q.AddAccountReservedBalance(ctx, AddAccountReservedBalanceParams{
+		ID:     accountID,
+		Amount: amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	return checkAvailableBalance(account)
+}
+
+// completeHeldTransfer posts a held transfer and releases its reservation. The transfer row must
+// already be locked by the caller.
+func completeHeldTransfer(ctx context.Context, q *Queries, transferID int64, result *TransferTxResult) error {
+	var err error
+
+	result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		ID:     transferID,
+		Status: TransferStatusCompleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	// post first so that both accounts are locked in ID order, the from account is already
+	// locked when the reservation is released
+	err = postTransfer(ctx, q, result.Transfer, result)
+	if err != nil {
+		return err
+	}
+
+	err = checkAccountsActive(result.FromAccount, result.ToAccount)
+	if err != nil {
+		return err
+	}
+
+	result.FromAccount, err = q.AddAccountReservedBalance(ctx, AddAccountReservedBalanceParams{
+		ID:     result.Transfer.FromAccountID,
+		Amount: -result.Transfer.Amount,
+	})
+	return err
+}
+
+// releaseHeldTransfer moves a held transfer to its final status without moving any money
+func releaseHeldTransfer(ctx context.Context, q *Queries, transferID int64, status string) (Transfer, Account, error) {
+	transfer, err := q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+		ID:     transferID,
+		Status: status,
+	})
+	if err != nil {
+		return Transfer{}, Account{}, err
+	}
+
+	account, err := q.AddAccountReservedBalance(ctx, AddAccountReservedBalanceParams{
+		ID:     transfer.FromAccountID,
+		Amount: -transfer.Amount,
+	})
+	return transfer, account, err
+}
@@ -0,0 +1,453 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: interest.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createInterestAccrual = `-- name: CreateInterestAccrual :execrows
+INSERT INTO INTEREST_ACCRUALS (
+  ACCOUNT_ID,
+  ACCRUAL_DATE,
+  BALANCE,
+  ANNUAL_RATE_BPS,
+  AMOUNT_MICROS
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (ACCOUNT_ID, ACCRUAL_DATE) DO NOTHING
+`
+
+type CreateInterestAccrualParams struct {
+	AccountID     int64     `json:"account_id"`
+	AccrualDate   time.Time `json:"accrual_date"`
+	Balance       int64     `json:"balance"`
+	AnnualRateBps int32     `json:"annual_rate_bps"`
+	AmountMicros  int64     `json:"amount_micros"`
+}
+
+func (q *Queries) CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createInterestAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.AnnualRateBps,
+		arg.AmountMicros,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createInterestPosting = `-- name: CreateInterestPosting :one
+INSERT INTO INTEREST_POSTINGS (
+  ACCOUNT_ID,
+  PERIOD_START,
+  ACCRUED_MICROS,
+  AMOUNT,
+  CARRY_MICROS
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING account_id, period_start, accrued_micros, amount, carry_micros, created_at
+`
+
+type CreateInterestPostingParams struct {
+	AccountID     int64     `json:"account_id"`
+	PeriodStart   time.Time `json:"period_start"`
+	AccruedMicros int64     `json:"accrued_micros"`
+	Amount        int64     `json:"amount"`
+	CarryMicros   int64     `json:"carry_micros"`
+}
+
+func (q *Queries) CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, createInterestPosting,
+		arg.AccountID,
+		arg.PeriodStart,
+		arg.AccruedMicros,
+		arg.Amount,
+		arg.CarryMicros,
+	)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.PeriodStart,
+		&i.AccruedMicros,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getInterestPosting = `-- name: GetInterestPosting :one
+SELECT account_id, period_start, accrued_micros, amount, carry_micros, created_at FROM INTEREST_POSTINGS
+WHERE ACCOUNT_ID = $1 AND PERIOD_START = $2
+LIMIT 1
+`
+
+type GetInterestPostingParams struct {
+	AccountID   int64     `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+}
+
+func (q *Queries) GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, getInterestPosting, arg.AccountID, arg.PeriodStart)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.PeriodStart,
+		&i.AccruedMicros,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLastInterestPosting = `-- name: GetLastInterestPosting :one
+SELECT account_id, period_start, accrued_micros, amount, carry_micros, created_at FROM INTEREST_POSTINGS
+WHERE ACCOUNT_ID = $1 AND PERIOD_START < $2
+ORDER BY PERIOD_START DESC
+LIMIT 1
+`
+
+type GetLastInterestPostingParams struct {
+	AccountID   int64     `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+}
+
+func (q *Queries) GetLastInterestPosting(ctx context.Context, arg GetLastInterestPostingParams) (InterestPosting, error) {
+	row := q.db.QueryRowContext(ctx, getLastInterestPosting, arg.AccountID, arg.PeriodStart)
+	var i InterestPosting
+	err := row.Scan(
+		&i.AccountID,
+		&i.PeriodStart,
+		&i.AccruedMicros,
+		&i.Amount,
+		&i.CarryMicros,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getSystemAccount = `-- name: GetSystemAccount :one
+SELECT a.id, a.owner, a.balance, a.currency, a.created_at, a.reserved_balance, a.type, a.nickname, a.status, a.overdraft_limit, a.overdraft_rate_bps, a.pocket_balance FROM ACCOUNTS A
+JOIN SYSTEM_ACCOUNTS S ON S.ACCOUNT_ID = A.ID
+WHERE S.PURPOSE = $1 AND S.CURRENCY = $2
+LIMIT 1
+`
+
+type GetSystemAccountParams struct {
+	Purpose  string `json:"purpose"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getSystemAccount, arg.Purpose, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.ReservedBalance,
+		&i.Type,
+		&i.Nickname,
+		&i.Status,
+		&i.OverdraftLimit,
+		&i.OverdraftRateBps,
+		&i.PocketBalance,
+	)
+	return i, err
+}
+
+const listInterestAccruals = `-- name: ListInterestAccruals :many
+SELECT account_id, accrual_date, balance, annual_rate_bps, amount_micros, created_at FROM INTEREST_ACCRUALS
+WHERE ACCOUNT_ID = $1
+AND ACCRUAL_DATE >= $2
+AND ACCRUAL_DATE < $3
+ORDER BY ACCRUAL_DATE
+`
+
+type ListInterestAccrualsParams struct {
+	AccountID int64     `json:"account_id"`
+	FromDate  time.Time `json:"from_date"`
+	ToDate    time.Time `json:"to_date"`
+}
+
+func (q *Queries) ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestAccruals, arg.AccountID, arg.FromDate, arg.ToDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestAccrual{}
+	for rows.Next() {
+		var i InterestAccrual
+		if err := rows.Scan(
+			&i.AccountID,
+			&i.AccrualDate,
+			&i.Balance,
+			&i.AnnualRateBps,
+			&i.AmountMicros,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestBearingAccounts = `-- name: ListInterestBearingAccounts :many
+SELECT
+  A.ID,
+  R.ANNUAL_RATE_BPS,
+  (A.BALANCE - COALESCE((
+    SELECT SUM(E.AMOUNT) FROM ENTRIES E
+    WHERE E.ACCOUNT_ID = A.ID AND E.CREATED_AT >= $1
+  ), 0))::bigint AS END_OF_DAY_BALANCE
+FROM ACCOUNTS A
+JOIN INTEREST_RATES R ON R.CURRENCY = A.CURRENCY AND R.ACCOUNT_TYPE = A.TYPE
+WHERE A.ID > $2
+AND A.CREATED_AT < $1
+AND COALESCE(
+  (SELECT C.TO_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID AND C.CREATED_AT < $1
+   ORDER BY C.CREATED_AT DESC, C.ID DESC LIMIT 1),
+  (SELECT C.FROM_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID
+   ORDER BY C.CREATED_AT, C.ID LIMIT 1),
+  A.STATUS
+) <> 'closed'
+AND R.ANNUAL_RATE_BPS > 0
+ORDER BY A.ID
+LIMIT $3
+`
+
+type ListInterestBearingAccountsParams struct {
+	EndOfDay time.Time `json:"end_of_day"`
+	AfterID  int64     `json:"after_id"`
+	Limit    int32     `json:"limit_"`
+}
+
+type ListInterestBearingAccountsRow struct {
+	ID              int64 `json:"id"`
+	AnnualRateBps   int32 `json:"annual_rate_bps"`
+	EndOfDayBalance int64 `json:"end_of_day_balance"`
+}
+
+// An account accrues for a day when it was not closed at the end of that day, so a run for a past
+// date still credits an account that has been closed since. Its status then is the last status it
+// changed to before the end of the day, or the status it had before its first change.
+func (q *Queries) ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestBearingAccounts, arg.EndOfDay, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListInterestBearingAccountsRow{}
+	for rows.Next() {
+		var i ListInterestBearingAccountsRow
+		if err := rows.Scan(&i.ID, &i.AnnualRateBps, &i.EndOfDayBalance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listInterestRates = `-- name: ListInterestRates :many
+SELECT currency, account_type, annual_rate_bps, updated_at FROM INTEREST_RATES
+ORDER BY CURRENCY, ACCOUNT_TYPE
+`
+
+func (q *Queries) ListInterestRates(ctx context.Context) ([]InterestRate, error) {
+	rows, err := q.db.QueryContext(ctx, listInterestRates)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []InterestRate{}
+	for rows.Next() {
+		var i InterestRate
+		if err := rows.Scan(
+			&i.Currency,
+			&i.AccountType,
+			&i.AnnualRateBps,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnpostedInterestAccounts = `-- name: ListUnpostedInterestAccounts :many
+SELECT DISTINCT I.ACCOUNT_ID FROM INTEREST_ACCRUALS I
+JOIN ACCOUNTS A ON A.ID = I.ACCOUNT_ID
+WHERE I.ACCRUAL_DATE >= $1
+AND I.ACCRUAL_DATE < $2
+AND I.ACCOUNT_ID > $3
+AND COALESCE(
+  (SELECT C.TO_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID AND C.CREATED_AT < (I.ACCRUAL_DATE + 1)::timestamp AT TIME ZONE 'UTC'
+   ORDER BY C.CREATED_AT DESC, C.ID DESC LIMIT 1),
+  (SELECT C.FROM_STATUS FROM ACCOUNT_STATUS_CHANGES C
+   WHERE C.ACCOUNT_ID = A.ID
+   ORDER BY C.CREATED_AT, C.ID LIMIT 1),
+  A.STATUS
+) <> 'closed'
+AND NOT EXISTS (
+  SELECT 1 FROM INTEREST_POSTINGS P
+  WHERE P.ACCOUNT_ID = I.ACCOUNT_ID AND P.PERIOD_START = $1
+)
+ORDER BY I.ACCOUNT_ID
+LIMIT $4
+`
+
+type ListUnpostedInterestAccountsParams struct {
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	AfterID     int64     `json:"after_id"`
+	Limit       int32     `json:"limit_"`
+}
+
+// Like ListInterestBearingAccounts, accruals count when the account was not closed at the end of
+// their day (UTC)
+func (q *Queries) ListUnpostedInterestAccounts(ctx context.Context, arg ListUnpostedInterestAccountsParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listUnpostedInterestAccounts,
+		arg.PeriodStart,
+		arg.PeriodEnd,
+		arg.AfterID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var account_id int64
+		if err := rows.Scan(&account_id); err != nil {
+			return nil, err
+		}
+		items = append(items, account_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUnpostedInterestPeriods = `-- name: ListUnpostedInterestPeriods :many
+SELECT DISTINCT date_trunc('month', I.ACCRUAL_DATE)::date AS PERIOD_START FROM INTEREST_ACCRUALS I
+WHERE I.ACCOUNT_ID = $1
+AND NOT EXISTS (
+  SELECT 1 FROM INTEREST_POSTINGS P
+  WHERE P.ACCOUNT_ID = I.ACCOUNT_ID AND P.PERIOD_START = date_trunc('month', I.ACCRUAL_DATE)::date
+)
+ORDER BY PERIOD_START
+`
+
+func (q *Queries) ListUnpostedInterestPeriods(ctx context.Context, accountID int64) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, listUnpostedInterestPeriods, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []time.Time{}
+	for rows.Next() {
+		var period_start time.Time
+		if err := rows.Scan(&period_start); err != nil {
+			return nil, err
+		}
+		items = append(items, period_start)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumInterestAccruals = `-- name: SumInterestAccruals :one
+SELECT COALESCE(SUM(AMOUNT_MICROS), 0)::bigint FROM INTEREST_ACCRUALS
+WHERE ACCOUNT_ID = $1
+AND ACCRUAL_DATE >= $2
+AND ACCRUAL_DATE < $3
+`
+
+type SumInterestAccrualsParams struct {
+	AccountID int64     `json:"account_id"`
+	FromDate  time.Time `json:"from_date"`
+	ToDate    time.Time `json:"to_date"`
+}
+
+func (q *Queries) SumInterestAccruals(ctx context.Context, arg SumInterestAccrualsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumInterestAccruals, arg.AccountID, arg.FromDate, arg.ToDate)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const upsertInterestRate = `-- name: UpsertInterestRate :one
+INSERT INTO INTEREST_RATES (
+  CURRENCY,
+  ACCOUNT_TYPE,
+  ANNUAL_RATE_BPS
+) VALUES (
+  $1, $2, $3
+) ON CONFLICT (CURRENCY, ACCOUNT_TYPE) DO UPDATE
+SET ANNUAL_RATE_BPS = EXCLUDED.ANNUAL_RATE_BPS,
+    UPDATED_AT = now()
+RETURNING currency, account_type, annual_rate_bps, updated_at
+`
+
+type UpsertInterestRateParams struct {
+	Currency      string `json:"currency"`
+	AccountType   string `json:"account_type"`
+	AnnualRateBps int32  `json:"annual_rate_bps"`
+}
+
+func (q *Queries) UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error) {
+	row := q.db.QueryRowContext(ctx, upsertInterestRate, arg.Currency, arg.AccountType, arg.AnnualRateBps)
+	var i InterestRate
+	err := row.Scan(
+		&i.Currency,
+		&i.AccountType,
+		&i.AnnualRateBps,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
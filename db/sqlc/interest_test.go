@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/interest"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSavingsAccount(t *testing.T, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := NewStore(testDB).CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.ID,
+			Balance:  balance,
+			Currency: util.USD,
+			Type:     util.Savings,
+		},
+		MaxAccounts: 1,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
+func TestCreateInterestAccrualIsIdempotent(t *testing.T) {
+	account := createRandomSavingsAccount(t, 1000)
+	date := interest.Date(time.Now()).AddDate(0, 0, -1)
+
+	arg := CreateInterestAccrualParams{
+		AccountID:     account.ID,
+		AccrualDate:   date,
+		Balance:       account.Balance,
+		AnnualRateBps: 200,
+		AmountMicros:  interest.DailyAccrualMicros(account.Balance, 200, date),
+	}
+
+	created, err := testQueries.CreateInterestAccrual(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), created)
+
+	created, err = testQueries.CreateInterestAccrual(context.Background(), arg)
+	require.NoError(t, err)
+	require.Zero(t, created)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), ListInterestAccrualsParams{
+		AccountID: account.ID,
+		FromDate:  date,
+		ToDate:    date.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 1)
+}
+
+func TestCapitalizeInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomSavingsAccount(t, 0)
+	periodStart := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, micros := range []int64{1_400_000, 900_000} {
+		_, err := testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+			AccountID:     account.ID,
+			AccrualDate:   periodStart.AddDate(0, 0, i),
+			Balance:       1,
+			AnnualRateBps: 200,
+			AmountMicros:  micros,
+		})
+		require.NoError(t, err)
+	}
+
+	expense, err := testQueries.GetSystemAccount(context.Background(), GetSystemAccountParams{
+		Purpose:  SystemAccountInterestExpense,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := store.CapitalizeInterestTx(context.Background(), CapitalizeInterestTxParams{
+		AccountID:   account.ID,
+		PeriodStart: periodStart,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2_300_000), result.Posting.AccruedMicros)
+	require.Equal(t, int64(2), result.Posting.Amount)
+	require.Equal(t, int64(300_000), result.Posting.CarryMicros)
+
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, expense.ID, result.Transfer.Transfer.FromAccountID)
+	require.Equal(t, int64(2), result.Transfer.ToAccount.Balance)
+	require.Equal(t, expense.Balance-2, result.Transfer.FromAccount.Balance)
+
+	// running it again does not post twice
+	_, err = store.CapitalizeInterestTx(context.Background(), CapitalizeInterestTxParams{
+		AccountID:   account.ID,
+		PeriodStart: periodStart,
+	})
+	require.ErrorIs(t, err, ErrInterestAlreadyPosted)
+
+	// the carry is added to the next month
+	_, err = testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:     account.ID,
+		AccrualDate:   periodStart.AddDate(0, 1, 0),
+		Balance:       1,
+		AnnualRateBps: 200,
+		AmountMicros:  600_000,
+	})
+	require.NoError(t, err)
+
+	result, err = store.CapitalizeInterestTx(context.Background(), CapitalizeInterestTxParams{
+		AccountID:   account.ID,
+		PeriodStart: periodStart.AddDate(0, 1, 0),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(900_000), result.Posting.AccruedMicros)
+	require.Zero(t, result.Posting.Amount)
+	require.Nil(t, result.Transfer)
+}
+
+func TestListInterestBearingAccounts(t *testing.T) {
+	savings := createRandomSavingsAccount(t, 1000)
+	checking := createRandomAccountWithCurrency(t, util.USD, 1000)
+	closed := createRandomSavingsAccount(t, 0)
+	endOfDay := time.Now()
+
+	_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:     closed.ID,
+		Status: AccountStatusClosed,
+	})
+	require.NoError(t, err)
+
+	// money received after the end of the day does not count for that day
+	_, err = NewStore(testDB).TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: checking.ID,
+		ToAccountID:   savings.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+
+	rows, err := testQueries.ListInterestBearingAccounts(context.Background(), ListInterestBearingAccountsParams{
+		EndOfDay: endOfDay,
+		AfterID:  savings.ID - 1,
+		Limit:    100,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+	require.Equal(t, savings.ID, rows[0].ID)
+	require.Equal(t, int64(1000), rows[0].EndOfDayBalance)
+
+	// checking accounts have no rate and closed accounts no longer accrue
+	for _, row := range rows {
+		require.NotEqual(t, checking.ID, row.ID)
+		require.NotEqual(t, closed.ID, row.ID)
+	}
+}
+
+func TestInterestOfAccountClosedSince(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomSavingsAccount(t, 0)
+	yesterday := interest.Date(time.Now()).AddDate(0, 0, -1)
+	beforeClosing := time.Now()
+
+	_, err := store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    AccountStatusClosed,
+		ChangedBy: account.Owner,
+		ActorRole: AccountStatusChangeByOwner,
+	})
+	require.NoError(t, err)
+
+	listed := func(endOfDay time.Time) bool {
+		rows, err := testQueries.ListInterestBearingAccounts(context.Background(), ListInterestBearingAccountsParams{
+			EndOfDay: endOfDay,
+			AfterID:  account.ID - 1,
+			Limit:    1,
+		})
+		require.NoError(t, err)
+		return len(rows) == 1 && rows[0].ID == account.ID
+	}
+
+	// a run for a day before the account was closed still accrues for it
+	require.True(t, listed(beforeClosing))
+	require.False(t, listed(time.Now()))
+
+	_, err = testQueries.CreateInterestAccrual(context.Background(), CreateInterestAccrualParams{
+		AccountID:     account.ID,
+		AccrualDate:   yesterday,
+		Balance:       1,
+		AnnualRateBps: 200,
+		AmountMicros:  1_000_000,
+	})
+	require.NoError(t, err)
+
+	ids, err := testQueries.ListUnpostedInterestAccounts(context.Background(), ListUnpostedInterestAccountsParams{
+		PeriodStart: interest.MonthStart(yesterday),
+		PeriodEnd:   interest.MonthStart(yesterday).AddDate(0, 1, 0),
+		AfterID:     account.ID - 1,
+		Limit:       1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{account.ID}, ids)
+}
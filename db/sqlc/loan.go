@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrInstallmentAlreadyPaid = errors.New("loan installment has already been paid")
+
+type LoanInstallmentParams struct {
+	DueDate   time.Time `json:"due_date"`
+	Principal int64     `json:"principal"`
+	Interest  int64     `json:"interest"`
+}
+
+type OriginateLoanTxParams struct {
+	AccountID     int64  `json:"account_id"`
+	Principal     int64  `json:"principal"`
+	AnnualRateBps int32  `json:"annual_rate_bps"`
+	Method        string `json:"method"`
+	CreatedBy     int64  `json:"created_by"`
+	// Installments is the amortization schedule, one entry per month of the term
+	Installments []LoanInstallmentParams `json:"installments"`
+}
+
+type OriginateLoanTxResult struct {
+	Loan         Loan              `json:"loan"`
+	Installments []LoanInstallment `json:"installments"`
+	Disbursement TransferTxResult  `json:"disbursement"`
+}
+
+// OriginateLoanTx creates a loan with its schedule and disburses the principal into the account
+// from the loan funding account of its currency. The borrower is the account owner.
+func (store *SQLStore) OriginateLoanTx(ctx context.Context, arg OriginateLoanTxParams) (OriginateLoanTxResult, error) {
+	var result OriginateLoanTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		err = checkAccountsActive(account)
+		if err != nil {
+			return err
+		}
+
+		result.Loan, err = q.CreateLoan(ctx, CreateLoanParams{
+			AccountID:     account.ID,
+			BorrowerID:    account.Owner,
+			Currency:      account.Currency,
+			Principal:     arg.Principal,
+			AnnualRateBps: arg.AnnualRateBps,
+			TermMonths:    int32(len(arg.Installments)),
+			Method:        arg.Method,
+			CreatedBy:     arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Installments = make([]LoanInstallment, len(arg.Installments))
+		for i, installment := range arg.Installments {
+			result.Installments[i], err = q.CreateLoanInstallment(ctx, CreateLoanInstallmentParams{
+				LoanID:    result.Loan.ID,
+				Seq:       int32(i + 1),
+				DueDate:   installment.DueDate,
+				Principal: installment.Principal,
+				Interest:  installment.Interest,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		funding, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Purpose:  SystemAccountLoanFunding,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		return postSystemTransfer(ctx, q, funding.ID, account.ID, arg.Principal, &result.Disbursement)
+	})
+
+	return result, err
+}
+
+type CollectLoanInstallmentTxResult struct {
+	Installment LoanInstallment   `json:"installment"`
+	Loan        Loan              `json:"loan"`
+	Repayment   TransferTxResult  `json:"repayment"`
+	LateFee     *TransferTxResult `json:"late_fee,omitempty"`
+}
+
+// CollectLoanInstallmentTx debits an installment and its late fee from the loan account. The
+// principal and interest go to the loan funding account, the late fee to the fee income account.
+// Nothing is collected when the account cannot cover the whole amount.
+func (store *SQLStore) CollectLoanInstallmentTx(ctx context.Context, installmentID int64) (CollectLoanInstallmentTxResult, error) {
+	var result CollectLoanInstallmentTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		installment, err := q.GetLoanInstallmentForUpdate(ctx, installmentID)
+		if err != nil {
+			return err
+		}
+
+		if installment.Status == InstallmentStatusPaid {
+			return ErrInstallmentAlreadyPaid
+		}
+
+		loan, err := q.GetLoan(ctx, installment.LoanID)
+		if err != nil {
+			return err
+		}
+
+		funding, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+			Purpose:  SystemAccountLoanFunding,
+			Currency: loan.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = postSystemTransfer(ctx, q, loan.AccountID, funding.ID, installment.Principal+installment.Interest, &result.Repayment)
+		if err != nil {
+			return err
+		}
+
+		err = checkAccountsActive(result.Repayment.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		err = checkAvailableBalance(result.Repayment.FromAccount)
+		if err != nil {
+			return err
+		}
+
+		if installment.LateFee > 0 {
+			feeIncome, err := q.GetSystemAccount(ctx, GetSystemAccountParams{
+				Purpose:  SystemAccountFeeIncome,
+				Currency: loan.Currency,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.LateFee = &TransferTxResult{}
+			err = postSystemTransfer(ctx, q, loan.AccountID, feeIncome.ID, installment.LateFee, result.LateFee)
+			if err != nil {
+				return err
+			}
+
+			err = checkAvailableBalance(result.LateFee.FromAccount)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Installment, err = q.MarkLoanInstallmentPaid(ctx, installment.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Loan, err = q.RepayLoanPrincipal(ctx, RepayLoanPrincipalParams{
+			ID:     loan.ID,
+			Amount: installment.Principal,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// postSystemTransfer moves money between a customer account and a system account. System accounts
+// keep the bank's side of the ledger and may go negative, so no balance rule is applied here.
+func postSystemTransfer(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64, amount int64, result *TransferTxResult) error {
+	transfer, err := q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		Status:        TransferStatusCompleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Transfer = transfer
+	return postTransfer(ctx, q, transfer, result)
+}
@@ -0,0 +1,442 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: loan.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createLoan = `-- name: CreateLoan :one
+INSERT INTO LOANS (
+  ACCOUNT_ID,
+  BORROWER_ID,
+  CURRENCY,
+  PRINCIPAL,
+  OUTSTANDING_PRINCIPAL,
+  ANNUAL_RATE_BPS,
+  TERM_MONTHS,
+  METHOD,
+  CREATED_BY
+) VALUES (
+  $1, $2, $3, $4, $4, $5, $6, $7, $8
+) RETURNING id, account_id, borrower_id, currency, principal, outstanding_principal, annual_rate_bps, term_months, method, status, created_by, created_at
+`
+
+type CreateLoanParams struct {
+	AccountID     int64  `json:"account_id"`
+	BorrowerID    int64  `json:"borrower_id"`
+	Currency      string `json:"currency"`
+	Principal     int64  `json:"principal"`
+	AnnualRateBps int32  `json:"annual_rate_bps"`
+	TermMonths    int32  `json:"term_months"`
+	Method        string `json:"method"`
+	CreatedBy     int64  `json:"created_by"`
+}
+
+func (q *Queries) CreateLoan(ctx context.Context, arg CreateLoanParams) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, createLoan,
+		arg.AccountID,
+		arg.BorrowerID,
+		arg.Currency,
+		arg.Principal,
+		arg.AnnualRateBps,
+		arg.TermMonths,
+		arg.Method,
+		arg.CreatedBy,
+	)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.BorrowerID,
+		&i.Currency,
+		&i.Principal,
+		&i.OutstandingPrincipal,
+		&i.AnnualRateBps,
+		&i.TermMonths,
+		&i.Method,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createLoanInstallment = `-- name: CreateLoanInstallment :one
+INSERT INTO LOAN_INSTALLMENTS (
+  LOAN_ID,
+  SEQ,
+  DUE_DATE,
+  PRINCIPAL,
+  INTEREST
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at
+`
+
+type CreateLoanInstallmentParams struct {
+	LoanID    int64     `json:"loan_id"`
+	Seq       int32     `json:"seq"`
+	DueDate   time.Time `json:"due_date"`
+	Principal int64     `json:"principal"`
+	Interest  int64     `json:"interest"`
+}
+
+func (q *Queries) CreateLoanInstallment(ctx context.Context, arg CreateLoanInstallmentParams) (LoanInstallment, error) {
+	row := q.db.QueryRowContext(ctx, createLoanInstallment,
+		arg.LoanID,
+		arg.Seq,
+		arg.DueDate,
+		arg.Principal,
+		arg.Interest,
+	)
+	var i LoanInstallment
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.Seq,
+		&i.DueDate,
+		&i.Principal,
+		&i.Interest,
+		&i.LateFee,
+		&i.Status,
+		&i.PaidAt,
+	)
+	return i, err
+}
+
+const getLoan = `-- name: GetLoan :one
+SELECT id, account_id, borrower_id, currency, principal, outstanding_principal, annual_rate_bps, term_months, method, status, created_by, created_at FROM LOANS
+WHERE ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetLoan(ctx context.Context, id int64) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, getLoan, id)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.BorrowerID,
+		&i.Currency,
+		&i.Principal,
+		&i.OutstandingPrincipal,
+		&i.AnnualRateBps,
+		&i.TermMonths,
+		&i.Method,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLoanForUpdate = `-- name: GetLoanForUpdate :one
+SELECT id, account_id, borrower_id, currency, principal, outstanding_principal, annual_rate_bps, term_months, method, status, created_by, created_at FROM LOANS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetLoanForUpdate(ctx context.Context, id int64) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, getLoanForUpdate, id)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.BorrowerID,
+		&i.Currency,
+		&i.Principal,
+		&i.OutstandingPrincipal,
+		&i.AnnualRateBps,
+		&i.TermMonths,
+		&i.Method,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getLoanInstallmentForUpdate = `-- name: GetLoanInstallmentForUpdate :one
+SELECT id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at FROM LOAN_INSTALLMENTS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetLoanInstallmentForUpdate(ctx context.Context, id int64) (LoanInstallment, error) {
+	row := q.db.QueryRowContext(ctx, getLoanInstallmentForUpdate, id)
+	var i LoanInstallment
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.Seq,
+		&i.DueDate,
+		&i.Principal,
+		&i.Interest,
+		&i.LateFee,
+		&i.Status,
+		&i.PaidAt,
+	)
+	return i, err
+}
+
+const getNextLoanInstallment = `-- name: GetNextLoanInstallment :one
+SELECT id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at FROM LOAN_INSTALLMENTS
+WHERE LOAN_ID = $1 AND STATUS <> 'paid'
+ORDER BY SEQ
+LIMIT 1
+`
+
+func (q *Queries) GetNextLoanInstallment(ctx context.Context, loanID int64) (LoanInstallment, error) {
+	row := q.db.QueryRowContext(ctx, getNextLoanInstallment, loanID)
+	var i LoanInstallment
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.Seq,
+		&i.DueDate,
+		&i.Principal,
+		&i.Interest,
+		&i.LateFee,
+		&i.Status,
+		&i.PaidAt,
+	)
+	return i, err
+}
+
+const listAccountLoans = `-- name: ListAccountLoans :many
+SELECT id, account_id, borrower_id, currency, principal, outstanding_principal, annual_rate_bps, term_months, method, status, created_by, created_at FROM LOANS
+WHERE ACCOUNT_ID = $1
+ORDER BY ID
+`
+
+func (q *Queries) ListAccountLoans(ctx context.Context, accountID int64) ([]Loan, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountLoans, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Loan{}
+	for rows.Next() {
+		var i Loan
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.BorrowerID,
+			&i.Currency,
+			&i.Principal,
+			&i.OutstandingPrincipal,
+			&i.AnnualRateBps,
+			&i.TermMonths,
+			&i.Method,
+			&i.Status,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDueLoanInstallments = `-- name: ListDueLoanInstallments :many
+SELECT id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at FROM LOAN_INSTALLMENTS
+WHERE STATUS <> 'paid'
+AND DUE_DATE <= $1
+AND ID > $2
+ORDER BY ID
+LIMIT $3
+`
+
+type ListDueLoanInstallmentsParams struct {
+	DueDate time.Time `json:"due_date"`
+	AfterID int64     `json:"after_id"`
+	Limit   int32     `json:"limit_"`
+}
+
+func (q *Queries) ListDueLoanInstallments(ctx context.Context, arg ListDueLoanInstallmentsParams) ([]LoanInstallment, error) {
+	rows, err := q.db.QueryContext(ctx, listDueLoanInstallments, arg.DueDate, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoanInstallment{}
+	for rows.Next() {
+		var i LoanInstallment
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Seq,
+			&i.DueDate,
+			&i.Principal,
+			&i.Interest,
+			&i.LateFee,
+			&i.Status,
+			&i.PaidAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoanInstallments = `-- name: ListLoanInstallments :many
+SELECT id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at FROM LOAN_INSTALLMENTS
+WHERE LOAN_ID = $1
+ORDER BY SEQ
+`
+
+func (q *Queries) ListLoanInstallments(ctx context.Context, loanID int64) ([]LoanInstallment, error) {
+	rows, err := q.db.QueryContext(ctx, listLoanInstallments, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoanInstallment{}
+	for rows.Next() {
+		var i LoanInstallment
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Seq,
+			&i.DueDate,
+			&i.Principal,
+			&i.Interest,
+			&i.LateFee,
+			&i.Status,
+			&i.PaidAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markLoanInstallmentPaid = `-- name: MarkLoanInstallmentPaid :one
+UPDATE LOAN_INSTALLMENTS
+SET STATUS = 'paid',
+    PAID_AT = now()
+WHERE ID = $1
+RETURNING id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at
+`
+
+func (q *Queries) MarkLoanInstallmentPaid(ctx context.Context, id int64) (LoanInstallment, error) {
+	row := q.db.QueryRowContext(ctx, markLoanInstallmentPaid, id)
+	var i LoanInstallment
+	err := row.Scan(
+		&i.ID,
+		&i.LoanID,
+		&i.Seq,
+		&i.DueDate,
+		&i.Principal,
+		&i.Interest,
+		&i.LateFee,
+		&i.Status,
+		&i.PaidAt,
+	)
+	return i, err
+}
+
+const markLoanInstallmentsOverdue = `-- name: MarkLoanInstallmentsOverdue :many
+UPDATE LOAN_INSTALLMENTS
+SET STATUS = 'overdue',
+    LATE_FEE = $1
+WHERE STATUS = 'scheduled'
+AND DUE_DATE < $2
+RETURNING id, loan_id, seq, due_date, principal, interest, late_fee, status, paid_at
+`
+
+type MarkLoanInstallmentsOverdueParams struct {
+	LateFee   int64     `json:"late_fee"`
+	DueBefore time.Time `json:"due_before"`
+}
+
+func (q *Queries) MarkLoanInstallmentsOverdue(ctx context.Context, arg MarkLoanInstallmentsOverdueParams) ([]LoanInstallment, error) {
+	rows, err := q.db.QueryContext(ctx, markLoanInstallmentsOverdue, arg.LateFee, arg.DueBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoanInstallment{}
+	for rows.Next() {
+		var i LoanInstallment
+		if err := rows.Scan(
+			&i.ID,
+			&i.LoanID,
+			&i.Seq,
+			&i.DueDate,
+			&i.Principal,
+			&i.Interest,
+			&i.LateFee,
+			&i.Status,
+			&i.PaidAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const repayLoanPrincipal = `-- name: RepayLoanPrincipal :one
+UPDATE LOANS
+SET OUTSTANDING_PRINCIPAL = OUTSTANDING_PRINCIPAL - $1,
+    STATUS = CASE WHEN OUTSTANDING_PRINCIPAL - $1 = 0 THEN 'paid_off' ELSE STATUS END
+WHERE ID = $2
+RETURNING id, account_id, borrower_id, currency, principal, outstanding_principal, annual_rate_bps, term_months, method, status, created_by, created_at
+`
+
+type RepayLoanPrincipalParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) RepayLoanPrincipal(ctx context.Context, arg RepayLoanPrincipalParams) (Loan, error) {
+	row := q.db.QueryRowContext(ctx, repayLoanPrincipal, arg.Amount, arg.ID)
+	var i Loan
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.BorrowerID,
+		&i.Currency,
+		&i.Principal,
+		&i.OutstandingPrincipal,
+		&i.AnnualRateBps,
+		&i.TermMonths,
+		&i.Method,
+		&i.Status,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func originateTestLoan(t *testing.T, store Store, account Account) OriginateLoanTxResult {
+	admin := createRandomUser(t)
+	dueDate := time.Now().UTC().AddDate(0, 0, -1)
+
+	result, err := store.OriginateLoanTx(context.Background(), OriginateLoanTxParams{
+		AccountID:     account.ID,
+		Principal:     200,
+		AnnualRateBps: 1200,
+		Method:        "equal_principal",
+		CreatedBy:     admin.ID,
+		Installments: []LoanInstallmentParams{
+			{DueDate: dueDate, Principal: 100, Interest: 2},
+			{DueDate: dueDate.AddDate(0, 1, 0), Principal: 100, Interest: 1},
+		},
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestOriginateLoanTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	result := originateTestLoan(t, store, account)
+	require.Equal(t, account.Owner, result.Loan.BorrowerID)
+	require.Equal(t, int64(200), result.Loan.OutstandingPrincipal)
+	require.Equal(t, int32(2), result.Loan.TermMonths)
+	require.Equal(t, LoanStatusActive, result.Loan.Status)
+	require.Len(t, result.Installments, 2)
+	require.Equal(t, InstallmentStatusScheduled, result.Installments[0].Status)
+
+	require.Equal(t, account.ID, result.Disbursement.ToAccount.ID)
+	require.Equal(t, int64(200), result.Disbursement.ToAccount.Balance)
+}
+
+func TestCollectLoanInstallmentTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, util.USD, 0)
+	loan := originateTestLoan(t, store, account)
+	installment := loan.Installments[0]
+
+	overdue, err := testQueries.MarkLoanInstallmentsOverdue(context.Background(), MarkLoanInstallmentsOverdueParams{
+		LateFee:   25,
+		DueBefore: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, overdue)
+
+	result, err := store.CollectLoanInstallmentTx(context.Background(), installment.ID)
+	require.NoError(t, err)
+	require.Equal(t, InstallmentStatusPaid, result.Installment.Status)
+	require.Equal(t, int64(25), result.Installment.LateFee)
+	require.Equal(t, int64(100), result.Loan.OutstandingPrincipal)
+	require.Equal(t, int64(102), result.Repayment.Transfer.Amount)
+	require.NotNil(t, result.LateFee)
+	require.Equal(t, int64(200-102-25), result.LateFee.FromAccount.Balance)
+
+	_, err = store.CollectLoanInstallmentTx(context.Background(), installment.ID)
+	require.ErrorIs(t, err, ErrInstallmentAlreadyPaid)
+
+	// the second installment cannot be covered, nothing is collected
+	_, err = store.CollectLoanInstallmentTx(context.Background(), loan.Installments[1].ID)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	next, err := testQueries.GetNextLoanInstallment(context.Background(), loan.Loan.ID)
+	require.NoError(t, err)
+	require.Equal(t, loan.Installments[1].ID, next.ID)
+}
+
+func TestLoanRepaymentOutsideTransferLimits(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	account := createRandomAccountWithCurrency(t, util.USD, 0)
+	recipient := createRandomAccountWithCurrency(t, util.USD, 0)
+	loan := originateTestLoan(t, store, account)
+
+	_, err := store.CollectLoanInstallmentTx(context.Background(), loan.Installments[0].ID)
+	require.NoError(t, err)
+
+	// the repayment is not a payment of the borrower
+	total, err := testQueries.GetDailyTransferTotal(context.Background(), GetDailyTransferTotalParams{
+		Owner:    account.Owner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Zero(t, total)
+
+	count, err := testQueries.CountUserTransfersSince(context.Background(), CountUserTransfersSinceParams{
+		Owner: account.Owner,
+		Since: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	_, err = testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:           account.ID,
+		PerTransactionLimit: 50,
+		DailyLimit:          50,
+		UpdatedBy:           admin.ID,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   recipient.ID,
+		Amount:        50,
+		EnforceLimits: true,
+	})
+	require.NoError(t, err)
+}
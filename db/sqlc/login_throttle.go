@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+)
+
+const (
+	LoginScopeUsername = "username"
+	LoginScopeIP       = "ip"
+)
+
+const (
+	LoginEventLocked   = "locked"
+	LoginEventUnlocked = "unlocked"
+)
+
+// LoginLockoutPolicy locks a username or a client IP after too many failed logins in a row. Every
+// lockout lasts twice as long as the previous one, up to MaxLockoutDuration.
+type LoginLockoutPolicy struct {
+	// MaxFailedAttempts and MaxFailedAttemptsPerIP are the failures that start a lockout, zero
+	// disables the scope
+	MaxFailedAttempts      int64
+	MaxFailedAttemptsPerIP int64
+	LockoutDuration        time.Duration
+	// MaxLockoutDuration also bounds the memory of failures, older ones are forgotten
+	MaxLockoutDuration time.Duration
+}
+
+// NewLoginLockoutPolicy reads the policy from the configuration
+func NewLoginLockoutPolicy(config util.Config) LoginLockoutPolicy {
+	return LoginLockoutPolicy{
+		MaxFailedAttempts:      config.LoginMaxFailedAttempts,
+		MaxFailedAttemptsPerIP: config.LoginMaxFailedAttemptsPerIP,
+		LockoutDuration:        config.LoginLockoutDuration,
+		MaxLockoutDuration:     config.LoginMaxLockoutDuration,
+	}
+}
+
+// Enabled reports whether failed logins are counted at all
+func (policy LoginLockoutPolicy) Enabled() bool {
+	return (policy.MaxFailedAttempts > 0 || policy.MaxFailedAttemptsPerIP > 0) && policy.LockoutDuration > 0
+}
+
+func (policy LoginLockoutPolicy) lockoutDuration(lockouts int64) time.Duration {
+	duration := policy.LockoutDuration
+	for i := int64(1); i < lockouts && duration < policy.MaxLockoutDuration; i++ {
+		duration *= 2
+	}
+
+	if duration > policy.MaxLockoutDuration {
+		return policy.MaxLockoutDuration
+	}
+	return duration
+}
+
+type RecordLoginFailureTxParams struct {
+	// Username is the username that was tried, it does not have to exist so lockouts do not
+	// reveal which usernames are taken
+	Username string `json:"username"`
+	IP       string `json:"ip"`
+	Policy   LoginLockoutPolicy
+}
+
+type RecordLoginFailureTxResult struct {
+	// Lockouts are the lockouts that this failure started
+	Lockouts []LoginLockoutEvent `json:"lockouts"`
+}
+
+// RecordLoginFailureTx counts a failed login against the username and the client IP, and locks
+// the ones that reached their limit
+func (store *SQLStore) RecordLoginFailureTx(ctx context.Context, arg RecordLoginFailureTxParams) (RecordLoginFailureTxResult, error) {
+	result := RecordLoginFailureTxResult{Lockouts: []LoginLockoutEvent{}}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		scopes := []struct {
+			scope       string
+			subject     string
+			maxFailures int64
+		}{
+			{LoginScopeUsername, arg.Username, arg.Policy.MaxFailedAttempts},
+			{LoginScopeIP, arg.IP, arg.Policy.MaxFailedAttemptsPerIP},
+		}
+
+		for _, s := range scopes {
+			if s.subject == "" || s.maxFailures <= 0 {
+				continue
+			}
+
+			event, err := recordLoginFailure(ctx, q, s.scope, s.subject, s.maxFailures, arg.Policy)
+			if err != nil {
+				return err
+			}
+
+			if event != nil {
+				result.Lockouts = append(result.Lockouts, *event)
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+func recordLoginFailure(ctx context.Context, q *Queries, scope string, subject string, maxFailures int64, policy LoginLockoutPolicy) (*LoginLockoutEvent, error) {
+	now := time.Now()
+
+	throttle, err := q.GetLoginThrottleForUpdate(ctx, GetLoginThrottleForUpdateParams{
+		Scope:   scope,
+		Subject: subject,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	if err == nil && throttle.LockedUntil.Before(now) && now.Sub(throttle.UpdatedAt) > policy.MaxLockoutDuration {
+		throttle.FailedAttempts = 0
+		throttle.Lockouts = 0
+	}
+
+	arg := UpsertLoginThrottleParams{
+		Scope:          scope,
+		Subject:        subject,
+		FailedAttempts: throttle.FailedAttempts + 1,
+		Lockouts:       throttle.Lockouts,
+		LockedUntil:    throttle.LockedUntil,
+	}
+
+	locked := arg.FailedAttempts >= maxFailures
+	if locked {
+		arg.FailedAttempts = 0
+		arg.Lockouts++
+		arg.LockedUntil = now.Add(policy.lockoutDuration(arg.Lockouts))
+	}
+
+	_, err = q.UpsertLoginThrottle(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if !locked {
+		return nil, nil
+	}
+
+	system, err := q.GetUserByUsername(ctx, SystemUsername)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := q.CreateLoginLockoutEvent(ctx, CreateLoginLockoutEventParams{
+		Scope:       scope,
+		Subject:     subject,
+		Event:       LoginEventLocked,
+		LockedUntil: arg.LockedUntil,
+		ChangedBy:   system.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+type UnlockLoginTxParams struct {
+	Username   string `json:"username"`
+	UnlockedBy int64  `json:"unlocked_by"`
+}
+
+// UnlockLoginTx lifts the lockout of a username and forgets its failed logins
+func (store *SQLStore) UnlockLoginTx(ctx context.Context, arg UnlockLoginTxParams) (LoginLockoutEvent, error) {
+	var event LoginLockoutEvent
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.DeleteLoginThrottle(ctx, DeleteLoginThrottleParams{
+			Scope:   LoginScopeUsername,
+			Subject: arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		event, err = q.CreateLoginLockoutEvent(ctx, CreateLoginLockoutEventParams{
+			Scope:     LoginScopeUsername,
+			Subject:   arg.Username,
+			Event:     LoginEventUnlocked,
+			ChangedBy: arg.UnlockedBy,
+		})
+		return err
+	})
+
+	return event, err
+}
@@ -0,0 +1,199 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: login_throttle.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createLoginLockoutEvent = `-- name: CreateLoginLockoutEvent :one
+INSERT INTO LOGIN_LOCKOUT_EVENTS (
+  SCOPE,
+  SUBJECT,
+  EVENT,
+  LOCKED_UNTIL,
+  CHANGED_BY
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, scope, subject, event, locked_until, changed_by, created_at
+`
+
+type CreateLoginLockoutEventParams struct {
+	Scope       string    `json:"scope"`
+	Subject     string    `json:"subject"`
+	Event       string    `json:"event"`
+	LockedUntil time.Time `json:"locked_until"`
+	ChangedBy   int64     `json:"changed_by"`
+}
+
+func (q *Queries) CreateLoginLockoutEvent(ctx context.Context, arg CreateLoginLockoutEventParams) (LoginLockoutEvent, error) {
+	row := q.db.QueryRowContext(ctx, createLoginLockoutEvent,
+		arg.Scope,
+		arg.Subject,
+		arg.Event,
+		arg.LockedUntil,
+		arg.ChangedBy,
+	)
+	var i LoginLockoutEvent
+	err := row.Scan(
+		&i.ID,
+		&i.Scope,
+		&i.Subject,
+		&i.Event,
+		&i.LockedUntil,
+		&i.ChangedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteLoginThrottle = `-- name: DeleteLoginThrottle :exec
+DELETE FROM LOGIN_THROTTLES
+WHERE SCOPE = $1 AND SUBJECT = $2
+`
+
+type DeleteLoginThrottleParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) error {
+	_, err := q.db.ExecContext(ctx, deleteLoginThrottle, arg.Scope, arg.Subject)
+	return err
+}
+
+const getLoginLockedUntil = `-- name: GetLoginLockedUntil :one
+SELECT COALESCE(MAX(LOCKED_UNTIL), '0001-01-01 00:00:00Z')::timestamptz AS LOCKED_UNTIL
+FROM LOGIN_THROTTLES
+WHERE (SCOPE = 'username' AND SUBJECT = $1::varchar)
+   OR (SCOPE = 'ip' AND SUBJECT = $2::varchar)
+`
+
+type GetLoginLockedUntilParams struct {
+	Username string `json:"username"`
+	Ip       string `json:"ip"`
+}
+
+func (q *Queries) GetLoginLockedUntil(ctx context.Context, arg GetLoginLockedUntilParams) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getLoginLockedUntil, arg.Username, arg.Ip)
+	var locked_until time.Time
+	err := row.Scan(&locked_until)
+	return locked_until, err
+}
+
+const getLoginThrottleForUpdate = `-- name: GetLoginThrottleForUpdate :one
+SELECT scope, subject, failed_attempts, lockouts, locked_until, updated_at FROM LOGIN_THROTTLES
+WHERE SCOPE = $1 AND SUBJECT = $2
+FOR NO KEY UPDATE
+`
+
+type GetLoginThrottleForUpdateParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) GetLoginThrottleForUpdate(ctx context.Context, arg GetLoginThrottleForUpdateParams) (LoginThrottle, error) {
+	row := q.db.QueryRowContext(ctx, getLoginThrottleForUpdate, arg.Scope, arg.Subject)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Scope,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listLoginLockoutEvents = `-- name: ListLoginLockoutEvents :many
+SELECT id, scope, subject, event, locked_until, changed_by, created_at FROM LOGIN_LOCKOUT_EVENTS
+WHERE SCOPE = $1 AND SUBJECT = $2
+ORDER BY ID
+`
+
+type ListLoginLockoutEventsParams struct {
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+}
+
+func (q *Queries) ListLoginLockoutEvents(ctx context.Context, arg ListLoginLockoutEventsParams) ([]LoginLockoutEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginLockoutEvents, arg.Scope, arg.Subject)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []LoginLockoutEvent{}
+	for rows.Next() {
+		var i LoginLockoutEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.Scope,
+			&i.Subject,
+			&i.Event,
+			&i.LockedUntil,
+			&i.ChangedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertLoginThrottle = `-- name: UpsertLoginThrottle :one
+INSERT INTO LOGIN_THROTTLES (
+  SCOPE,
+  SUBJECT,
+  FAILED_ATTEMPTS,
+  LOCKOUTS,
+  LOCKED_UNTIL,
+  UPDATED_AT
+) VALUES (
+  $1, $2, $3, $4, $5, now()
+) ON CONFLICT (SCOPE, SUBJECT) DO UPDATE SET
+  FAILED_ATTEMPTS = EXCLUDED.FAILED_ATTEMPTS,
+  LOCKOUTS = EXCLUDED.LOCKOUTS,
+  LOCKED_UNTIL = EXCLUDED.LOCKED_UNTIL,
+  UPDATED_AT = EXCLUDED.UPDATED_AT
+RETURNING scope, subject, failed_attempts, lockouts, locked_until, updated_at
+`
+
+type UpsertLoginThrottleParams struct {
+	Scope          string    `json:"scope"`
+	Subject        string    `json:"subject"`
+	FailedAttempts int64     `json:"failed_attempts"`
+	Lockouts       int64     `json:"lockouts"`
+	LockedUntil    time.Time `json:"locked_until"`
+}
+
+func (q *Queries) UpsertLoginThrottle(ctx context.Context, arg UpsertLoginThrottleParams) (LoginThrottle, error) {
+	row := q.db.QueryRowContext(ctx, upsertLoginThrottle,
+		arg.Scope,
+		arg.Subject,
+		arg.FailedAttempts,
+		arg.Lockouts,
+		arg.LockedUntil,
+	)
+	var i LoginThrottle
+	err := row.Scan(
+		&i.Scope,
+		&i.Subject,
+		&i.FailedAttempts,
+		&i.Lockouts,
+		&i.LockedUntil,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginFailureTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// the username does not have to exist
+	username := util.RandomUsername()
+	ip := fmt.Sprintf("10.%d.%d.%d", util.RandomInt(0, 255), util.RandomInt(0, 255), util.RandomInt(0, 255))
+
+	policy := LoginLockoutPolicy{
+		MaxFailedAttempts:      3,
+		MaxFailedAttemptsPerIP: 100,
+		LockoutDuration:        time.Minute,
+		MaxLockoutDuration:     time.Hour,
+	}
+	arg := RecordLoginFailureTxParams{Username: username, IP: ip, Policy: policy}
+
+	for i := 0; i < 2; i++ {
+		result, err := store.RecordLoginFailureTx(context.Background(), arg)
+		require.NoError(t, err)
+		require.Empty(t, result.Lockouts)
+	}
+
+	lockedUntil, err := store.GetLoginLockedUntil(context.Background(), GetLoginLockedUntilParams{Username: username, Ip: ip})
+	require.NoError(t, err)
+	require.True(t, lockedUntil.Before(time.Now()))
+
+	result, err := store.RecordLoginFailureTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Lockouts, 1)
+	require.Equal(t, LoginScopeUsername, result.Lockouts[0].Scope)
+	require.Equal(t, username, result.Lockouts[0].Subject)
+	require.Equal(t, LoginEventLocked, result.Lockouts[0].Event)
+	require.WithinDuration(t, time.Now().Add(time.Minute), result.Lockouts[0].LockedUntil, time.Second)
+
+	lockedUntil, err = store.GetLoginLockedUntil(context.Background(), GetLoginLockedUntilParams{Username: username, Ip: ip})
+	require.NoError(t, err)
+	require.True(t, lockedUntil.After(time.Now()))
+
+	// the next lockout lasts twice as long
+	for i := 0; i < 3; i++ {
+		result, err = store.RecordLoginFailureTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+	require.Len(t, result.Lockouts, 1)
+	require.WithinDuration(t, time.Now().Add(2*time.Minute), result.Lockouts[0].LockedUntil, time.Second)
+
+	throttle, err := store.GetLoginThrottleForUpdate(context.Background(), GetLoginThrottleForUpdateParams{
+		Scope:   LoginScopeIP,
+		Subject: ip,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(6), throttle.FailedAttempts)
+}
+
+func TestUnlockLoginTx(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+	username := util.RandomUsername()
+
+	policy := LoginLockoutPolicy{
+		MaxFailedAttempts:  1,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	}
+
+	_, err := store.RecordLoginFailureTx(context.Background(), RecordLoginFailureTxParams{Username: username, Policy: policy})
+	require.NoError(t, err)
+
+	event, err := store.UnlockLoginTx(context.Background(), UnlockLoginTxParams{Username: username, UnlockedBy: admin.ID})
+	require.NoError(t, err)
+	require.Equal(t, LoginEventUnlocked, event.Event)
+	require.Equal(t, admin.ID, event.ChangedBy)
+
+	lockedUntil, err := store.GetLoginLockedUntil(context.Background(), GetLoginLockedUntilParams{Username: username})
+	require.NoError(t, err)
+	require.True(t, lockedUntil.Before(time.Now()))
+
+	events, err := store.ListLoginLockoutEvents(context.Background(), ListLoginLockoutEventsParams{
+		Scope:   LoginScopeUsername,
+		Subject: username,
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+}
@@ -14,6 +14,89 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// held by pending transfers, not available to spend
+	ReservedBalance int64 `json:"reserved_balance"`
+	// checking or savings
+	Type     string `json:"type"`
+	Nickname string `json:"nickname"`
+	// active, frozen or closed
+	Status string `json:"status"`
+	// how far below zero the available balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// annual interest charged on a negative balance
+	OverdraftRateBps int32 `json:"overdraft_rate_bps"`
+	// set aside in pockets, not available to spend
+	PocketBalance int64 `json:"pocket_balance"`
+}
+
+type AccountApprovalPolicy struct {
+	AccountID int64 `json:"account_id"`
+	// transfers above this amount need approvals
+	Threshold int64 `json:"threshold"`
+	// not counting the user who made the transfer
+	RequiredApprovals     int32     `json:"required_approvals"`
+	ApprovalWindowMinutes int32     `json:"approval_window_minutes"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+type AccountApprover struct {
+	AccountID int64     `json:"account_id"`
+	UserID    int64     `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AccountMember struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+	// owner, co_owner or viewer
+	Role string `json:"role"`
+	// invited or active
+	Status string `json:"status"`
+	// the owner themselves for the membership created with the account
+	InvitedBy int64     `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AccountStatusChange struct {
+	ID         int64  `json:"id"`
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	ChangedBy  int64  `json:"changed_by"`
+	// owner or admin
+	ActorRole string    `json:"actor_role"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AccountTransferLimit struct {
+	AccountID           int64 `json:"account_id"`
+	PerTransactionLimit int64 `json:"per_transaction_limit"`
+	DailyLimit          int64 `json:"daily_limit"`
+	// admin who set the override
+	UpdatedBy int64     `json:"updated_by"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BillSplit struct {
+	ID          int64  `json:"id"`
+	CreatorID   int64  `json:"creator_id"`
+	ToAccountID int64  `json:"to_account_id"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+	Memo        string `json:"memo"`
+	// equal, percentage or exact
+	Method string `json:"method"`
+	// part of the amount that is not requested: the share of the creator plus the rounding remainder
+	CreatorShare int64     `json:"creator_share"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type BillSplitShare struct {
+	SplitID   int64 `json:"split_id"`
+	UserID    int64 `json:"user_id"`
+	Amount    int64 `json:"amount"`
+	RequestID int64 `json:"request_id"`
 }
 
 type Entry struct {
@@ -24,6 +107,212 @@ type Entry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type Escrow struct {
+	ID              int64  `json:"id"`
+	BuyerID         int64  `json:"buyer_id"`
+	BuyerAccountID  int64  `json:"buyer_account_id"`
+	SellerAccountID int64  `json:"seller_account_id"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	Description     string `json:"description"`
+	// held, disputed, released or refunded
+	Status            string `json:"status"`
+	DisputeReason     string `json:"dispute_reason"`
+	FundingTransferID int64  `json:"funding_transfer_id"`
+	// held funds are released to the seller at this time unless a dispute was opened
+	ReleaseAt time.Time `json:"release_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type EscrowSettlement struct {
+	EscrowID   int64 `json:"escrow_id"`
+	TransferID int64 `json:"transfer_id"`
+	// released or refunded
+	Outcome string `json:"outcome"`
+	// user who settled the escrow, the system user for automatic releases
+	SettledBy int64     `json:"settled_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// end of day balance
+	Balance       int64 `json:"balance"`
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+	// millionths of a minor unit
+	AmountMicros int64     `json:"amount_micros"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type InterestPosting struct {
+	AccountID   int64     `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+	// accruals of the month plus the carry of the previous month
+	AccruedMicros int64 `json:"accrued_micros"`
+	Amount        int64 `json:"amount"`
+	// remainder below one minor unit, carried to the next month
+	CarryMicros int64     `json:"carry_micros"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type InterestRate struct {
+	Currency    string `json:"currency"`
+	AccountType string `json:"account_type"`
+	// 1 bps = 0.01% per year
+	AnnualRateBps int32     `json:"annual_rate_bps"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Loan struct {
+	ID int64 `json:"id"`
+	// disbursed into and repaid from
+	AccountID            int64  `json:"account_id"`
+	BorrowerID           int64  `json:"borrower_id"`
+	Currency             string `json:"currency"`
+	Principal            int64  `json:"principal"`
+	OutstandingPrincipal int64  `json:"outstanding_principal"`
+	AnnualRateBps        int32  `json:"annual_rate_bps"`
+	TermMonths           int32  `json:"term_months"`
+	// annuity or equal_principal
+	Method string `json:"method"`
+	// active or paid_off
+	Status    string    `json:"status"`
+	CreatedBy int64     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoanInstallment struct {
+	ID        int64     `json:"id"`
+	LoanID    int64     `json:"loan_id"`
+	Seq       int32     `json:"seq"`
+	DueDate   time.Time `json:"due_date"`
+	Principal int64     `json:"principal"`
+	Interest  int64     `json:"interest"`
+	LateFee   int64     `json:"late_fee"`
+	// scheduled, overdue or paid
+	Status string    `json:"status"`
+	PaidAt time.Time `json:"paid_at"`
+}
+
+type LoginLockoutEvent struct {
+	ID      int64  `json:"id"`
+	Scope   string `json:"scope"`
+	Subject string `json:"subject"`
+	// locked or unlocked
+	Event       string    `json:"event"`
+	LockedUntil time.Time `json:"locked_until"`
+	// the system user for lockouts, the admin for unlocks
+	ChangedBy int64     `json:"changed_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type LoginThrottle struct {
+	// username or ip
+	Scope string `json:"scope"`
+	// the username tried, whether or not it exists, or the client IP
+	Subject        string `json:"subject"`
+	FailedAttempts int64  `json:"failed_attempts"`
+	// lockouts in a row, each one lasts twice as long as the previous one
+	Lockouts    int64     `json:"lockouts"`
+	LockedUntil time.Time `json:"locked_until"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// sha256 of the recovery code, each code can be used once
+	HashedCode string    `json:"hashed_code"`
+	UsedAt     time.Time `json:"used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type MoneyRequest struct {
+	ID          int64 `json:"id"`
+	RequesterID int64 `json:"requester_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	// 0 for requests shared as a link, anyone with the link can pay them
+	PayerID int64 `json:"payer_id"`
+	// sha256 of the token of the payment link, empty for requests sent to a user
+	HashedLinkToken string `json:"-"`
+	Amount          int64  `json:"amount"`
+	Currency        string `json:"currency"`
+	Memo            string `json:"memo"`
+	// pending, paid, declined or expired
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type MoneyRequestPayment struct {
+	RequestID  int64     `json:"request_id"`
+	TransferID int64     `json:"transfer_id"`
+	PaidBy     int64     `json:"paid_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OverdraftAccrual struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// end of day balance, negative
+	Balance       int64 `json:"balance"`
+	AnnualRateBps int32 `json:"annual_rate_bps"`
+	// millionths of a minor unit
+	AmountMicros int64     `json:"amount_micros"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Payee struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	AccountID int64  `json:"account_id"`
+	Nickname  string `json:"nickname"`
+	// masked full name of the account owner when the payee was saved
+	HolderName string    `json:"holder_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	// when the user first saved the account as a payee, deleting and saving it again does not restart the cooling-off period
+	FirstSavedAt time.Time `json:"first_saved_at"`
+}
+
+type PayeeTransfer struct {
+	TransferID int64 `json:"transfer_id"`
+	// payee the transfer was paid to, zero when the recipient was not a saved payee, the row is kept when the payee is deleted
+	PayeeID int64 `json:"payee_id"`
+	UserID  int64 `json:"user_id"`
+	// account that receives the money, the seller account of an escrow
+	AccountID int64 `json:"account_id"`
+	// first_saved_at of the payee when the transfer was made
+	FirstSavedAt time.Time `json:"first_saved_at"`
+}
+
+type Pocket struct {
+	ID           int64     `json:"id"`
+	AccountID    int64     `json:"account_id"`
+	Name         string    `json:"name"`
+	TargetAmount int64     `json:"target_amount"`
+	TargetDate   time.Time `json:"target_date"`
+	Balance      int64     `json:"balance"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type PocketMovement struct {
+	ID       int64 `json:"id"`
+	PocketID int64 `json:"pocket_id"`
+	// positive into the pocket, negative back to the main balance
+	Amount    int64     `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SystemAccount struct {
+	// interest_expense, loan_funding, fee_income or escrow
+	Purpose   string `json:"purpose"`
+	Currency  string `json:"currency"`
+	AccountID int64  `json:"account_id"`
+}
+
 type Transfer struct {
 	ID            int64 `json:"id"`
 	ToAccountID   int64 `json:"to_account_id"`
@@ -31,6 +320,51 @@ type Transfer struct {
 	// must be positive amount
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// completed, pending, awaiting_approval, rejected, declined, expired or blocked
+	Status string `json:"status"`
+}
+
+type TransferApproval struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+	ApproverID int64 `json:"approver_id"`
+	// approved or declined
+	Decision  string    `json:"decision"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TransferApprovalRequest struct {
+	TransferID        int64     `json:"transfer_id"`
+	InitiatedBy       int64     `json:"initiated_by"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type TransferLimit struct {
+	Currency            string `json:"currency"`
+	PerTransactionLimit int64  `json:"per_transaction_limit"`
+	// rolling 24 hours, per user
+	DailyLimit int64     `json:"daily_limit"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type TransferReview struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+	ReviewerID int64 `json:"reviewer_id"`
+	// approved or rejected
+	Decision  string    `json:"decision"`
+	Comment   string    `json:"comment"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TransferRiskAssessment struct {
+	TransferID int64 `json:"transfer_id"`
+	// allow, review or block
+	Decision     string    `json:"decision"`
+	MatchedRules []string  `json:"matched_rules"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type User struct {
@@ -41,4 +375,35 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	// optional E.164 phone number, empty when not given
+	Phone string `json:"phone"`
+	// when the email was last verified, zero while it is not verified
+	EmailVerifiedAt time.Time `json:"email_verified_at"`
+}
+
+type UserMfa struct {
+	UserID int64 `json:"user_id"`
+	// base32 TOTP secret shared with the authenticator app
+	Secret string `json:"secret"`
+	// pending until the first code is verified, then enabled
+	Status string `json:"status"`
+	// TOTP time step of the last accepted code, codes of this step or older are rejected
+	LastUsedStep int64     `json:"last_used_step"`
+	ConfirmedAt  time.Time `json:"confirmed_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type UserToken struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// verify_email or reset_password
+	Purpose string `json:"purpose"`
+	// sha256 of the token sent by email, the token itself is never stored
+	HashedToken string `json:"hashed_token"`
+	// address the token was sent to, a verification only counts while the user still has it
+	Email     string    `json:"email"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UsedAt    time.Time `json:"used_at"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrMoneyRequestNotPending = errors.New("money request is not pending")
+	ErrMoneyRequestExpired    = errors.New("money request has expired")
+	ErrNotMoneyRequestPayer   = errors.New("money request cannot be paid by this user")
+)
+
+type PayMoneyRequestTxParams struct {
+	RequestID     int64 `json:"request_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	PaidBy        int64 `json:"paid_by"`
+	// RiskDecision and MatchedRules are persisted with the transfer when it has been assessed
+	RiskDecision string   `json:"risk_decision"`
+	MatchedRules []string `json:"matched_rules"`
+	// Payee records the payment against the requester account for the cooling-off limit
+	Payee *PayeeTransferParams `json:"payee,omitempty"`
+}
+
+type PayMoneyRequestTxResult struct {
+	TransferTxResult
+	Request MoneyRequest        `json:"request"`
+	Payment MoneyRequestPayment `json:"payment"`
+}
+
+// PayMoneyRequestTx pays a pending money request from an account of the payer. The transfer is
+// checked against the limits of the payer like any other transfer.
+func (store *SQLStore) PayMoneyRequestTx(ctx context.Context, arg PayMoneyRequestTxParams) (PayMoneyRequestTxResult, error) {
+	var result PayMoneyRequestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		request, err := q.GetMoneyRequestForUpdate(ctx, arg.RequestID)
+		if err != nil {
+			return err
+		}
+
+		if request.Status != MoneyRequestStatusPending {
+			return ErrMoneyRequestNotPending
+		}
+
+		// the request is expired by ExpireMoneyRequests, it is only refused here
+		if !time.Now().Before(request.ExpiresAt) {
+			return ErrMoneyRequestExpired
+		}
+
+		if request.RequesterID == arg.PaidBy || (request.PayerID != 0 && request.PayerID != arg.PaidBy) {
+			return ErrNotMoneyRequestPayer
+		}
+
+		err = runTransfer(ctx, q, TransferTxParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   request.ToAccountID,
+			Amount:        request.Amount,
+			EnforceLimits: true,
+			RiskDecision:  arg.RiskDecision,
+			MatchedRules:  arg.MatchedRules,
+			Payee:         arg.Payee,
+		}, &result.TransferTxResult)
+		if err != nil {
+			return err
+		}
+
+		result.Payment, err = q.CreateMoneyRequestPayment(ctx, CreateMoneyRequestPaymentParams{
+			RequestID:  request.ID,
+			TransferID: result.Transfer.ID,
+			PaidBy:     arg.PaidBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Request, err = q.UpdateMoneyRequestStatus(ctx, UpdateMoneyRequestStatusParams{
+			ID:     request.ID,
+			Status: MoneyRequestStatusPaid,
+		})
+		return err
+	})
+
+	return result, err
+}
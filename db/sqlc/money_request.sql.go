@@ -0,0 +1,374 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: money_request.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createMoneyRequest = `-- name: CreateMoneyRequest :one
+INSERT INTO MONEY_REQUESTS (
+  REQUESTER_ID,
+  TO_ACCOUNT_ID,
+  PAYER_ID,
+  HASHED_LINK_TOKEN,
+  AMOUNT,
+  CURRENCY,
+  MEMO,
+  EXPIRES_AT
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at
+`
+
+type CreateMoneyRequestParams struct {
+	RequesterID     int64     `json:"requester_id"`
+	ToAccountID     int64     `json:"to_account_id"`
+	PayerID         int64     `json:"payer_id"`
+	HashedLinkToken string    `json:"hashed_link_token"`
+	Amount          int64     `json:"amount"`
+	Currency        string    `json:"currency"`
+	Memo            string    `json:"memo"`
+	ExpiresAt       time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateMoneyRequest(ctx context.Context, arg CreateMoneyRequestParams) (MoneyRequest, error) {
+	row := q.db.QueryRowContext(ctx, createMoneyRequest,
+		arg.RequesterID,
+		arg.ToAccountID,
+		arg.PayerID,
+		arg.HashedLinkToken,
+		arg.Amount,
+		arg.Currency,
+		arg.Memo,
+		arg.ExpiresAt,
+	)
+	var i MoneyRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.ToAccountID,
+		&i.PayerID,
+		&i.HashedLinkToken,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createMoneyRequestPayment = `-- name: CreateMoneyRequestPayment :one
+INSERT INTO MONEY_REQUEST_PAYMENTS (
+  REQUEST_ID,
+  TRANSFER_ID,
+  PAID_BY
+) VALUES (
+  $1, $2, $3
+) RETURNING request_id, transfer_id, paid_by, created_at
+`
+
+type CreateMoneyRequestPaymentParams struct {
+	RequestID  int64 `json:"request_id"`
+	TransferID int64 `json:"transfer_id"`
+	PaidBy     int64 `json:"paid_by"`
+}
+
+func (q *Queries) CreateMoneyRequestPayment(ctx context.Context, arg CreateMoneyRequestPaymentParams) (MoneyRequestPayment, error) {
+	row := q.db.QueryRowContext(ctx, createMoneyRequestPayment, arg.RequestID, arg.TransferID, arg.PaidBy)
+	var i MoneyRequestPayment
+	err := row.Scan(
+		&i.RequestID,
+		&i.TransferID,
+		&i.PaidBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const declineMoneyRequest = `-- name: DeclineMoneyRequest :one
+UPDATE MONEY_REQUESTS
+SET STATUS = 'declined', UPDATED_AT = now()
+WHERE ID = $1 AND STATUS = 'pending' AND EXPIRES_AT > now()
+RETURNING id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at
+`
+
+func (q *Queries) DeclineMoneyRequest(ctx context.Context, id int64) (MoneyRequest, error) {
+	row := q.db.QueryRowContext(ctx, declineMoneyRequest, id)
+	var i MoneyRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.ToAccountID,
+		&i.PayerID,
+		&i.HashedLinkToken,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const expireMoneyRequests = `-- name: ExpireMoneyRequests :execrows
+UPDATE MONEY_REQUESTS
+SET STATUS = 'expired', UPDATED_AT = now()
+WHERE STATUS = 'pending' AND EXPIRES_AT <= now()
+`
+
+func (q *Queries) ExpireMoneyRequests(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, expireMoneyRequests)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getMoneyRequest = `-- name: GetMoneyRequest :one
+SELECT id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at FROM MONEY_REQUESTS
+WHERE ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetMoneyRequest(ctx context.Context, id int64) (MoneyRequest, error) {
+	row := q.db.QueryRowContext(ctx, getMoneyRequest, id)
+	var i MoneyRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.ToAccountID,
+		&i.PayerID,
+		&i.HashedLinkToken,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMoneyRequestByLinkToken = `-- name: GetMoneyRequestByLinkToken :one
+SELECT id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at FROM MONEY_REQUESTS
+WHERE HASHED_LINK_TOKEN = $1 AND HASHED_LINK_TOKEN <> '' LIMIT 1
+`
+
+func (q *Queries) GetMoneyRequestByLinkToken(ctx context.Context, hashedLinkToken string) (MoneyRequest, error) {
+	row := q.db.QueryRowContext(ctx, getMoneyRequestByLinkToken, hashedLinkToken)
+	var i MoneyRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.ToAccountID,
+		&i.PayerID,
+		&i.HashedLinkToken,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMoneyRequestForUpdate = `-- name: GetMoneyRequestForUpdate :one
+SELECT id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at FROM MONEY_REQUESTS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetMoneyRequestForUpdate(ctx context.Context, id int64) (MoneyRequest, error) {
+	row := q.db.QueryRowContext(ctx, getMoneyRequestForUpdate, id)
+	var i MoneyRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.ToAccountID,
+		&i.PayerID,
+		&i.HashedLinkToken,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getMoneyRequestPayment = `-- name: GetMoneyRequestPayment :one
+SELECT request_id, transfer_id, paid_by, created_at FROM MONEY_REQUEST_PAYMENTS
+WHERE REQUEST_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetMoneyRequestPayment(ctx context.Context, requestID int64) (MoneyRequestPayment, error) {
+	row := q.db.QueryRowContext(ctx, getMoneyRequestPayment, requestID)
+	var i MoneyRequestPayment
+	err := row.Scan(
+		&i.RequestID,
+		&i.TransferID,
+		&i.PaidBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listIncomingMoneyRequests = `-- name: ListIncomingMoneyRequests :many
+SELECT id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at FROM MONEY_REQUESTS
+WHERE PAYER_ID = $1
+AND ($2::varchar = '' OR STATUS = $2)
+ORDER BY ID DESC
+LIMIT $4
+OFFSET $3
+`
+
+type ListIncomingMoneyRequestsParams struct {
+	PayerID int64  `json:"payer_id"`
+	Status  string `json:"status"`
+	Offset  int32  `json:"offset_"`
+	Limit   int32  `json:"limit_"`
+}
+
+func (q *Queries) ListIncomingMoneyRequests(ctx context.Context, arg ListIncomingMoneyRequestsParams) ([]MoneyRequest, error) {
+	rows, err := q.db.QueryContext(ctx, listIncomingMoneyRequests,
+		arg.PayerID,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MoneyRequest{}
+	for rows.Next() {
+		var i MoneyRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterID,
+			&i.ToAccountID,
+			&i.PayerID,
+			&i.HashedLinkToken,
+			&i.Amount,
+			&i.Currency,
+			&i.Memo,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOutgoingMoneyRequests = `-- name: ListOutgoingMoneyRequests :many
+SELECT id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at FROM MONEY_REQUESTS
+WHERE REQUESTER_ID = $1
+AND ($2::varchar = '' OR STATUS = $2)
+ORDER BY ID DESC
+LIMIT $4
+OFFSET $3
+`
+
+type ListOutgoingMoneyRequestsParams struct {
+	RequesterID int64  `json:"requester_id"`
+	Status      string `json:"status"`
+	Offset      int32  `json:"offset_"`
+	Limit       int32  `json:"limit_"`
+}
+
+func (q *Queries) ListOutgoingMoneyRequests(ctx context.Context, arg ListOutgoingMoneyRequestsParams) ([]MoneyRequest, error) {
+	rows, err := q.db.QueryContext(ctx, listOutgoingMoneyRequests,
+		arg.RequesterID,
+		arg.Status,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []MoneyRequest{}
+	for rows.Next() {
+		var i MoneyRequest
+		if err := rows.Scan(
+			&i.ID,
+			&i.RequesterID,
+			&i.ToAccountID,
+			&i.PayerID,
+			&i.HashedLinkToken,
+			&i.Amount,
+			&i.Currency,
+			&i.Memo,
+			&i.Status,
+			&i.ExpiresAt,
+			&i.UpdatedAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateMoneyRequestStatus = `-- name: UpdateMoneyRequestStatus :one
+UPDATE MONEY_REQUESTS
+SET STATUS = $1, UPDATED_AT = now()
+WHERE ID = $2
+RETURNING id, requester_id, to_account_id, payer_id, hashed_link_token, amount, currency, memo, status, expires_at, updated_at, created_at
+`
+
+type UpdateMoneyRequestStatusParams struct {
+	Status string `json:"status"`
+	ID     int64  `json:"id"`
+}
+
+func (q *Queries) UpdateMoneyRequestStatus(ctx context.Context, arg UpdateMoneyRequestStatusParams) (MoneyRequest, error) {
+	row := q.db.QueryRowContext(ctx, updateMoneyRequestStatus, arg.Status, arg.ID)
+	var i MoneyRequest
+	err := row.Scan(
+		&i.ID,
+		&i.RequesterID,
+		&i.ToAccountID,
+		&i.PayerID,
+		&i.HashedLinkToken,
+		&i.Amount,
+		&i.Currency,
+		&i.Memo,
+		&i.Status,
+		&i.ExpiresAt,
+		&i.UpdatedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
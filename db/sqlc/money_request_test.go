@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayMoneyRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+	toAccount := createRandomAccountWithCurrency(t, util.USD, 0)
+	fromAccount := createRandomAccountWithCurrency(t, util.USD, 100)
+
+	request, err := testQueries.CreateMoneyRequest(context.Background(), CreateMoneyRequestParams{
+		RequesterID: toAccount.Owner,
+		ToAccountID: toAccount.ID,
+		PayerID:     fromAccount.Owner,
+		Amount:      40,
+		Currency:    util.USD,
+		Memo:        "Dinner",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, MoneyRequestStatusPending, request.Status)
+
+	// only the user the request was sent to can pay it
+	_, err = store.PayMoneyRequestTx(context.Background(), PayMoneyRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: toAccount.ID,
+		PaidBy:        toAccount.Owner,
+	})
+	require.ErrorIs(t, err, ErrNotMoneyRequestPayer)
+
+	result, err := store.PayMoneyRequestTx(context.Background(), PayMoneyRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: fromAccount.ID,
+		PaidBy:        fromAccount.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, MoneyRequestStatusPaid, result.Request.Status)
+	require.Equal(t, result.Transfer.ID, result.Payment.TransferID)
+	require.Equal(t, int64(60), result.FromAccount.Balance)
+	require.Equal(t, int64(40), result.ToAccount.Balance)
+
+	_, err = store.PayMoneyRequestTx(context.Background(), PayMoneyRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: fromAccount.ID,
+		PaidBy:        fromAccount.Owner,
+	})
+	require.ErrorIs(t, err, ErrMoneyRequestNotPending)
+}
+
+func TestExpireMoneyRequests(t *testing.T) {
+	store := NewStore(testDB)
+	toAccount := createRandomAccountWithCurrency(t, util.USD, 0)
+	fromAccount := createRandomAccountWithCurrency(t, util.USD, 100)
+
+	request, err := testQueries.CreateMoneyRequest(context.Background(), CreateMoneyRequestParams{
+		RequesterID:     toAccount.Owner,
+		ToAccountID:     toAccount.ID,
+		HashedLinkToken: util.HashToken(util.RandomString(32)),
+		Amount:          40,
+		Currency:        util.USD,
+		ExpiresAt:       time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = store.PayMoneyRequestTx(context.Background(), PayMoneyRequestTxParams{
+		RequestID:     request.ID,
+		FromAccountID: fromAccount.ID,
+		PaidBy:        fromAccount.Owner,
+	})
+	require.ErrorIs(t, err, ErrMoneyRequestExpired)
+
+	expired, err := testQueries.ExpireMoneyRequests(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, expired, int64(1))
+
+	request, err = testQueries.GetMoneyRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, MoneyRequestStatusExpired, request.Status)
+}
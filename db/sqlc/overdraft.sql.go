@@ -0,0 +1,116 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: overdraft.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createOverdraftAccrual = `-- name: CreateOverdraftAccrual :execrows
+INSERT INTO OVERDRAFT_ACCRUALS (
+  ACCOUNT_ID,
+  ACCRUAL_DATE,
+  BALANCE,
+  ANNUAL_RATE_BPS,
+  AMOUNT_MICROS
+) VALUES (
+  $1, $2, $3, $4, $5
+) ON CONFLICT (ACCOUNT_ID, ACCRUAL_DATE) DO NOTHING
+`
+
+type CreateOverdraftAccrualParams struct {
+	AccountID     int64     `json:"account_id"`
+	AccrualDate   time.Time `json:"accrual_date"`
+	Balance       int64     `json:"balance"`
+	AnnualRateBps int32     `json:"annual_rate_bps"`
+	AmountMicros  int64     `json:"amount_micros"`
+}
+
+func (q *Queries) CreateOverdraftAccrual(ctx context.Context, arg CreateOverdraftAccrualParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createOverdraftAccrual,
+		arg.AccountID,
+		arg.AccrualDate,
+		arg.Balance,
+		arg.AnnualRateBps,
+		arg.AmountMicros,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const listOverdraftAccounts = `-- name: ListOverdraftAccounts :many
+SELECT
+  A.ID,
+  A.OVERDRAFT_RATE_BPS,
+  (A.BALANCE - COALESCE((
+    SELECT SUM(E.AMOUNT) FROM ENTRIES E
+    WHERE E.ACCOUNT_ID = A.ID AND E.CREATED_AT >= $1
+  ), 0))::bigint AS END_OF_DAY_BALANCE
+FROM ACCOUNTS A
+WHERE A.ID > $2
+AND A.CREATED_AT < $1
+AND A.OVERDRAFT_RATE_BPS > 0
+ORDER BY A.ID
+LIMIT $3
+`
+
+type ListOverdraftAccountsParams struct {
+	EndOfDay time.Time `json:"end_of_day"`
+	AfterID  int64     `json:"after_id"`
+	Limit    int32     `json:"limit_"`
+}
+
+type ListOverdraftAccountsRow struct {
+	ID               int64 `json:"id"`
+	OverdraftRateBps int32 `json:"overdraft_rate_bps"`
+	EndOfDayBalance  int64 `json:"end_of_day_balance"`
+}
+
+func (q *Queries) ListOverdraftAccounts(ctx context.Context, arg ListOverdraftAccountsParams) ([]ListOverdraftAccountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOverdraftAccounts, arg.EndOfDay, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListOverdraftAccountsRow{}
+	for rows.Next() {
+		var i ListOverdraftAccountsRow
+		if err := rows.Scan(&i.ID, &i.OverdraftRateBps, &i.EndOfDayBalance); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumOverdraftAccruals = `-- name: SumOverdraftAccruals :one
+SELECT COALESCE(SUM(AMOUNT_MICROS), 0)::bigint FROM OVERDRAFT_ACCRUALS
+WHERE ACCOUNT_ID = $1
+AND ACCRUAL_DATE >= $2
+AND ACCRUAL_DATE < $3
+`
+
+type SumOverdraftAccrualsParams struct {
+	AccountID int64     `json:"account_id"`
+	FromDate  time.Time `json:"from_date"`
+	ToDate    time.Time `json:"to_date"`
+}
+
+func (q *Queries) SumOverdraftAccruals(ctx context.Context, arg SumOverdraftAccrualsParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumOverdraftAccruals, arg.AccountID, arg.FromDate, arg.ToDate)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxOverdraft(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	account1, err := testQueries.UpdateAccountOverdraft(context.Background(), UpdateAccountOverdraftParams{
+		ID:               account1.ID,
+		OverdraftLimit:   50,
+		OverdraftRateBps: 1500,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(150), AvailableBalance(account1))
+
+	// the balance may go below zero up to the overdraft limit
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        140,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(-40), result.FromAccount.Balance)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	created, err := testQueries.CreateOverdraftAccrual(context.Background(), CreateOverdraftAccrualParams{
+		AccountID:     account1.ID,
+		AccrualDate:   result.Transfer.CreatedAt,
+		Balance:       -40,
+		AnnualRateBps: 1500,
+		AmountMicros:  16_438,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), created)
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PayeeTransferParams records a transfer against the recipient account it pays, so that new
+// recipients are capped during their cooling-off period whether or not they are saved payees
+type PayeeTransferParams struct {
+	// PayeeID is the saved payee the transfer is paid to, zero when the account is not a payee
+	PayeeID int64 `json:"payee_id"`
+	// UserID is the user who makes the transfer
+	UserID int64 `json:"user_id"`
+	// AccountID is the account that receives the money, the seller account of an escrow. Zero
+	// means the to account of the transfer.
+	AccountID    int64     `json:"account_id"`
+	FirstSavedAt time.Time `json:"first_saved_at"`
+	// CoolingOffLimit caps the total paid to a recipient first saved or paid less than
+	// CoolingOffPeriod ago, zero disables the cap
+	CoolingOffPeriod time.Duration `json:"cooling_off_period"`
+	CoolingOffLimit  int64         `json:"cooling_off_limit"`
+}
+
+// CoolingOffLimitError is returned when a transfer would pay a new recipient more than it may
+// receive during its cooling-off period
+type CoolingOffLimitError struct {
+	PayeeID   int64 `json:"payee_id"`
+	AccountID int64 `json:"account_id"`
+	Remaining int64 `json:"remaining_allowance"`
+}
+
+func (e *CoolingOffLimitError) Error() string {
+	return fmt.Sprintf("account %d is a new recipient in its cooling-off period: remaining allowance is %d", e.AccountID, e.Remaining)
+}
+
+// recordPayeeTransfer must run inside the transaction that created the transfer. The user is locked
+// so that parallel transfers cannot bypass the cooling-off limit. The limit counts everything the
+// user paid to the account, including payments made before the account was saved or after it was
+// deleted as a payee. Accounts of the user are not new recipients.
+func recordPayeeTransfer(ctx context.Context, q *Queries, payee *PayeeTransferParams, transfer Transfer) error {
+	if payee == nil {
+		return nil
+	}
+
+	accountID := payee.AccountID
+	if accountID == 0 {
+		accountID = transfer.ToAccountID
+	}
+
+	_, err := q.GetUserForUpdate(ctx, payee.UserID)
+	if err != nil {
+		return err
+	}
+
+	if payee.CoolingOffLimit > 0 {
+		err = checkCoolingOffLimit(ctx, q, payee, accountID, transfer.Amount)
+		if err != nil {
+			return err
+		}
+	}
+
+	return q.CreatePayeeTransfer(ctx, CreatePayeeTransferParams{
+		TransferID:   transfer.ID,
+		PayeeID:      payee.PayeeID,
+		UserID:       payee.UserID,
+		AccountID:    accountID,
+		FirstSavedAt: payee.FirstSavedAt,
+	})
+}
+
+func checkCoolingOffLimit(ctx context.Context, q *Queries, payee *PayeeTransferParams, accountID int64, amount int64) error {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	if account.Owner == payee.UserID {
+		return nil
+	}
+
+	firstSeenAt, err := q.GetRecipientFirstSeenAt(ctx, GetRecipientFirstSeenAtParams{
+		UserID:    payee.UserID,
+		AccountID: accountID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if time.Since(firstSeenAt) >= payee.CoolingOffPeriod {
+		return nil
+	}
+
+	total, err := q.GetPayeeTransferTotal(ctx, GetPayeeTransferTotalParams{
+		UserID:    payee.UserID,
+		AccountID: accountID,
+	})
+	if err != nil {
+		return err
+	}
+
+	remaining := payee.CoolingOffLimit - total
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	if amount > remaining {
+		return &CoolingOffLimitError{PayeeID: payee.PayeeID, AccountID: accountID, Remaining: remaining}
+	}
+
+	return nil
+}
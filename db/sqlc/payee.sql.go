@@ -0,0 +1,225 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: payee.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createPayee = `-- name: CreatePayee :one
+INSERT INTO PAYEES (
+  USER_ID,
+  ACCOUNT_ID,
+  NICKNAME,
+  HOLDER_NAME,
+  FIRST_SAVED_AT
+) VALUES (
+  $1, $2, $3, $4, COALESCE((
+    SELECT MIN(T.FIRST_SAVED_AT) FROM PAYEE_TRANSFERS T
+    WHERE T.USER_ID = $1 AND T.ACCOUNT_ID = $2
+  ), now())
+) RETURNING id, user_id, account_id, nickname, holder_name, created_at, first_saved_at
+`
+
+type CreatePayeeParams struct {
+	UserID     int64  `json:"user_id"`
+	AccountID  int64  `json:"account_id"`
+	Nickname   string `json:"nickname"`
+	HolderName string `json:"holder_name"`
+}
+
+func (q *Queries) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, createPayee,
+		arg.UserID,
+		arg.AccountID,
+		arg.Nickname,
+		arg.HolderName,
+	)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AccountID,
+		&i.Nickname,
+		&i.HolderName,
+		&i.CreatedAt,
+		&i.FirstSavedAt,
+	)
+	return i, err
+}
+
+const createPayeeTransfer = `-- name: CreatePayeeTransfer :exec
+INSERT INTO PAYEE_TRANSFERS (
+  TRANSFER_ID,
+  PAYEE_ID,
+  USER_ID,
+  ACCOUNT_ID,
+  FIRST_SAVED_AT
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+`
+
+type CreatePayeeTransferParams struct {
+	TransferID   int64     `json:"transfer_id"`
+	PayeeID      int64     `json:"payee_id"`
+	UserID       int64     `json:"user_id"`
+	AccountID    int64     `json:"account_id"`
+	FirstSavedAt time.Time `json:"first_saved_at"`
+}
+
+func (q *Queries) CreatePayeeTransfer(ctx context.Context, arg CreatePayeeTransferParams) error {
+	_, err := q.db.ExecContext(ctx, createPayeeTransfer,
+		arg.TransferID,
+		arg.PayeeID,
+		arg.UserID,
+		arg.AccountID,
+		arg.FirstSavedAt,
+	)
+	return err
+}
+
+const deletePayee = `-- name: DeletePayee :exec
+DELETE FROM PAYEES
+WHERE ID = $1
+`
+
+func (q *Queries) DeletePayee(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePayee, id)
+	return err
+}
+
+const getPayee = `-- name: GetPayee :one
+SELECT id, user_id, account_id, nickname, holder_name, created_at, first_saved_at FROM PAYEES
+WHERE ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetPayee(ctx context.Context, id int64) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, getPayee, id)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AccountID,
+		&i.Nickname,
+		&i.HolderName,
+		&i.CreatedAt,
+		&i.FirstSavedAt,
+	)
+	return i, err
+}
+
+const getPayeeByAccount = `-- name: GetPayeeByAccount :one
+SELECT id, user_id, account_id, nickname, holder_name, created_at, first_saved_at FROM PAYEES
+WHERE USER_ID = $1 AND ACCOUNT_ID = $2 LIMIT 1
+`
+
+type GetPayeeByAccountParams struct {
+	UserID    int64 `json:"user_id"`
+	AccountID int64 `json:"account_id"`
+}
+
+func (q *Queries) GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error) {
+	row := q.db.QueryRowContext(ctx, getPayeeByAccount, arg.UserID, arg.AccountID)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.AccountID,
+		&i.Nickname,
+		&i.HolderName,
+		&i.CreatedAt,
+		&i.FirstSavedAt,
+	)
+	return i, err
+}
+
+const getPayeeTransferTotal = `-- name: GetPayeeTransferTotal :one
+SELECT COALESCE(SUM(T.AMOUNT), 0)::bigint FROM PAYEE_TRANSFERS P
+JOIN TRANSFERS T ON T.ID = P.TRANSFER_ID
+WHERE P.USER_ID = $1 AND P.ACCOUNT_ID = $2
+AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+`
+
+type GetPayeeTransferTotalParams struct {
+	UserID    int64 `json:"user_id"`
+	AccountID int64 `json:"account_id"`
+}
+
+func (q *Queries) GetPayeeTransferTotal(ctx context.Context, arg GetPayeeTransferTotalParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getPayeeTransferTotal, arg.UserID, arg.AccountID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getRecipientFirstSeenAt = `-- name: GetRecipientFirstSeenAt :one
+SELECT COALESCE(MIN(S.SEEN_AT), now())::timestamptz FROM (
+  SELECT P.FIRST_SAVED_AT AS SEEN_AT FROM PAYEES P
+  WHERE P.USER_ID = $1 AND P.ACCOUNT_ID = $2
+  UNION ALL
+  SELECT P.FIRST_SAVED_AT FROM PAYEE_TRANSFERS P
+  WHERE P.USER_ID = $1 AND P.ACCOUNT_ID = $2
+  AND P.FIRST_SAVED_AT > '0001-01-01 00:00:00Z'
+  UNION ALL
+  SELECT T.CREATED_AT FROM PAYEE_TRANSFERS P
+  JOIN TRANSFERS T ON T.ID = P.TRANSFER_ID
+  WHERE P.USER_ID = $1 AND P.ACCOUNT_ID = $2
+  AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+) S
+`
+
+type GetRecipientFirstSeenAtParams struct {
+	UserID    int64 `json:"user_id"`
+	AccountID int64 `json:"account_id"`
+}
+
+// The cooling-off period of a recipient starts when the user first saved the account as a payee or
+// first paid it, whichever came first. It starts now for an account the user never saved nor paid.
+func (q *Queries) GetRecipientFirstSeenAt(ctx context.Context, arg GetRecipientFirstSeenAtParams) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getRecipientFirstSeenAt, arg.UserID, arg.AccountID)
+	var column_1 time.Time
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const listPayees = `-- name: ListPayees :many
+SELECT id, user_id, account_id, nickname, holder_name, created_at, first_saved_at FROM PAYEES
+WHERE USER_ID = $1
+ORDER BY NICKNAME, ID
+`
+
+func (q *Queries) ListPayees(ctx context.Context, userID int64) ([]Payee, error) {
+	rows, err := q.db.QueryContext(ctx, listPayees, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Payee{}
+	for rows.Next() {
+		var i Payee
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.AccountID,
+			&i.Nickname,
+			&i.HolderName,
+			&i.CreatedAt,
+			&i.FirstSavedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferTxToPayee(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	payee, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		UserID:     user.ID,
+		AccountID:  account2.ID,
+		Nickname:   util.RandomString(6),
+		HolderName: util.RandomString(6),
+	})
+	require.NoError(t, err)
+
+	params := &PayeeTransferParams{
+		PayeeID:          payee.ID,
+		UserID:           user.ID,
+		FirstSavedAt:     payee.FirstSavedAt,
+		CoolingOffPeriod: time.Hour,
+		CoolingOffLimit:  50,
+	}
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Payee:         params,
+	})
+	require.NoError(t, err)
+
+	total, err := testQueries.GetPayeeTransferTotal(context.Background(), GetPayeeTransferTotalParams{
+		UserID:    user.ID,
+		AccountID: account2.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), total)
+
+	// only 20 is left of the cooling-off allowance
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Payee:         params,
+	})
+	var coolingOffErr *CoolingOffLimitError
+	require.ErrorAs(t, err, &coolingOffErr)
+	require.Equal(t, int64(20), coolingOffErr.Remaining)
+
+	// once the period is over the payee can be paid any amount
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        30,
+		Payee:         &PayeeTransferParams{PayeeID: payee.ID, UserID: user.ID},
+	})
+	require.NoError(t, err)
+}
+
+func TestDeletePayeeKeepsCoolingOff(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	arg := CreatePayeeParams{
+		UserID:     user.ID,
+		AccountID:  account2.ID,
+		Nickname:   util.RandomString(6),
+		HolderName: util.MaskName(util.RandomString(6)),
+	}
+
+	payee, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        40,
+		Payee: &PayeeTransferParams{
+			PayeeID:          payee.ID,
+			UserID:           user.ID,
+			FirstSavedAt:     payee.FirstSavedAt,
+			CoolingOffPeriod: time.Hour,
+			CoolingOffLimit:  50,
+		},
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeletePayee(context.Background(), payee.ID)
+	require.NoError(t, err)
+
+	// saving the payee again neither restarts the cooling-off period nor resets what was paid
+	saved, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEqual(t, payee.ID, saved.ID)
+	require.WithinDuration(t, payee.FirstSavedAt, saved.FirstSavedAt, time.Microsecond)
+
+	total, err := testQueries.GetPayeeTransferTotal(context.Background(), GetPayeeTransferTotalParams{
+		UserID:    user.ID,
+		AccountID: account2.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(40), total)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+		Payee: &PayeeTransferParams{
+			PayeeID:          saved.ID,
+			UserID:           user.ID,
+			FirstSavedAt:     saved.FirstSavedAt,
+			CoolingOffPeriod: time.Hour,
+			CoolingOffLimit:  50,
+		},
+	})
+	var coolingOffErr *CoolingOffLimitError
+	require.ErrorAs(t, err, &coolingOffErr)
+	require.Equal(t, int64(10), coolingOffErr.Remaining)
+}
+
+func TestTransferTxCoolingOffNewRecipient(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 200)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	params := &PayeeTransferParams{
+		UserID:           account1.Owner,
+		CoolingOffPeriod: time.Hour,
+		CoolingOffLimit:  50,
+	}
+
+	// an account that was never saved as a payee is capped from its first payment
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        40,
+		Payee:         params,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        20,
+		Payee:         params,
+	})
+	var coolingOffErr *CoolingOffLimitError
+	require.ErrorAs(t, err, &coolingOffErr)
+	require.Zero(t, coolingOffErr.PayeeID)
+	require.Equal(t, account2.ID, coolingOffErr.AccountID)
+	require.Equal(t, int64(10), coolingOffErr.Remaining)
+
+	// accounts of the user are not new recipients
+	own, err := store.CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    account1.Owner,
+			Currency: util.USD,
+			Type:     util.Savings,
+		},
+		MaxAccounts: 2,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   own.ID,
+		Amount:        100,
+		Payee:         params,
+	})
+	require.NoError(t, err)
+}
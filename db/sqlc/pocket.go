@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrInsufficientPocketFunds = errors.New("pocket does not hold enough funds")
+	ErrAccountHasPocketFunds   = errors.New("account still has money set aside in pockets")
+)
+
+type MovePocketFundsTxParams struct {
+	PocketID int64 `json:"pocket_id"`
+	// Amount is positive to set money aside in the pocket and negative to move it back
+	Amount int64 `json:"amount"`
+}
+
+type MovePocketFundsTxResult struct {
+	Pocket   Pocket         `json:"pocket"`
+	Account  Account        `json:"account"`
+	Movement PocketMovement `json:"movement"`
+}
+
+// MovePocketFundsTx moves money between the main balance of an account and one of its pockets.
+// Money in pockets stays on the account balance but is no longer available to spend, and only
+// money the account actually holds can be set aside, never its overdraft.
+func (store *SQLStore) MovePocketFundsTx(ctx context.Context, arg MovePocketFundsTxParams) (MovePocketFundsTxResult, error) {
+	var result MovePocketFundsTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pocket, err := q.GetPocketForUpdate(ctx, arg.PocketID)
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountPocketBalance(ctx, AddAccountPocketBalanceParams{
+			ID:     pocket.AccountID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = checkAccountsActive(result.Account)
+		if err != nil {
+			return err
+		}
+
+		if arg.Amount > 0 && result.Account.Balance-result.Account.ReservedBalance-result.Account.PocketBalance < 0 {
+			return ErrInsufficientFunds
+		}
+
+		result.Pocket, err = q.AddPocketBalance(ctx, AddPocketBalanceParams{
+			ID:     pocket.ID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.Pocket.Balance < 0 {
+			return ErrInsufficientPocketFunds
+		}
+
+		result.Movement, err = q.CreatePocketMovement(ctx, CreatePocketMovementParams{
+			PocketID: pocket.ID,
+			Amount:   arg.Amount,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// DeletePocketTx deletes a pocket and returns whatever it still holds to the main balance
+func (store *SQLStore) DeletePocketTx(ctx context.Context, pocketID int64) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pocket, err := q.GetPocketForUpdate(ctx, pocketID)
+		if err != nil {
+			return err
+		}
+
+		account, err = q.AddAccountPocketBalance(ctx, AddAccountPocketBalanceParams{
+			ID:     pocket.AccountID,
+			Amount: -pocket.Balance,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.DeletePocket(ctx, pocket.ID)
+	})
+
+	return account, err
+}
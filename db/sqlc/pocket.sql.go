@@ -0,0 +1,190 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: pocket.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const addPocketBalance = `-- name: AddPocketBalance :one
+UPDATE POCKETS
+SET BALANCE = BALANCE + $1
+WHERE ID = $2
+RETURNING id, account_id, name, target_amount, target_date, balance, created_at
+`
+
+type AddPocketBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddPocketBalance(ctx context.Context, arg AddPocketBalanceParams) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, addPocketBalance, arg.Amount, arg.ID)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.TargetDate,
+		&i.Balance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPocket = `-- name: CreatePocket :one
+INSERT INTO POCKETS (
+  ACCOUNT_ID,
+  NAME,
+  TARGET_AMOUNT,
+  TARGET_DATE
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, account_id, name, target_amount, target_date, balance, created_at
+`
+
+type CreatePocketParams struct {
+	AccountID    int64     `json:"account_id"`
+	Name         string    `json:"name"`
+	TargetAmount int64     `json:"target_amount"`
+	TargetDate   time.Time `json:"target_date"`
+}
+
+func (q *Queries) CreatePocket(ctx context.Context, arg CreatePocketParams) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, createPocket,
+		arg.AccountID,
+		arg.Name,
+		arg.TargetAmount,
+		arg.TargetDate,
+	)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.TargetDate,
+		&i.Balance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPocketMovement = `-- name: CreatePocketMovement :one
+INSERT INTO POCKET_MOVEMENTS (
+  POCKET_ID,
+  AMOUNT
+) VALUES (
+  $1, $2
+) RETURNING id, pocket_id, amount, created_at
+`
+
+type CreatePocketMovementParams struct {
+	PocketID int64 `json:"pocket_id"`
+	Amount   int64 `json:"amount"`
+}
+
+func (q *Queries) CreatePocketMovement(ctx context.Context, arg CreatePocketMovementParams) (PocketMovement, error) {
+	row := q.db.QueryRowContext(ctx, createPocketMovement, arg.PocketID, arg.Amount)
+	var i PocketMovement
+	err := row.Scan(
+		&i.ID,
+		&i.PocketID,
+		&i.Amount,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePocket = `-- name: DeletePocket :exec
+DELETE FROM POCKETS
+WHERE ID = $1
+`
+
+func (q *Queries) DeletePocket(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deletePocket, id)
+	return err
+}
+
+const getPocket = `-- name: GetPocket :one
+SELECT id, account_id, name, target_amount, target_date, balance, created_at FROM POCKETS
+WHERE ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetPocket(ctx context.Context, id int64) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, getPocket, id)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.TargetDate,
+		&i.Balance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getPocketForUpdate = `-- name: GetPocketForUpdate :one
+SELECT id, account_id, name, target_amount, target_date, balance, created_at FROM POCKETS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetPocketForUpdate(ctx context.Context, id int64) (Pocket, error) {
+	row := q.db.QueryRowContext(ctx, getPocketForUpdate, id)
+	var i Pocket
+	err := row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.Name,
+		&i.TargetAmount,
+		&i.TargetDate,
+		&i.Balance,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listPockets = `-- name: ListPockets :many
+SELECT id, account_id, name, target_amount, target_date, balance, created_at FROM POCKETS
+WHERE ACCOUNT_ID = $1
+ORDER BY ID
+`
+
+func (q *Queries) ListPockets(ctx context.Context, accountID int64) ([]Pocket, error) {
+	rows, err := q.db.QueryContext(ctx, listPockets, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Pocket{}
+	for rows.Next() {
+		var i Pocket
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Name,
+			&i.TargetAmount,
+			&i.TargetDate,
+			&i.Balance,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
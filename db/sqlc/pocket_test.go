@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPocket(t *testing.T, account Account) Pocket {
+	pocket, err := testQueries.CreatePocket(context.Background(), CreatePocketParams{
+		AccountID:    account.ID,
+		Name:         util.RandomString(6),
+		TargetAmount: 500,
+		TargetDate:   time.Now().AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+	require.Zero(t, pocket.Balance)
+
+	return pocket
+}
+
+func TestMovePocketFundsTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+	pocket := createRandomPocket(t, account1)
+
+	result, err := store.MovePocketFundsTx(context.Background(), MovePocketFundsTxParams{
+		PocketID: pocket.ID,
+		Amount:   70,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Pocket.Balance)
+	require.Equal(t, int64(70), result.Account.PocketBalance)
+	require.Equal(t, int64(100), result.Account.Balance)
+	require.Equal(t, int64(30), AvailableBalance(result.Account))
+
+	// money in pockets cannot be spent
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        40,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// nor set aside twice
+	_, err = store.MovePocketFundsTx(context.Background(), MovePocketFundsTxParams{
+		PocketID: pocket.ID,
+		Amount:   40,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.MovePocketFundsTx(context.Background(), MovePocketFundsTxParams{
+		PocketID: pocket.ID,
+		Amount:   -80,
+	})
+	require.ErrorIs(t, err, ErrInsufficientPocketFunds)
+
+	result, err = store.MovePocketFundsTx(context.Background(), MovePocketFundsTxParams{
+		PocketID: pocket.ID,
+		Amount:   -20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(50), result.Pocket.Balance)
+	require.Equal(t, int64(-20), result.Movement.Amount)
+
+	account, err := store.DeletePocketTx(context.Background(), pocket.ID)
+	require.NoError(t, err)
+	require.Zero(t, account.PocketBalance)
+	require.Equal(t, int64(100), AvailableBalance(account))
+
+	_, err = testQueries.GetPocket(context.Background(), pocket.ID)
+	require.Error(t, err)
+}
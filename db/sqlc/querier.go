@@ -6,25 +6,174 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	AcceptAccountMember(ctx context.Context, arg AcceptAccountMemberParams) (AccountMember, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddAccountPocketBalance(ctx context.Context, arg AddAccountPocketBalanceParams) (Account, error)
+	AddAccountReservedBalance(ctx context.Context, arg AddAccountReservedBalanceParams) (Account, error)
+	AddPocketBalance(ctx context.Context, arg AddPocketBalanceParams) (Pocket, error)
+	CountAccountsByType(ctx context.Context, arg CountAccountsByTypeParams) (int64, error)
+	CountTransferApprovals(ctx context.Context, transferID int64) (int64, error)
+	// Like GetDailyTransferTotal, postings the bank collects are left out
+	CountUserTransfersSince(ctx context.Context, arg CountUserTransfersSinceParams) (int64, error)
+	CountUserTransfersToAccount(ctx context.Context, arg CountUserTransfersToAccountParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountApprover(ctx context.Context, arg CreateAccountApproverParams) (AccountApprover, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateAccountStatusChange(ctx context.Context, arg CreateAccountStatusChangeParams) (AccountStatusChange, error)
+	CreateBillSplit(ctx context.Context, arg CreateBillSplitParams) (BillSplit, error)
+	CreateBillSplitShare(ctx context.Context, arg CreateBillSplitShareParams) (BillSplitShare, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateEscrow(ctx context.Context, arg CreateEscrowParams) (Escrow, error)
+	CreateEscrowSettlement(ctx context.Context, arg CreateEscrowSettlementParams) (EscrowSettlement, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (int64, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateLoan(ctx context.Context, arg CreateLoanParams) (Loan, error)
+	CreateLoanInstallment(ctx context.Context, arg CreateLoanInstallmentParams) (LoanInstallment, error)
+	CreateLoginLockoutEvent(ctx context.Context, arg CreateLoginLockoutEventParams) (LoginLockoutEvent, error)
+	CreateMFARecoveryCode(ctx context.Context, arg CreateMFARecoveryCodeParams) (MfaRecoveryCode, error)
+	CreateMoneyRequest(ctx context.Context, arg CreateMoneyRequestParams) (MoneyRequest, error)
+	CreateMoneyRequestPayment(ctx context.Context, arg CreateMoneyRequestPaymentParams) (MoneyRequestPayment, error)
+	CreateOverdraftAccrual(ctx context.Context, arg CreateOverdraftAccrualParams) (int64, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePayeeTransfer(ctx context.Context, arg CreatePayeeTransferParams) error
+	CreatePocket(ctx context.Context, arg CreatePocketParams) (Pocket, error)
+	CreatePocketMovement(ctx context.Context, arg CreatePocketMovementParams) (PocketMovement, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error)
+	CreateTransferApprovalRequest(ctx context.Context, arg CreateTransferApprovalRequestParams) (TransferApprovalRequest, error)
+	CreateTransferReview(ctx context.Context, arg CreateTransferReviewParams) (TransferReview, error)
+	CreateTransferRiskAssessment(ctx context.Context, arg CreateTransferRiskAssessmentParams) (TransferRiskAssessment, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// Starts an enrolment, or restarts one that was never confirmed. Enabled MFA is left alone.
+	CreateUserMFA(ctx context.Context, arg CreateUserMFAParams) (UserMfa, error)
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
+	DeclineMoneyRequest(ctx context.Context, id int64) (MoneyRequest, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountApprovalPolicy(ctx context.Context, accountID int64) error
+	DeleteAccountApprover(ctx context.Context, arg DeleteAccountApproverParams) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) error
+	DeleteAccountTransferLimit(ctx context.Context, accountID int64) error
+	DeleteLoginThrottle(ctx context.Context, arg DeleteLoginThrottleParams) error
+	DeleteMFARecoveryCodes(ctx context.Context, userID int64) error
+	DeletePayee(ctx context.Context, id int64) error
+	DeletePocket(ctx context.Context, id int64) error
+	EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) (UserMfa, error)
+	ExpireMoneyRequests(ctx context.Context) (int64, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountApprovalPolicy(ctx context.Context, accountID int64) (AccountApprovalPolicy, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAccountTransferLimit(ctx context.Context, accountID int64) (AccountTransferLimit, error)
+	GetBillSplit(ctx context.Context, id int64) (BillSplit, error)
+	// Loan repayments and fees the bank collects are not payments of the user. Funding an escrow is, its
+	// money only passes through the escrow account.
+	GetDailyTransferTotal(ctx context.Context, arg GetDailyTransferTotalParams) (int64, error)
+	GetEffectiveTransferLimit(ctx context.Context, id int64) (GetEffectiveTransferLimitRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetEscrow(ctx context.Context, id int64) (Escrow, error)
+	GetEscrowForUpdate(ctx context.Context, id int64) (Escrow, error)
+	GetEscrowSettlement(ctx context.Context, escrowID int64) (EscrowSettlement, error)
+	GetInterestPosting(ctx context.Context, arg GetInterestPostingParams) (InterestPosting, error)
+	GetLastAccountStatusChange(ctx context.Context, arg GetLastAccountStatusChangeParams) (AccountStatusChange, error)
+	GetLastInterestPosting(ctx context.Context, arg GetLastInterestPostingParams) (InterestPosting, error)
+	GetLoan(ctx context.Context, id int64) (Loan, error)
+	GetLoanForUpdate(ctx context.Context, id int64) (Loan, error)
+	GetLoanInstallmentForUpdate(ctx context.Context, id int64) (LoanInstallment, error)
+	GetLoginLockedUntil(ctx context.Context, arg GetLoginLockedUntilParams) (time.Time, error)
+	GetLoginThrottleForUpdate(ctx context.Context, arg GetLoginThrottleForUpdateParams) (LoginThrottle, error)
+	GetMoneyRequest(ctx context.Context, id int64) (MoneyRequest, error)
+	GetMoneyRequestByLinkToken(ctx context.Context, hashedLinkToken string) (MoneyRequest, error)
+	GetMoneyRequestForUpdate(ctx context.Context, id int64) (MoneyRequest, error)
+	GetMoneyRequestPayment(ctx context.Context, requestID int64) (MoneyRequestPayment, error)
+	GetNextLoanInstallment(ctx context.Context, loanID int64) (LoanInstallment, error)
+	GetPayee(ctx context.Context, id int64) (Payee, error)
+	GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error)
+	GetPayeeTransferTotal(ctx context.Context, arg GetPayeeTransferTotalParams) (int64, error)
+	GetPocket(ctx context.Context, id int64) (Pocket, error)
+	GetPocketForUpdate(ctx context.Context, id int64) (Pocket, error)
+	// The cooling-off period of a recipient starts when the user first saved the account as a payee or
+	// first paid it, whichever came first. It starts now for an account the user never saved nor paid.
+	GetRecipientFirstSeenAt(ctx context.Context, arg GetRecipientFirstSeenAtParams) (time.Time, error)
+	GetSystemAccount(ctx context.Context, arg GetSystemAccountParams) (Account, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferApprovalRequest(ctx context.Context, transferID int64) (TransferApprovalRequest, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, currency string) (TransferLimit, error)
+	GetTransferReview(ctx context.Context, transferID int64) (TransferReview, error)
+	GetTransferRiskAssessment(ctx context.Context, transferID int64) (TransferRiskAssessment, error)
+	GetUserAverageTransferAmount(ctx context.Context, arg GetUserAverageTransferAmountParams) (int64, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserById(ctx context.Context, id int64) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, id int64) (User, error)
+	GetUserMFA(ctx context.Context, userID int64) (UserMfa, error)
+	GetUserPasswordChangedAt(ctx context.Context, username string) (time.Time, error)
+	InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error
+	IsAccountApprover(ctx context.Context, arg IsAccountApproverParams) (bool, error)
+	ListAccountLoans(ctx context.Context, accountID int64) ([]Loan, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	ListAccountStatusChanges(ctx context.Context, accountID int64) ([]AccountStatusChange, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	// Shares of a split with the status of their money request, which tells whether they were paid
+	ListBillSplitShares(ctx context.Context, splitID int64) ([]ListBillSplitSharesRow, error)
+	// Held escrows whose release time has passed, disputed escrows wait for an admin
+	ListDueEscrows(ctx context.Context, arg ListDueEscrowsParams) ([]int64, error)
+	ListDueLoanInstallments(ctx context.Context, arg ListDueLoanInstallmentsParams) ([]LoanInstallment, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExpiredTransferApprovals(ctx context.Context, limit int32) ([]int64, error)
+	ListIncomingMoneyRequests(ctx context.Context, arg ListIncomingMoneyRequestsParams) ([]MoneyRequest, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	// An account accrues for a day when it was not closed at the end of that day, so a run for a past
+	// date still credits an account that has been closed since. Its status then is the last status it
+	// changed to before the end of the day, or the status it had before its first change.
+	ListInterestBearingAccounts(ctx context.Context, arg ListInterestBearingAccountsParams) ([]ListInterestBearingAccountsRow, error)
+	ListInterestRates(ctx context.Context) ([]InterestRate, error)
+	ListLoanInstallments(ctx context.Context, loanID int64) ([]LoanInstallment, error)
+	ListLoginLockoutEvents(ctx context.Context, arg ListLoginLockoutEventsParams) ([]LoginLockoutEvent, error)
+	ListOutgoingMoneyRequests(ctx context.Context, arg ListOutgoingMoneyRequestsParams) ([]MoneyRequest, error)
+	ListOverdraftAccounts(ctx context.Context, arg ListOverdraftAccountsParams) ([]ListOverdraftAccountsRow, error)
+	ListPayees(ctx context.Context, userID int64) ([]Payee, error)
+	ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]ListPendingTransfersRow, error)
+	ListPockets(ctx context.Context, accountID int64) ([]Pocket, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersAwaitingApproval(ctx context.Context, arg ListTransfersAwaitingApprovalParams) ([]ListTransfersAwaitingApprovalRow, error)
+	// Like ListInterestBearingAccounts, accruals count when the account was not closed at the end of
+	// their day (UTC)
+	ListUnpostedInterestAccounts(ctx context.Context, arg ListUnpostedInterestAccountsParams) ([]int64, error)
+	ListUnpostedInterestPeriods(ctx context.Context, accountID int64) ([]time.Time, error)
+	MarkLoanInstallmentPaid(ctx context.Context, id int64) (LoanInstallment, error)
+	MarkLoanInstallmentsOverdue(ctx context.Context, arg MarkLoanInstallmentsOverdueParams) ([]LoanInstallment, error)
+	RepayLoanPrincipal(ctx context.Context, arg RepayLoanPrincipalParams) (Loan, error)
+	// Finds the account that receives payments sent to a username, email or phone number in a currency.
+	// Checking accounts are preferred over savings accounts, then the oldest account. Emails only match
+	// once verified, anyone can sign up with an address they do not own.
+	ResolveRecipientAlias(ctx context.Context, arg ResolveRecipientAliasParams) (ResolveRecipientAliasRow, error)
+	RestartTransferApprovalWindow(ctx context.Context, transferID int64) (TransferApprovalRequest, error)
+	SumInterestAccruals(ctx context.Context, arg SumInterestAccrualsParams) (int64, error)
+	SumOverdraftAccruals(ctx context.Context, arg SumOverdraftAccrualsParams) (int64, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountOverdraft(ctx context.Context, arg UpdateAccountOverdraftParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateEscrowStatus(ctx context.Context, arg UpdateEscrowStatusParams) (Escrow, error)
+	UpdateMoneyRequestStatus(ctx context.Context, arg UpdateMoneyRequestStatusParams) (MoneyRequest, error)
+	UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error)
+	// The time comes from the server like the issue times of tokens, which are compared with it.
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertAccountApprovalPolicy(ctx context.Context, arg UpsertAccountApprovalPolicyParams) (AccountApprovalPolicy, error)
+	UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (AccountTransferLimit, error)
+	UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	UpsertLoginThrottle(ctx context.Context, arg UpsertLoginThrottleParams) (LoginThrottle, error)
+	UseMFARecoveryCode(ctx context.Context, arg UseMFARecoveryCodeParams) (MfaRecoveryCode, error)
+	// Fails when the step was already used, so each code is accepted once.
+	UseUserMFAStep(ctx context.Context, arg UseUserMFAStepParams) (UserMfa, error)
+	// Fails when the token is unknown, expired or already used, so each token is accepted once.
+	UseUserToken(ctx context.Context, arg UseUserTokenParams) (UserToken, error)
+	// Only verifies the email the token was sent to, a changed email stays unverified.
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+var ErrTransferNotPending = errors.New("transfer is not pending")
+
+type ReviewTransferTxParams struct {
+	TransferID int64  `json:"transfer_id"`
+	ReviewerID int64  `json:"reviewer_id"`
+	Comment    string `json:"comment"`
+}
+
+type ReviewTransferTxResult struct {
+	TransferTxResult
+	Review TransferReview `json:"review"`
+	// ApprovalRequest is set when the transfer still has to be approved by the account approvers
+	ApprovalRequest *TransferApprovalRequest `json:"approval_request,omitempty"`
+}
+
+// ApproveTransferTx releases the reserved amount of a pending transfer and posts it. A transfer
+// that is over the approval threshold of its account is handed to the approvers instead.
+func (store *SQLStore) ApproveTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ReviewTransferTxResult, error) {
+	var result ReviewTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Review, err = reviewTransfer(ctx, q, arg, ReviewDecisionApproved)
+		if err != nil {
+			return err
+		}
+
+		request, err := q.RestartTransferApprovalWindow(ctx, arg.TransferID)
+		if err == nil {
+			result.ApprovalRequest = &request
+			result.Transfer, err = q.UpdateTransferStatus(ctx, UpdateTransferStatusParams{
+				ID:     arg.TransferID,
+				Status: TransferStatusAwaitingApproval,
+			})
+			return err
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		return completeHeldTransfer(ctx, q, arg.TransferID, &result.TransferTxResult)
+	})
+
+	return result, err
+}
+
+// RejectTransferTx releases the reserved amount of a pending transfer without moving any money
+func (store *SQLStore) RejectTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ReviewTransferTxResult, error) {
+	var result ReviewTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Review, err = reviewTransfer(ctx, q, arg, ReviewDecisionRejected)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, result.FromAccount, err = releaseHeldTransfer(ctx, q, arg.TransferID, TransferStatusRejected)
+		return err
+	})
+
+	return result, err
+}
+
+// reviewTransfer locks the transfer so that it can only be reviewed once
+func reviewTransfer(ctx context.Context, q *Queries, arg ReviewTransferTxParams, decision string) (TransferReview, error) {
+	transfer, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+	if err != nil {
+		return TransferReview{}, err
+	}
+
+	if transfer.Status != TransferStatusPending {
+		return TransferReview{}, ErrTransferNotPending
+	}
+
+	return q.CreateTransferReview(ctx, CreateTransferReviewParams{
+		TransferID: arg.TransferID,
+		ReviewerID: arg.ReviewerID,
+		Decision:   decision,
+		Comment:    arg.Comment,
+	})
+}
@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createPendingTransfer(t *testing.T, store Store, fromAccount Account, toAccount Account, amount int64) Transfer {
+	result, err := store.FlagTransferTx(context.Background(), FlagTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		Status:        TransferStatusPending,
+		RiskDecision:  "review",
+		MatchedRules:  []string{"amount_spike"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusPending, result.Transfer.Status)
+
+	return result.Transfer
+}
+
+func TestFlagTransferTxReservesBalance(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	createPendingTransfer(t, store, account1, account2, 60)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, int64(60), updatedAccount1.ReservedBalance)
+
+	// reserved money can neither be reserved again nor spent
+	_, err = store.FlagTransferTx(context.Background(), FlagTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        60,
+		Status:        TransferStatusPending,
+		RiskDecision:  "review",
+		MatchedRules:  []string{},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        60,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	pending, err := testQueries.ListPendingTransfers(context.Background(), ListPendingTransfersParams{Limit: 1000})
+	require.NoError(t, err)
+	require.NotEmpty(t, pending)
+	for _, transfer := range pending {
+		require.Equal(t, TransferStatusPending, transfer.Status)
+	}
+}
+
+func TestApproveTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	reviewer := createRandomUser(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+	amount := int64(60)
+
+	transfer := createPendingTransfer(t, store, account1, account2, amount)
+
+	arg := ReviewTransferTxParams{
+		TransferID: transfer.ID,
+		ReviewerID: reviewer.ID,
+		Comment:    "looks fine",
+	}
+
+	result, err := store.ApproveTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, transfer.ID, result.Transfer.ID)
+	require.Equal(t, TransferStatusCompleted, result.Transfer.Status)
+	require.Equal(t, ReviewDecisionApproved, result.Review.Decision)
+	require.Equal(t, reviewer.ID, result.Review.ReviewerID)
+	require.Equal(t, arg.Comment, result.Review.Comment)
+
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, amount, result.ToEntry.Amount)
+	require.Equal(t, account1.Balance-amount, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.ReservedBalance)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	// a transfer can only be reviewed once
+	_, err = store.RejectTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestApproveTransferTxAwaitingApproval(t *testing.T) {
+	store := NewStore(testDB)
+	reviewer := createRandomUser(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+	approver := createRandomApprover(t, account1)
+	amount := int64(60)
+
+	flagged, err := store.FlagTransferTx(context.Background(), FlagTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		Status:        TransferStatusPending,
+		RiskDecision:  "review",
+		MatchedRules:  []string{"amount_spike"},
+		Approval: &PendingApprovalParams{
+			InitiatedBy:       account1.Owner,
+			RequiredApprovals: 1,
+			ApprovalWindow:    time.Hour,
+		},
+	})
+	require.NoError(t, err)
+
+	// the pending transfer is not shown to the approvers before it is reviewed
+	awaiting, err := testQueries.ListTransfersAwaitingApproval(context.Background(), ListTransfersAwaitingApprovalParams{
+		ApproverID: approver.ID,
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, awaiting)
+
+	result, err := store.ApproveTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: flagged.Transfer.ID,
+		ReviewerID: reviewer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusAwaitingApproval, result.Transfer.Status)
+	require.NotNil(t, result.ApprovalRequest)
+	require.WithinDuration(t, time.Now().Add(time.Hour), result.ApprovalRequest.ExpiresAt, time.Minute)
+	require.Empty(t, result.FromEntry)
+
+	account, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, account.Balance)
+	require.Equal(t, amount, account.ReservedBalance)
+
+	decided, err := store.DecideTransferApprovalTx(context.Background(), DecideTransferApprovalTxParams{
+		TransferID: flagged.Transfer.ID,
+		ApproverID: approver.ID,
+		Decision:   ApprovalDecisionApproved,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusCompleted, decided.Transfer.Status)
+	require.Equal(t, account1.Balance-amount, decided.FromAccount.Balance)
+}
+
+func TestRejectTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	reviewer := createRandomUser(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	transfer := createPendingTransfer(t, store, account1, account2, 60)
+
+	result, err := store.RejectTransferTx(context.Background(), ReviewTransferTxParams{
+		TransferID: transfer.ID,
+		ReviewerID: reviewer.ID,
+		Comment:    "customer did not confirm",
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusRejected, result.Transfer.Status)
+	require.Equal(t, ReviewDecisionRejected, result.Review.Decision)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.ReservedBalance)
+
+	review, err := testQueries.GetTransferReview(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, result.Review.ID, review.ID)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{AccountID: account2.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
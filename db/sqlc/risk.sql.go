@@ -0,0 +1,57 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: risk.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+const createTransferRiskAssessment = `-- name: CreateTransferRiskAssessment :one
+INSERT INTO TRANSFER_RISK_ASSESSMENTS (
+  TRANSFER_ID,
+  DECISION,
+  MATCHED_RULES
+) VALUES (
+  $1, $2, $3
+) RETURNING transfer_id, decision, matched_rules, created_at
+`
+
+type CreateTransferRiskAssessmentParams struct {
+	TransferID   int64    `json:"transfer_id"`
+	Decision     string   `json:"decision"`
+	MatchedRules []string `json:"matched_rules"`
+}
+
+func (q *Queries) CreateTransferRiskAssessment(ctx context.Context, arg CreateTransferRiskAssessmentParams) (TransferRiskAssessment, error) {
+	row := q.db.QueryRowContext(ctx, createTransferRiskAssessment, arg.TransferID, arg.Decision, pq.Array(arg.MatchedRules))
+	var i TransferRiskAssessment
+	err := row.Scan(
+		&i.TransferID,
+		&i.Decision,
+		pq.Array(&i.MatchedRules),
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferRiskAssessment = `-- name: GetTransferRiskAssessment :one
+SELECT transfer_id, decision, matched_rules, created_at FROM TRANSFER_RISK_ASSESSMENTS
+WHERE TRANSFER_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferRiskAssessment(ctx context.Context, transferID int64) (TransferRiskAssessment, error) {
+	row := q.db.QueryRowContext(ctx, getTransferRiskAssessment, transferID)
+	var i TransferRiskAssessment
+	err := row.Scan(
+		&i.TransferID,
+		&i.Decision,
+		pq.Array(&i.MatchedRules),
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTransferRiskAssessment(t *testing.T) {
+	transfer := createRandomTransfer(t)
+
+	arg := CreateTransferRiskAssessmentParams{
+		TransferID:   transfer.ID,
+		Decision:     "review",
+		MatchedRules: []string{"velocity", "amount_spike"},
+	}
+
+	assessment1, err := testQueries.CreateTransferRiskAssessment(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.TransferID, assessment1.TransferID)
+	require.Equal(t, arg.Decision, assessment1.Decision)
+	require.Equal(t, arg.MatchedRules, assessment1.MatchedRules)
+	require.NotZero(t, assessment1.CreatedAt)
+
+	assessment2, err := testQueries.GetTransferRiskAssessment(context.Background(), transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, assessment1.Decision, assessment2.Decision)
+	require.Equal(t, assessment1.MatchedRules, assessment2.MatchedRules)
+	require.WithinDuration(t, assessment1.CreatedAt, assessment2.CreatedAt, time.Second)
+}
+
+func TestFlagTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 100)
+
+	result, err := store.FlagTransferTx(context.Background(), FlagTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Status:        TransferStatusBlocked,
+		RiskDecision:  "block",
+		MatchedRules:  []string{"velocity"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferStatusBlocked, result.Transfer.Status)
+	require.Equal(t, result.Transfer.ID, result.RiskAssessment.TransferID)
+	require.Equal(t, []string{"velocity"}, result.RiskAssessment.MatchedRules)
+
+	// no money is moved for flagged transfers
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{AccountID: account1.ID, Limit: 5})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	// blocked transfers are not part of the history of the user
+	count, err := testQueries.CountUserTransfersSince(context.Background(), CountUserTransfersSinceParams{
+		Owner: account1.Owner,
+		Since: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestUserTransferHistory(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD, 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	amounts := []int64{10, 20, 30}
+	for _, amount := range amounts {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+			RiskDecision:  "allow",
+			MatchedRules:  []string{},
+		})
+		require.NoError(t, err)
+	}
+
+	count, err := testQueries.CountUserTransfersSince(context.Background(), CountUserTransfersSinceParams{
+		Owner: account1.Owner,
+		Since: time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(amounts)), count)
+
+	count, err = testQueries.CountUserTransfersToAccount(context.Background(), CountUserTransfersToAccountParams{
+		Owner:       account1.Owner,
+		ToAccountID: account2.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(len(amounts)), count)
+
+	average, err := testQueries.GetUserAverageTransferAmount(context.Background(), GetUserAverageTransferAmountParams{
+		Owner:    account1.Owner,
+		Currency: util.USD,
+		Since:    time.Now().Add(-time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(20), average)
+}
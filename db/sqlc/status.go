@@ -0,0 +1,102 @@
+package db
+
+// Transfer statuses
+const (
+	TransferStatusCompleted        = "completed"
+	TransferStatusPending          = "pending"
+	TransferStatusAwaitingApproval = "awaiting_approval"
+	TransferStatusRejected         = "rejected"
+	TransferStatusDeclined         = "declined"
+	TransferStatusExpired          = "expired"
+	TransferStatusBlocked          = "blocked"
+)
+
+// Review decisions
+const (
+	ReviewDecisionApproved = "approved"
+	ReviewDecisionRejected = "rejected"
+)
+
+// Approval decisions
+const (
+	ApprovalDecisionApproved = "approved"
+	ApprovalDecisionDeclined = "declined"
+)
+
+// Account member roles
+const (
+	AccountRoleOwner   = "owner"
+	AccountRoleCoOwner = "co_owner"
+	AccountRoleViewer  = "viewer"
+)
+
+// Account member statuses
+const (
+	MemberStatusInvited = "invited"
+	MemberStatusActive  = "active"
+)
+
+// Account statuses
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+// Actors of account status changes
+const (
+	AccountStatusChangeByOwner = "owner"
+	AccountStatusChangeByAdmin = "admin"
+)
+
+// System account purposes
+const (
+	SystemAccountInterestExpense = "interest_expense"
+	SystemAccountLoanFunding     = "loan_funding"
+	SystemAccountFeeIncome       = "fee_income"
+	SystemAccountEscrow          = "escrow"
+)
+
+// SystemUsername is the user that owns the system accounts and acts for background jobs
+const SystemUsername = "system"
+
+// Loan statuses
+const (
+	LoanStatusActive  = "active"
+	LoanStatusPaidOff = "paid_off"
+)
+
+// Loan installment statuses
+const (
+	InstallmentStatusScheduled = "scheduled"
+	InstallmentStatusOverdue   = "overdue"
+	InstallmentStatusPaid      = "paid"
+)
+
+// Money request statuses
+const (
+	MoneyRequestStatusPending  = "pending"
+	MoneyRequestStatusPaid     = "paid"
+	MoneyRequestStatusDeclined = "declined"
+	MoneyRequestStatusExpired  = "expired"
+)
+
+// Escrow statuses
+const (
+	EscrowStatusHeld     = "held"
+	EscrowStatusDisputed = "disputed"
+	EscrowStatusReleased = "released"
+	EscrowStatusRefunded = "refunded"
+)
+
+// Two-factor authentication statuses
+const (
+	MFAStatusPending = "pending"
+	MFAStatusEnabled = "enabled"
+)
+
+// User token purposes
+const (
+	UserTokenPurposeVerifyEmail   = "verify_email"
+	UserTokenPurposeResetPassword = "reset_password"
+)
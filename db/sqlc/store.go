@@ -3,12 +3,37 @@ package db
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 )
 
 type Store interface {
 	Querier
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	FlagTransferTx(ctx context.Context, arg FlagTransferTxParams) (FlagTransferTxResult, error)
+	ApproveTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ReviewTransferTxResult, error)
+	RejectTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ReviewTransferTxResult, error)
+	RequestTransferApprovalTx(ctx context.Context, arg RequestTransferApprovalTxParams) (RequestTransferApprovalTxResult, error)
+	DecideTransferApprovalTx(ctx context.Context, arg DecideTransferApprovalTxParams) (DecideTransferApprovalTxResult, error)
+	ExpireTransferApprovalTx(ctx context.Context, transferID int64) (Transfer, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	CapitalizeInterestTx(ctx context.Context, arg CapitalizeInterestTxParams) (CapitalizeInterestTxResult, error)
+	OriginateLoanTx(ctx context.Context, arg OriginateLoanTxParams) (OriginateLoanTxResult, error)
+	CollectLoanInstallmentTx(ctx context.Context, installmentID int64) (CollectLoanInstallmentTxResult, error)
+	MovePocketFundsTx(ctx context.Context, arg MovePocketFundsTxParams) (MovePocketFundsTxResult, error)
+	DeletePocketTx(ctx context.Context, pocketID int64) (Account, error)
+	PayMoneyRequestTx(ctx context.Context, arg PayMoneyRequestTxParams) (PayMoneyRequestTxResult, error)
+	CreateBillSplitTx(ctx context.Context, arg CreateBillSplitTxParams) (CreateBillSplitTxResult, error)
+	CreateEscrowTx(ctx context.Context, arg CreateEscrowTxParams) (CreateEscrowTxResult, error)
+	ChangeEscrowStatusTx(ctx context.Context, arg ChangeEscrowStatusTxParams) (ChangeEscrowStatusTxResult, error)
+	RecordLoginFailureTx(ctx context.Context, arg RecordLoginFailureTxParams) (RecordLoginFailureTxResult, error)
+	UnlockLoginTx(ctx context.Context, arg UnlockLoginTxParams) (LoginLockoutEvent, error)
+	ConfirmUserMFATx(ctx context.Context, arg ConfirmUserMFATxParams) (UserMfa, error)
+	IssueUserTokenTx(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
+	VerifyEmailTx(ctx context.Context, hashedToken string) (User, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error)
 }
 
 type SQLStore struct {
@@ -46,6 +71,13 @@ type TransferTxParams struct {
 	ToAccountID   int64 `json:"to_account_id"`
 	FromAccountID int64 `json:"from_account_id"`
 	Amount        int64 `json:"amount"`
+	// EnforceLimits checks the transfer against the limits of the from account owner
+	EnforceLimits bool `json:"enforce_limits"`
+	// RiskDecision and MatchedRules are persisted with the transfer when it has been assessed
+	RiskDecision string   `json:"risk_decision"`
+	MatchedRules []string `json:"matched_rules"`
+	// Payee records the transfer against its recipient for the cooling-off limit
+	Payee *PayeeTransferParams `json:"payee,omitempty"`
 }
 
 type TransferTxResult struct {
@@ -56,6 +88,8 @@ type TransferTxResult struct {
 	FromEntry   Entry    `json:"from_entry"`
 }
 
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
 // var txKey = struct{}{}
 
 type AddMoneyParams struct {
@@ -92,65 +126,122 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
+		return runTransfer(ctx, q, arg, &result)
+	})
 
-		// txName := ctx.Value(txKey)
+	return result, err
+}
 
-		// fmt.Println(txName, "create transfer")
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
+// runTransfer creates and posts a completed transfer inside the transaction of q
+func runTransfer(ctx context.Context, q *Queries, arg TransferTxParams, result *TransferTxResult) error {
+	var err error
 
-		// fmt.Println(txName, "create entry 1")
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+	if arg.EnforceLimits {
+		err = checkTransferLimits(ctx, q, arg.FromAccountID, arg.Amount)
 		if err != nil {
 			return err
 		}
+	}
+
+	// txName := ctx.Value(txKey)
 
-		// fmt.Println(txName, "create entry 2")
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+	// fmt.Println(txName, "create transfer")
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Status:        TransferStatusCompleted,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = recordPayeeTransfer(ctx, q, arg.Payee, result.Transfer)
+	if err != nil {
+		return err
+	}
+
+	if arg.RiskDecision != "" {
+		_, err = q.CreateTransferRiskAssessment(ctx, CreateTransferRiskAssessmentParams{
+			TransferID:   result.Transfer.ID,
+			Decision:     arg.RiskDecision,
+			MatchedRules: arg.MatchedRules,
 		})
 		if err != nil {
 			return err
 		}
+	}
 
-		//Update account balance
-
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(AddMoneyParams{
-				ctx:        ctx,
-				q:          q,
-				accountID1: arg.FromAccountID,
-				amount1:    -arg.Amount,
-				accountID2: arg.ToAccountID,
-				amount2:    arg.Amount,
-			})
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(AddMoneyParams{
-				ctx:        ctx,
-				q:          q,
-				accountID1: arg.ToAccountID,
-				amount1:    arg.Amount,
-				accountID2: arg.FromAccountID,
-				amount2:    -arg.Amount,
-			})
-		}
-		if err != nil {
-			return err
-		}
+	err = postTransfer(ctx, q, result.Transfer, result)
+	if err != nil {
+		return err
+	}
+
+	err = checkAccountsActive(result.FromAccount, result.ToAccount)
+	if err != nil {
+		return err
+	}
+
+	return checkAvailableBalance(result.FromAccount)
+}
 
-		return nil
+// postTransfer creates the entries of a transfer and moves the money between both accounts.
+// Account rows are updated in ID order to avoid deadlocks.
+func postTransfer(ctx context.Context, q *Queries, transfer Transfer, result *TransferTxResult) error {
+	var err error
+
+	// fmt.Println(txName, "create entry 1")
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: transfer.FromAccountID,
+		Amount:    -transfer.Amount,
 	})
+	if err != nil {
+		return err
+	}
 
-	return result, err
+	// fmt.Println(txName, "create entry 2")
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: transfer.ToAccountID,
+		Amount:    transfer.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	//Update account balance
+
+	if transfer.FromAccountID < transfer.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(AddMoneyParams{
+			ctx:        ctx,
+			q:          q,
+			accountID1: transfer.FromAccountID,
+			amount1:    -transfer.Amount,
+			accountID2: transfer.ToAccountID,
+			amount2:    transfer.Amount,
+		})
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(AddMoneyParams{
+			ctx:        ctx,
+			q:          q,
+			accountID1: transfer.ToAccountID,
+			amount1:    transfer.Amount,
+			accountID2: transfer.FromAccountID,
+			amount2:    -transfer.Amount,
+		})
+	}
+	return err
+}
+
+// AvailableBalance is what the account can still spend, including its overdraft and excluding
+// the money set aside in pockets
+func AvailableBalance(account Account) int64 {
+	return account.Balance - account.ReservedBalance - account.PocketBalance + account.OverdraftLimit
+}
+
+// checkAvailableBalance must be called after the account row has been updated in the transaction
+func checkAvailableBalance(account Account) error {
+	if AvailableBalance(account) < 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
 }
@@ -4,14 +4,16 @@ import (
 	"context"
 	"testing"
 
+	"github.com/khorsl/simple_bank/util"
 	"github.com/stretchr/testify/require"
 )
 
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1, _, _ := createRandomAccount(t)
-	account2, _, _ := createRandomAccount(t)
+	// transfers cannot overdraw the from account
+	account1 := createRandomAccountWithCurrency(t, util.USD, 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 1000)
 	// fmt.Println(">> before balance: ", account1.Balance, account2.Balance)
 
 	// run n concurrent transfer transactions
@@ -112,8 +114,8 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1, _, _ := createRandomAccount(t)
-	account2, _, _ := createRandomAccount(t)
+	account1 := createRandomAccountWithCurrency(t, util.USD, 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 1000)
 
 	// run n concurrent transfer transactions
 	n := 10
@@ -157,3 +159,22 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account1.Balance, updatedAccount1.Balance)
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD, 10)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the transaction is rolled back
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
@@ -7,26 +7,81 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const countUserTransfersSince = `-- name: CountUserTransfersSince :one
+SELECT COUNT(*) FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = $1
+AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+AND NOT EXISTS (
+  SELECT 1 FROM SYSTEM_ACCOUNTS S
+  WHERE S.ACCOUNT_ID = T.TO_ACCOUNT_ID AND S.PURPOSE <> 'escrow'
 )
+AND T.CREATED_AT > $2
+`
+
+type CountUserTransfersSinceParams struct {
+	Owner int64     `json:"owner"`
+	Since time.Time `json:"since"`
+}
+
+// Like GetDailyTransferTotal, postings the bank collects are left out
+func (q *Queries) CountUserTransfersSince(ctx context.Context, arg CountUserTransfersSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserTransfersSince, arg.Owner, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUserTransfersToAccount = `-- name: CountUserTransfersToAccount :one
+SELECT COUNT(*) FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = $1
+AND T.TO_ACCOUNT_ID = $2
+AND T.STATUS = 'completed'
+`
+
+type CountUserTransfersToAccountParams struct {
+	Owner       int64 `json:"owner"`
+	ToAccountID int64 `json:"to_account_id"`
+}
+
+func (q *Queries) CountUserTransfersToAccount(ctx context.Context, arg CountUserTransfersToAccountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUserTransfersToAccount, arg.Owner, arg.ToAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO TRANSFERS (
   FROM_ACCOUNT_ID,
   TO_ACCOUNT_ID,
-  AMOUNT
+  AMOUNT,
+  STATUS
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, to_account_id, from_account_id, amount, created_at
+  $1, $2, $3, $4
+) RETURNING id, to_account_id, from_account_id, amount, created_at, status
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Status        string `json:"status"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Status,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +89,13 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.FromAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, to_account_id, from_account_id, amount, created_at FROM TRANSFERS
+SELECT id, to_account_id, from_account_id, amount, created_at, status FROM TRANSFERS
 WHERE ID = $1
 LIMIT 1
 `
@@ -53,12 +109,115 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.FromAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, to_account_id, from_account_id, amount, created_at, status FROM TRANSFERS
+WHERE ID = $1
+LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.ToAccountID,
+		&i.FromAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Status,
 	)
 	return i, err
 }
 
+const getUserAverageTransferAmount = `-- name: GetUserAverageTransferAmount :one
+SELECT COALESCE(AVG(T.AMOUNT), 0)::bigint AS AVERAGE FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = $1
+AND A.CURRENCY = $2
+AND T.STATUS = 'completed'
+AND NOT EXISTS (
+  SELECT 1 FROM SYSTEM_ACCOUNTS S
+  WHERE S.ACCOUNT_ID = T.TO_ACCOUNT_ID AND S.PURPOSE <> 'escrow'
+)
+AND T.CREATED_AT > $3
+`
+
+type GetUserAverageTransferAmountParams struct {
+	Owner    int64     `json:"owner"`
+	Currency string    `json:"currency"`
+	Since    time.Time `json:"since"`
+}
+
+func (q *Queries) GetUserAverageTransferAmount(ctx context.Context, arg GetUserAverageTransferAmountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserAverageTransferAmount, arg.Owner, arg.Currency, arg.Since)
+	var average int64
+	err := row.Scan(&average)
+	return average, err
+}
+
+const listPendingTransfers = `-- name: ListPendingTransfers :many
+SELECT t.id, t.to_account_id, t.from_account_id, t.amount, t.created_at, t.status, R.MATCHED_RULES FROM TRANSFERS T
+LEFT JOIN TRANSFER_RISK_ASSESSMENTS R ON R.TRANSFER_ID = T.ID
+WHERE T.STATUS = 'pending'
+ORDER BY T.ID
+LIMIT $1
+OFFSET $2
+`
+
+type ListPendingTransfersParams struct {
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type ListPendingTransfersRow struct {
+	ID            int64     `json:"id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	FromAccountID int64     `json:"from_account_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	Status        string    `json:"status"`
+	MatchedRules  []string  `json:"matched_rules"`
+}
+
+func (q *Queries) ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]ListPendingTransfersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPendingTransfers, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPendingTransfersRow{}
+	for rows.Next() {
+		var i ListPendingTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ToAccountID,
+			&i.FromAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Status,
+			pq.Array(&i.MatchedRules),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, to_account_id, from_account_id, amount, created_at FROM TRANSFERS
+SELECT id, to_account_id, from_account_id, amount, created_at, status FROM TRANSFERS
 WHERE FROM_ACCOUNT_ID = $1
 OR TO_ACCOUNT_ID = $2
 ORDER BY ID
@@ -93,6 +252,7 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.FromAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Status,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +266,29 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferStatus = `-- name: UpdateTransferStatus :one
+UPDATE TRANSFERS
+SET STATUS = $2
+WHERE ID = $1
+RETURNING id, to_account_id, from_account_id, amount, created_at, status
+`
+
+type UpdateTransferStatusParams struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+func (q *Queries) UpdateTransferStatus(ctx context.Context, arg UpdateTransferStatusParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferStatus, arg.ID, arg.Status)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.ToAccountID,
+		&i.FromAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Status,
+	)
+	return i, err
+}
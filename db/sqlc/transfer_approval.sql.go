@@ -0,0 +1,350 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: transfer_approval.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const countTransferApprovals = `-- name: CountTransferApprovals :one
+SELECT COUNT(*) FROM TRANSFER_APPROVALS
+WHERE TRANSFER_ID = $1 AND DECISION = 'approved'
+`
+
+func (q *Queries) CountTransferApprovals(ctx context.Context, transferID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransferApprovals, transferID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createAccountApprover = `-- name: CreateAccountApprover :one
+INSERT INTO ACCOUNT_APPROVERS (
+  ACCOUNT_ID,
+  USER_ID
+) VALUES (
+  $1, $2
+) RETURNING account_id, user_id, created_at
+`
+
+type CreateAccountApproverParams struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) CreateAccountApprover(ctx context.Context, arg CreateAccountApproverParams) (AccountApprover, error) {
+	row := q.db.QueryRowContext(ctx, createAccountApprover, arg.AccountID, arg.UserID)
+	var i AccountApprover
+	err := row.Scan(&i.AccountID, &i.UserID, &i.CreatedAt)
+	return i, err
+}
+
+const createTransferApproval = `-- name: CreateTransferApproval :one
+INSERT INTO TRANSFER_APPROVALS (
+  TRANSFER_ID,
+  APPROVER_ID,
+  DECISION
+) VALUES (
+  $1, $2, $3
+) RETURNING id, transfer_id, approver_id, decision, created_at
+`
+
+type CreateTransferApprovalParams struct {
+	TransferID int64  `json:"transfer_id"`
+	ApproverID int64  `json:"approver_id"`
+	Decision   string `json:"decision"`
+}
+
+func (q *Queries) CreateTransferApproval(ctx context.Context, arg CreateTransferApprovalParams) (TransferApproval, error) {
+	row := q.db.QueryRowContext(ctx, createTransferApproval, arg.TransferID, arg.ApproverID, arg.Decision)
+	var i TransferApproval
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.ApproverID,
+		&i.Decision,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createTransferApprovalRequest = `-- name: CreateTransferApprovalRequest :one
+INSERT INTO TRANSFER_APPROVAL_REQUESTS (
+  TRANSFER_ID,
+  INITIATED_BY,
+  REQUIRED_APPROVALS,
+  EXPIRES_AT
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING transfer_id, initiated_by, required_approvals, expires_at, created_at
+`
+
+type CreateTransferApprovalRequestParams struct {
+	TransferID        int64     `json:"transfer_id"`
+	InitiatedBy       int64     `json:"initiated_by"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateTransferApprovalRequest(ctx context.Context, arg CreateTransferApprovalRequestParams) (TransferApprovalRequest, error) {
+	row := q.db.QueryRowContext(ctx, createTransferApprovalRequest,
+		arg.TransferID,
+		arg.InitiatedBy,
+		arg.RequiredApprovals,
+		arg.ExpiresAt,
+	)
+	var i TransferApprovalRequest
+	err := row.Scan(
+		&i.TransferID,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAccountApprovalPolicy = `-- name: DeleteAccountApprovalPolicy :exec
+DELETE FROM ACCOUNT_APPROVAL_POLICIES
+WHERE ACCOUNT_ID = $1
+`
+
+func (q *Queries) DeleteAccountApprovalPolicy(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountApprovalPolicy, accountID)
+	return err
+}
+
+const deleteAccountApprover = `-- name: DeleteAccountApprover :exec
+DELETE FROM ACCOUNT_APPROVERS
+WHERE ACCOUNT_ID = $1 AND USER_ID = $2
+`
+
+type DeleteAccountApproverParams struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) DeleteAccountApprover(ctx context.Context, arg DeleteAccountApproverParams) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountApprover, arg.AccountID, arg.UserID)
+	return err
+}
+
+const getAccountApprovalPolicy = `-- name: GetAccountApprovalPolicy :one
+SELECT account_id, threshold, required_approvals, approval_window_minutes, updated_at FROM ACCOUNT_APPROVAL_POLICIES
+WHERE ACCOUNT_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountApprovalPolicy(ctx context.Context, accountID int64) (AccountApprovalPolicy, error) {
+	row := q.db.QueryRowContext(ctx, getAccountApprovalPolicy, accountID)
+	var i AccountApprovalPolicy
+	err := row.Scan(
+		&i.AccountID,
+		&i.Threshold,
+		&i.RequiredApprovals,
+		&i.ApprovalWindowMinutes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getTransferApprovalRequest = `-- name: GetTransferApprovalRequest :one
+SELECT transfer_id, initiated_by, required_approvals, expires_at, created_at FROM TRANSFER_APPROVAL_REQUESTS
+WHERE TRANSFER_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferApprovalRequest(ctx context.Context, transferID int64) (TransferApprovalRequest, error) {
+	row := q.db.QueryRowContext(ctx, getTransferApprovalRequest, transferID)
+	var i TransferApprovalRequest
+	err := row.Scan(
+		&i.TransferID,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const isAccountApprover = `-- name: IsAccountApprover :one
+SELECT EXISTS (
+  SELECT 1 FROM ACCOUNT_APPROVERS AP
+  JOIN ACCOUNT_MEMBERS M ON M.ACCOUNT_ID = AP.ACCOUNT_ID AND M.USER_ID = AP.USER_ID
+  WHERE AP.ACCOUNT_ID = $1 AND AP.USER_ID = $2
+  AND M.STATUS = 'active'
+)
+`
+
+type IsAccountApproverParams struct {
+	AccountID int64 `json:"account_id"`
+	UserID    int64 `json:"user_id"`
+}
+
+func (q *Queries) IsAccountApprover(ctx context.Context, arg IsAccountApproverParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isAccountApprover, arg.AccountID, arg.UserID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listExpiredTransferApprovals = `-- name: ListExpiredTransferApprovals :many
+SELECT T.ID FROM TRANSFERS T
+JOIN TRANSFER_APPROVAL_REQUESTS R ON R.TRANSFER_ID = T.ID
+WHERE T.STATUS = 'awaiting_approval'
+AND R.EXPIRES_AT <= now()
+ORDER BY T.ID
+LIMIT $1
+`
+
+func (q *Queries) ListExpiredTransferApprovals(ctx context.Context, limit int32) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredTransferApprovals, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAwaitingApproval = `-- name: ListTransfersAwaitingApproval :many
+SELECT t.id, t.to_account_id, t.from_account_id, t.amount, t.created_at, t.status, R.REQUIRED_APPROVALS, R.EXPIRES_AT FROM TRANSFERS T
+JOIN TRANSFER_APPROVAL_REQUESTS R ON R.TRANSFER_ID = T.ID
+JOIN ACCOUNT_APPROVERS AP ON AP.ACCOUNT_ID = T.FROM_ACCOUNT_ID
+WHERE AP.USER_ID = $1
+AND R.INITIATED_BY <> $1
+AND T.STATUS = 'awaiting_approval'
+AND R.EXPIRES_AT > now()
+AND NOT EXISTS (
+  SELECT 1 FROM TRANSFER_APPROVALS A
+  WHERE A.TRANSFER_ID = T.ID AND A.APPROVER_ID = $1
+)
+ORDER BY T.ID
+LIMIT $3
+OFFSET $2
+`
+
+type ListTransfersAwaitingApprovalParams struct {
+	ApproverID int64 `json:"approver_id"`
+	Offset     int32 `json:"offset_"`
+	Limit      int32 `json:"limit_"`
+}
+
+type ListTransfersAwaitingApprovalRow struct {
+	ID                int64     `json:"id"`
+	ToAccountID       int64     `json:"to_account_id"`
+	FromAccountID     int64     `json:"from_account_id"`
+	Amount            int64     `json:"amount"`
+	CreatedAt         time.Time `json:"created_at"`
+	Status            string    `json:"status"`
+	RequiredApprovals int32     `json:"required_approvals"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+func (q *Queries) ListTransfersAwaitingApproval(ctx context.Context, arg ListTransfersAwaitingApprovalParams) ([]ListTransfersAwaitingApprovalRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersAwaitingApproval, arg.ApproverID, arg.Offset, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTransfersAwaitingApprovalRow{}
+	for rows.Next() {
+		var i ListTransfersAwaitingApprovalRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ToAccountID,
+			&i.FromAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Status,
+			&i.RequiredApprovals,
+			&i.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restartTransferApprovalWindow = `-- name: RestartTransferApprovalWindow :one
+UPDATE TRANSFER_APPROVAL_REQUESTS
+SET EXPIRES_AT = now() + (EXPIRES_AT - CREATED_AT)
+WHERE TRANSFER_ID = $1
+RETURNING transfer_id, initiated_by, required_approvals, expires_at, created_at
+`
+
+func (q *Queries) RestartTransferApprovalWindow(ctx context.Context, transferID int64) (TransferApprovalRequest, error) {
+	row := q.db.QueryRowContext(ctx, restartTransferApprovalWindow, transferID)
+	var i TransferApprovalRequest
+	err := row.Scan(
+		&i.TransferID,
+		&i.InitiatedBy,
+		&i.RequiredApprovals,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertAccountApprovalPolicy = `-- name: UpsertAccountApprovalPolicy :one
+INSERT INTO ACCOUNT_APPROVAL_POLICIES (
+  ACCOUNT_ID,
+  THRESHOLD,
+  REQUIRED_APPROVALS,
+  APPROVAL_WINDOW_MINUTES
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (ACCOUNT_ID) DO UPDATE
+SET THRESHOLD = EXCLUDED.THRESHOLD,
+  REQUIRED_APPROVALS = EXCLUDED.REQUIRED_APPROVALS,
+  APPROVAL_WINDOW_MINUTES = EXCLUDED.APPROVAL_WINDOW_MINUTES,
+  UPDATED_AT = now()
+RETURNING account_id, threshold, required_approvals, approval_window_minutes, updated_at
+`
+
+type UpsertAccountApprovalPolicyParams struct {
+	AccountID             int64 `json:"account_id"`
+	Threshold             int64 `json:"threshold"`
+	RequiredApprovals     int32 `json:"required_approvals"`
+	ApprovalWindowMinutes int32 `json:"approval_window_minutes"`
+}
+
+func (q *Queries) UpsertAccountApprovalPolicy(ctx context.Context, arg UpsertAccountApprovalPolicyParams) (AccountApprovalPolicy, error) {
+	row := q.db.QueryRowContext(ctx, upsertAccountApprovalPolicy,
+		arg.AccountID,
+		arg.Threshold,
+		arg.RequiredApprovals,
+		arg.ApprovalWindowMinutes,
+	)
+	var i AccountApprovalPolicy
+	err := row.Scan(
+		&i.AccountID,
+		&i.Threshold,
+		&i.RequiredApprovals,
+		&i.ApprovalWindowMinutes,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
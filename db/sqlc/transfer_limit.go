@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	PerTransactionLimit = "per_transaction"
+	DailyLimit          = "daily"
+)
+
+// LimitExceededError is returned by TransferTx when a transfer would go over
+// the per-transaction or rolling 24-hour limit of the sending user
+type LimitExceededError struct {
+	Limit     string `json:"limit"`
+	Currency  string `json:"currency"`
+	Remaining int64  `json:"remaining_allowance"`
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s transfer limit exceeded: remaining allowance is %d %s", e.Limit, e.Remaining, e.Currency)
+}
+
+// checkTransferLimits must run inside the transfer transaction. The owner of the from account is locked
+// first so that parallel transfers of the same user are serialized and cannot bypass the daily total.
+func checkTransferLimits(ctx context.Context, q *Queries, fromAccountID int64, amount int64) error {
+	fromAccount, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.GetUserForUpdate(ctx, fromAccount.Owner)
+	if err != nil {
+		return err
+	}
+
+	limit, err := q.GetEffectiveTransferLimit(ctx, fromAccountID)
+	if err != nil {
+		return fmt.Errorf("cannot get transfer limit of account %d: %w", fromAccountID, err)
+	}
+
+	total, err := q.GetDailyTransferTotal(ctx, GetDailyTransferTotalParams{
+		Owner:    fromAccount.Owner,
+		Currency: fromAccount.Currency,
+	})
+	if err != nil {
+		return err
+	}
+
+	remaining := RemainingAllowance(limit, total)
+
+	if amount > limit.PerTransactionLimit {
+		return &LimitExceededError{Limit: PerTransactionLimit, Currency: fromAccount.Currency, Remaining: remaining}
+	}
+
+	if amount > remaining {
+		return &LimitExceededError{Limit: DailyLimit, Currency: fromAccount.Currency, Remaining: remaining}
+	}
+
+	return nil
+}
+
+// RemainingAllowance is the largest amount that can still be transferred in a single transfer
+func RemainingAllowance(limit GetEffectiveTransferLimitRow, dailyUsed int64) int64 {
+	remaining := limit.DailyLimit - dailyUsed
+	if remaining < 0 {
+		remaining = 0
+	}
+	if limit.PerTransactionLimit < remaining {
+		remaining = limit.PerTransactionLimit
+	}
+	return remaining
+}
@@ -0,0 +1,146 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: transfer_limit.sql
+
+package db
+
+import (
+	"context"
+)
+
+const deleteAccountTransferLimit = `-- name: DeleteAccountTransferLimit :exec
+DELETE FROM ACCOUNT_TRANSFER_LIMITS
+WHERE ACCOUNT_ID = $1
+`
+
+func (q *Queries) DeleteAccountTransferLimit(ctx context.Context, accountID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteAccountTransferLimit, accountID)
+	return err
+}
+
+const getAccountTransferLimit = `-- name: GetAccountTransferLimit :one
+SELECT account_id, per_transaction_limit, daily_limit, updated_by, updated_at FROM ACCOUNT_TRANSFER_LIMITS
+WHERE ACCOUNT_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetAccountTransferLimit(ctx context.Context, accountID int64) (AccountTransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, getAccountTransferLimit, accountID)
+	var i AccountTransferLimit
+	err := row.Scan(
+		&i.AccountID,
+		&i.PerTransactionLimit,
+		&i.DailyLimit,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getDailyTransferTotal = `-- name: GetDailyTransferTotal :one
+SELECT COALESCE(SUM(T.AMOUNT), 0)::bigint AS TOTAL
+FROM TRANSFERS T
+JOIN ACCOUNTS A ON A.ID = T.FROM_ACCOUNT_ID
+WHERE A.OWNER = $1
+AND A.CURRENCY = $2
+AND T.STATUS IN ('completed', 'pending', 'awaiting_approval')
+AND NOT EXISTS (
+  SELECT 1 FROM SYSTEM_ACCOUNTS S
+  WHERE S.ACCOUNT_ID = T.TO_ACCOUNT_ID AND S.PURPOSE <> 'escrow'
+)
+AND T.CREATED_AT > now() - INTERVAL '24 hours'
+`
+
+type GetDailyTransferTotalParams struct {
+	Owner    int64  `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+// Loan repayments and fees the bank collects are not payments of the user. Funding an escrow is, its
+// money only passes through the escrow account.
+func (q *Queries) GetDailyTransferTotal(ctx context.Context, arg GetDailyTransferTotalParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getDailyTransferTotal, arg.Owner, arg.Currency)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
+
+const getEffectiveTransferLimit = `-- name: GetEffectiveTransferLimit :one
+SELECT
+  COALESCE(ATL.PER_TRANSACTION_LIMIT, TL.PER_TRANSACTION_LIMIT)::bigint AS PER_TRANSACTION_LIMIT,
+  COALESCE(ATL.DAILY_LIMIT, TL.DAILY_LIMIT)::bigint AS DAILY_LIMIT
+FROM ACCOUNTS A
+JOIN TRANSFER_LIMITS TL ON TL.CURRENCY = A.CURRENCY
+LEFT JOIN ACCOUNT_TRANSFER_LIMITS ATL ON ATL.ACCOUNT_ID = A.ID
+WHERE A.ID = $1 LIMIT 1
+`
+
+type GetEffectiveTransferLimitRow struct {
+	PerTransactionLimit int64 `json:"per_transaction_limit"`
+	DailyLimit          int64 `json:"daily_limit"`
+}
+
+func (q *Queries) GetEffectiveTransferLimit(ctx context.Context, id int64) (GetEffectiveTransferLimitRow, error) {
+	row := q.db.QueryRowContext(ctx, getEffectiveTransferLimit, id)
+	var i GetEffectiveTransferLimitRow
+	err := row.Scan(&i.PerTransactionLimit, &i.DailyLimit)
+	return i, err
+}
+
+const getTransferLimit = `-- name: GetTransferLimit :one
+SELECT currency, per_transaction_limit, daily_limit, updated_at FROM TRANSFER_LIMITS
+WHERE CURRENCY = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferLimit(ctx context.Context, currency string) (TransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, getTransferLimit, currency)
+	var i TransferLimit
+	err := row.Scan(
+		&i.Currency,
+		&i.PerTransactionLimit,
+		&i.DailyLimit,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertAccountTransferLimit = `-- name: UpsertAccountTransferLimit :one
+INSERT INTO ACCOUNT_TRANSFER_LIMITS (
+  ACCOUNT_ID,
+  PER_TRANSACTION_LIMIT,
+  DAILY_LIMIT,
+  UPDATED_BY
+) VALUES (
+  $1, $2, $3, $4
+) ON CONFLICT (ACCOUNT_ID) DO UPDATE
+SET PER_TRANSACTION_LIMIT = EXCLUDED.PER_TRANSACTION_LIMIT,
+  DAILY_LIMIT = EXCLUDED.DAILY_LIMIT,
+  UPDATED_BY = EXCLUDED.UPDATED_BY,
+  UPDATED_AT = now()
+RETURNING account_id, per_transaction_limit, daily_limit, updated_by, updated_at
+`
+
+type UpsertAccountTransferLimitParams struct {
+	AccountID           int64 `json:"account_id"`
+	PerTransactionLimit int64 `json:"per_transaction_limit"`
+	DailyLimit          int64 `json:"daily_limit"`
+	UpdatedBy           int64 `json:"updated_by"`
+}
+
+func (q *Queries) UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (AccountTransferLimit, error) {
+	row := q.db.QueryRowContext(ctx, upsertAccountTransferLimit,
+		arg.AccountID,
+		arg.PerTransactionLimit,
+		arg.DailyLimit,
+		arg.UpdatedBy,
+	)
+	var i AccountTransferLimit
+	err := row.Scan(
+		&i.AccountID,
+		&i.PerTransactionLimit,
+		&i.DailyLimit,
+		&i.UpdatedBy,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
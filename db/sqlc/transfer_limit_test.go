@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetTransferLimit(t *testing.T) {
+	limit, err := testQueries.GetTransferLimit(context.Background(), util.USD)
+	require.NoError(t, err)
+	require.Equal(t, util.USD, limit.Currency)
+	require.Positive(t, limit.PerTransactionLimit)
+	require.GreaterOrEqual(t, limit.DailyLimit, limit.PerTransactionLimit)
+}
+
+func TestUpsertAccountTransferLimit(t *testing.T) {
+	admin := createRandomUser(t)
+	account := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	arg := UpsertAccountTransferLimitParams{
+		AccountID:           account.ID,
+		PerTransactionLimit: 100,
+		DailyLimit:          200,
+		UpdatedBy:           admin.ID,
+	}
+
+	limit1, err := testQueries.UpsertAccountTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, limit1.AccountID)
+	require.Equal(t, arg.PerTransactionLimit, limit1.PerTransactionLimit)
+	require.Equal(t, arg.DailyLimit, limit1.DailyLimit)
+	require.Equal(t, arg.UpdatedBy, limit1.UpdatedBy)
+
+	arg.DailyLimit = 300
+	limit2, err := testQueries.UpsertAccountTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), limit2.DailyLimit)
+
+	effective, err := testQueries.GetEffectiveTransferLimit(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, arg.PerTransactionLimit, effective.PerTransactionLimit)
+	require.Equal(t, arg.DailyLimit, effective.DailyLimit)
+
+	err = testQueries.DeleteAccountTransferLimit(context.Background(), account.ID)
+	require.NoError(t, err)
+
+	defaultLimit, err := testQueries.GetTransferLimit(context.Background(), util.USD)
+	require.NoError(t, err)
+
+	effective, err = testQueries.GetEffectiveTransferLimit(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, defaultLimit.PerTransactionLimit, effective.PerTransactionLimit)
+	require.Equal(t, defaultLimit.DailyLimit, effective.DailyLimit)
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+	admin := createRandomUser(t)
+
+	account1 := createRandomAccountWithCurrency(t, util.USD, 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD, 0)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:           account1.ID,
+		PerTransactionLimit: 50,
+		DailyLimit:          100,
+		UpdatedBy:           admin.ID,
+	})
+	require.NoError(t, err)
+
+	// over the per-transaction limit
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        60,
+		EnforceLimits: true,
+	})
+	var limitErr *LimitExceededError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, PerTransactionLimit, limitErr.Limit)
+	require.Equal(t, int64(50), limitErr.Remaining)
+
+	// run n concurrent transfers, only the first 100 / amount fit in the daily limit
+	n := 5
+	amount := int64(30)
+
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        amount,
+				EnforceLimits: true,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+
+		require.True(t, errors.As(err, &limitErr))
+		require.Equal(t, DailyLimit, limitErr.Limit)
+		require.Equal(t, int64(10), limitErr.Remaining)
+	}
+	require.Equal(t, 3, succeeded)
+
+	total, err := testQueries.GetDailyTransferTotal(context.Background(), GetDailyTransferTotalParams{
+		Owner:    account1.Owner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(succeeded)*amount, total)
+}
@@ -0,0 +1,66 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: transfer_review.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createTransferReview = `-- name: CreateTransferReview :one
+INSERT INTO TRANSFER_REVIEWS (
+  TRANSFER_ID,
+  REVIEWER_ID,
+  DECISION,
+  COMMENT
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING id, transfer_id, reviewer_id, decision, comment, created_at
+`
+
+type CreateTransferReviewParams struct {
+	TransferID int64  `json:"transfer_id"`
+	ReviewerID int64  `json:"reviewer_id"`
+	Decision   string `json:"decision"`
+	Comment    string `json:"comment"`
+}
+
+func (q *Queries) CreateTransferReview(ctx context.Context, arg CreateTransferReviewParams) (TransferReview, error) {
+	row := q.db.QueryRowContext(ctx, createTransferReview,
+		arg.TransferID,
+		arg.ReviewerID,
+		arg.Decision,
+		arg.Comment,
+	)
+	var i TransferReview
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.ReviewerID,
+		&i.Decision,
+		&i.Comment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getTransferReview = `-- name: GetTransferReview :one
+SELECT id, transfer_id, reviewer_id, decision, comment, created_at FROM TRANSFER_REVIEWS
+WHERE TRANSFER_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetTransferReview(ctx context.Context, transferID int64) (TransferReview, error) {
+	row := q.db.QueryRowContext(ctx, getTransferReview, transferID)
+	var i TransferReview
+	err := row.Scan(
+		&i.ID,
+		&i.TransferID,
+		&i.ReviewerID,
+		&i.Decision,
+		&i.Comment,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -19,6 +19,7 @@ func createRandomTransfer(t *testing.T) Transfer {
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
 		Amount:        util.RandomMoney(),
+		Status:        TransferStatusCompleted,
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -29,6 +30,7 @@ func createRandomTransfer(t *testing.T) Transfer {
 	require.Equal(t, transfer.FromAccountID, arg.FromAccountID)
 	require.Equal(t, transfer.ToAccountID, arg.ToAccountID)
 	require.Equal(t, transfer.Amount, arg.Amount)
+	require.Equal(t, transfer.Status, arg.Status)
 	require.NotZero(t, transfer.CreatedAt)
 
 	return transfer
@@ -7,6 +7,7 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 const createUser = `-- name: CreateUser :one
@@ -14,10 +15,11 @@ INSERT INTO USERS (
   USERNAME,
   HASHED_PASSWORD,
   FULL_NAME,
-  EMAIL
+  EMAIL,
+  PHONE
 ) VALUES (
-  $1, $2, $3, $4
-) RETURNING id, username, full_name, hashed_password, email, password_changed_at, created_at
+  $1, $2, $3, $4, $5
+) RETURNING id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at
 `
 
 type CreateUserParams struct {
@@ -25,6 +27,7 @@ type CreateUserParams struct {
 	HashedPassword string `json:"hashed_password"`
 	FullName       string `json:"full_name"`
 	Email          string `json:"email"`
+	Phone          string `json:"phone"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -33,6 +36,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.HashedPassword,
 		arg.FullName,
 		arg.Email,
+		arg.Phone,
 	)
 	var i User
 	err := row.Scan(
@@ -43,12 +47,38 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserByEmail = `-- name: GetUserByEmail :one
+SELECT id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at FROM USERS
+WHERE EMAIL = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUserById = `-- name: GetUserById :one
-SELECT id, username, full_name, hashed_password, email, password_changed_at, created_at FROM USERS
+SELECT id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at FROM USERS
 WHERE ID = $1 LIMIT 1
 `
 
@@ -63,12 +93,15 @@ func (q *Queries) GetUserById(ctx context.Context, id int64) (User, error) {
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, full_name, hashed_password, email, password_changed_at, created_at FROM USERS
+SELECT id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at FROM USERS
 WHERE USERNAME = $1 LIMIT 1
 `
 
@@ -83,6 +116,141 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.Email,
 		&i.PasswordChangedAt,
 		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at FROM USERS
+WHERE ID = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserForUpdate, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const getUserPasswordChangedAt = `-- name: GetUserPasswordChangedAt :one
+SELECT PASSWORD_CHANGED_AT FROM USERS
+WHERE USERNAME = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserPasswordChangedAt(ctx context.Context, username string) (time.Time, error) {
+	row := q.db.QueryRowContext(ctx, getUserPasswordChangedAt, username)
+	var password_changed_at time.Time
+	err := row.Scan(&password_changed_at)
+	return password_changed_at, err
+}
+
+const resolveRecipientAlias = `-- name: ResolveRecipientAlias :one
+SELECT A.ID AS ACCOUNT_ID, U.FULL_NAME FROM ACCOUNTS A
+JOIN USERS U ON U.ID = A.OWNER
+WHERE (U.USERNAME = $1
+  OR (U.EMAIL = $1 AND U.EMAIL_VERIFIED_AT > '0001-01-01 00:00:00Z')
+  OR (U.PHONE <> '' AND U.PHONE = $1))
+AND A.CURRENCY = $2
+AND A.STATUS = 'active'
+AND A.TYPE <> 'internal'
+ORDER BY A.TYPE = 'checking' DESC, A.ID
+LIMIT 1
+`
+
+type ResolveRecipientAliasParams struct {
+	Alias    string `json:"alias"`
+	Currency string `json:"currency"`
+}
+
+type ResolveRecipientAliasRow struct {
+	AccountID int64  `json:"account_id"`
+	FullName  string `json:"full_name"`
+}
+
+// Finds the account that receives payments sent to a username, email or phone number in a currency.
+// Checking accounts are preferred over savings accounts, then the oldest account. Emails only match
+// once verified, anyone can sign up with an address they do not own.
+func (q *Queries) ResolveRecipientAlias(ctx context.Context, arg ResolveRecipientAliasParams) (ResolveRecipientAliasRow, error) {
+	row := q.db.QueryRowContext(ctx, resolveRecipientAlias, arg.Alias, arg.Currency)
+	var i ResolveRecipientAliasRow
+	err := row.Scan(&i.AccountID, &i.FullName)
+	return i, err
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :one
+UPDATE USERS
+SET HASHED_PASSWORD = $2, PASSWORD_CHANGED_AT = $3
+WHERE ID = $1
+RETURNING id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at
+`
+
+type UpdateUserPasswordParams struct {
+	ID                int64     `json:"id"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+// The time comes from the server like the issue times of tokens, which are compared with it.
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserPassword, arg.ID, arg.HashedPassword, arg.PasswordChangedAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
+	)
+	return i, err
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :one
+UPDATE USERS
+SET EMAIL_VERIFIED_AT = now()
+WHERE ID = $1 AND EMAIL = $2
+RETURNING id, username, full_name, hashed_password, email, password_changed_at, created_at, role, phone, email_verified_at
+`
+
+type VerifyUserEmailParams struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
+
+// Only verifies the email the token was sent to, a changed email stays unverified.
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, verifyUserEmail, arg.ID, arg.Email)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.HashedPassword,
+		&i.Email,
+		&i.PasswordChangedAt,
+		&i.CreatedAt,
+		&i.Role,
+		&i.Phone,
+		&i.EmailVerifiedAt,
 	)
 	return i, err
 }
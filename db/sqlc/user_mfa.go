@@ -0,0 +1,46 @@
+package db
+
+import "context"
+
+type ConfirmUserMFATxParams struct {
+	UserID int64 `json:"user_id"`
+	// Step is the TOTP time step of the code that confirmed the enrolment
+	Step                int64    `json:"step"`
+	HashedRecoveryCodes []string `json:"-"`
+}
+
+// ConfirmUserMFATx enables a pending enrolment and replaces the recovery codes of the user
+func (store *SQLStore) ConfirmUserMFATx(ctx context.Context, arg ConfirmUserMFATxParams) (UserMfa, error) {
+	var mfa UserMfa
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		mfa, err = q.EnableUserMFA(ctx, EnableUserMFAParams{
+			UserID:       arg.UserID,
+			LastUsedStep: arg.Step,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteMFARecoveryCodes(ctx, arg.UserID)
+		if err != nil {
+			return err
+		}
+
+		for _, hashedCode := range arg.HashedRecoveryCodes {
+			_, err = q.CreateMFARecoveryCode(ctx, CreateMFARecoveryCodeParams{
+				UserID:     arg.UserID,
+				HashedCode: hashedCode,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return mfa, err
+}
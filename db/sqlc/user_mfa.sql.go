@@ -0,0 +1,178 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: user_mfa.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createMFARecoveryCode = `-- name: CreateMFARecoveryCode :one
+INSERT INTO MFA_RECOVERY_CODES (
+  USER_ID,
+  HASHED_CODE
+) VALUES (
+  $1, $2
+) RETURNING id, user_id, hashed_code, used_at, created_at
+`
+
+type CreateMFARecoveryCodeParams struct {
+	UserID     int64  `json:"user_id"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) CreateMFARecoveryCode(ctx context.Context, arg CreateMFARecoveryCodeParams) (MfaRecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, createMFARecoveryCode, arg.UserID, arg.HashedCode)
+	var i MfaRecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.HashedCode,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createUserMFA = `-- name: CreateUserMFA :one
+INSERT INTO USER_MFA (
+  USER_ID,
+  SECRET
+) VALUES (
+  $1, $2
+) ON CONFLICT (USER_ID) DO UPDATE SET
+  SECRET = EXCLUDED.SECRET,
+  LAST_USED_STEP = 0,
+  CREATED_AT = now()
+WHERE USER_MFA.STATUS = 'pending'
+RETURNING user_id, secret, status, last_used_step, confirmed_at, created_at
+`
+
+type CreateUserMFAParams struct {
+	UserID int64  `json:"user_id"`
+	Secret string `json:"secret"`
+}
+
+// Starts an enrolment, or restarts one that was never confirmed. Enabled MFA is left alone.
+func (q *Queries) CreateUserMFA(ctx context.Context, arg CreateUserMFAParams) (UserMfa, error) {
+	row := q.db.QueryRowContext(ctx, createUserMFA, arg.UserID, arg.Secret)
+	var i UserMfa
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Status,
+		&i.LastUsedStep,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteMFARecoveryCodes = `-- name: DeleteMFARecoveryCodes :exec
+DELETE FROM MFA_RECOVERY_CODES
+WHERE USER_ID = $1
+`
+
+func (q *Queries) DeleteMFARecoveryCodes(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteMFARecoveryCodes, userID)
+	return err
+}
+
+const enableUserMFA = `-- name: EnableUserMFA :one
+UPDATE USER_MFA
+SET STATUS = 'enabled', LAST_USED_STEP = $2, CONFIRMED_AT = now()
+WHERE USER_ID = $1 AND STATUS = 'pending'
+RETURNING user_id, secret, status, last_used_step, confirmed_at, created_at
+`
+
+type EnableUserMFAParams struct {
+	UserID       int64 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+func (q *Queries) EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) (UserMfa, error) {
+	row := q.db.QueryRowContext(ctx, enableUserMFA, arg.UserID, arg.LastUsedStep)
+	var i UserMfa
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Status,
+		&i.LastUsedStep,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getUserMFA = `-- name: GetUserMFA :one
+SELECT user_id, secret, status, last_used_step, confirmed_at, created_at FROM USER_MFA
+WHERE USER_ID = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserMFA(ctx context.Context, userID int64) (UserMfa, error) {
+	row := q.db.QueryRowContext(ctx, getUserMFA, userID)
+	var i UserMfa
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Status,
+		&i.LastUsedStep,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useMFARecoveryCode = `-- name: UseMFARecoveryCode :one
+UPDATE MFA_RECOVERY_CODES
+SET USED_AT = now()
+WHERE USER_ID = $1 AND HASHED_CODE = $2 AND USED_AT = '0001-01-01 00:00:00Z'
+RETURNING id, user_id, hashed_code, used_at, created_at
+`
+
+type UseMFARecoveryCodeParams struct {
+	UserID     int64  `json:"user_id"`
+	HashedCode string `json:"hashed_code"`
+}
+
+func (q *Queries) UseMFARecoveryCode(ctx context.Context, arg UseMFARecoveryCodeParams) (MfaRecoveryCode, error) {
+	row := q.db.QueryRowContext(ctx, useMFARecoveryCode, arg.UserID, arg.HashedCode)
+	var i MfaRecoveryCode
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.HashedCode,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const useUserMFAStep = `-- name: UseUserMFAStep :one
+UPDATE USER_MFA
+SET LAST_USED_STEP = $2
+WHERE USER_ID = $1 AND STATUS = 'enabled' AND LAST_USED_STEP < $2
+RETURNING user_id, secret, status, last_used_step, confirmed_at, created_at
+`
+
+type UseUserMFAStepParams struct {
+	UserID       int64 `json:"user_id"`
+	LastUsedStep int64 `json:"last_used_step"`
+}
+
+// Fails when the step was already used, so each code is accepted once.
+func (q *Queries) UseUserMFAStep(ctx context.Context, arg UseUserMFAStepParams) (UserMfa, error) {
+	row := q.db.QueryRowContext(ctx, useUserMFAStep, arg.UserID, arg.LastUsedStep)
+	var i UserMfa
+	err := row.Scan(
+		&i.UserID,
+		&i.Secret,
+		&i.Status,
+		&i.LastUsedStep,
+		&i.ConfirmedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
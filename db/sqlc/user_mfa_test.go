@@ -0,0 +1,61 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmUserMFATx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+
+	mfa, err := store.CreateUserMFA(context.Background(), CreateUserMFAParams{UserID: user.ID, Secret: secret})
+	require.NoError(t, err)
+	require.Equal(t, MFAStatusPending, mfa.Status)
+	require.True(t, mfa.ConfirmedAt.IsZero())
+
+	codes, err := util.RandomRecoveryCodes(3)
+	require.NoError(t, err)
+
+	hashedCodes := make([]string, len(codes))
+	for i, code := range codes {
+		hashedCodes[i] = util.HashRecoveryCode(code)
+	}
+
+	mfa, err = store.ConfirmUserMFATx(context.Background(), ConfirmUserMFATxParams{
+		UserID:              user.ID,
+		Step:                100,
+		HashedRecoveryCodes: hashedCodes,
+	})
+	require.NoError(t, err)
+	require.Equal(t, MFAStatusEnabled, mfa.Status)
+	require.Equal(t, int64(100), mfa.LastUsedStep)
+	require.False(t, mfa.ConfirmedAt.IsZero())
+
+	// an enabled enrolment cannot be restarted or confirmed again
+	_, err = store.CreateUserMFA(context.Background(), CreateUserMFAParams{UserID: user.ID, Secret: secret})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.ConfirmUserMFATx(context.Background(), ConfirmUserMFATxParams{UserID: user.ID, Step: 200})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	// each step and each recovery code is accepted once
+	_, err = store.UseUserMFAStep(context.Background(), UseUserMFAStepParams{UserID: user.ID, LastUsedStep: 100})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.UseUserMFAStep(context.Background(), UseUserMFAStepParams{UserID: user.ID, LastUsedStep: 101})
+	require.NoError(t, err)
+
+	_, err = store.UseMFARecoveryCode(context.Background(), UseMFARecoveryCodeParams{UserID: user.ID, HashedCode: hashedCodes[0]})
+	require.NoError(t, err)
+
+	_, err = store.UseMFARecoveryCode(context.Background(), UseMFARecoveryCodeParams{UserID: user.ID, HashedCode: hashedCodes[0]})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ func createRandomUser(t *testing.T) User {
 	require.Equal(t, arg.HashedPassword, user.HashedPassword)
 	require.Equal(t, arg.FullName, user.FullName)
 	require.Equal(t, arg.Email, user.Email)
+	require.Equal(t, util.CustomerRole, user.Role)
 
 	require.True(t, user.PasswordChangedAt.IsZero())
 
@@ -71,3 +73,37 @@ func TestGetUserByUsername(t *testing.T) {
 	require.WithinDuration(t, user1.PasswordChangedAt, user2.PasswordChangedAt, time.Second)
 	require.WithinDuration(t, user1.CreatedAt, user2.CreatedAt, time.Second)
 }
+
+func TestResolveRecipientAlias(t *testing.T) {
+	account := createRandomAccountWithCurrency(t, util.EUR, 0)
+
+	user, err := testQueries.GetUserById(context.Background(), account.Owner)
+	require.NoError(t, err)
+
+	// an email that was never verified does not resolve
+	_, err = testQueries.ResolveRecipientAlias(context.Background(), ResolveRecipientAliasParams{
+		Alias:    user.Email,
+		Currency: util.EUR,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.VerifyUserEmail(context.Background(), VerifyUserEmailParams{ID: user.ID, Email: user.Email})
+	require.NoError(t, err)
+
+	for _, alias := range []string{user.Username, user.Email} {
+		recipient, err := testQueries.ResolveRecipientAlias(context.Background(), ResolveRecipientAliasParams{
+			Alias:    alias,
+			Currency: util.EUR,
+		})
+		require.NoError(t, err)
+		require.Equal(t, account.ID, recipient.AccountID)
+		require.Equal(t, user.FullName, recipient.FullName)
+	}
+
+	// the user has no account in this currency
+	_, err = testQueries.ResolveRecipientAlias(context.Background(), ResolveRecipientAliasParams{
+		Alias:    user.Username,
+		Currency: util.CAD,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
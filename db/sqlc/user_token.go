@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+var ErrInvalidUserToken = errors.New("token is invalid, expired or has already been used")
+
+// IssueUserTokenTx stores a new token and invalidates the unused tokens of the user with the same
+// purpose, so only the link of the latest email works
+func (store *SQLStore) IssueUserTokenTx(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	var userToken UserToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		err = q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+			UserID:  arg.UserID,
+			Purpose: arg.Purpose,
+		})
+		if err != nil {
+			return err
+		}
+
+		userToken, err = q.CreateUserToken(ctx, arg)
+		return err
+	})
+
+	return userToken, err
+}
+
+// VerifyEmailTx uses up a verification token and marks the email it was sent to as verified
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, hashedToken string) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		userToken, err := consumeUserToken(ctx, q, hashedToken, UserTokenPurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			ID:    userToken.UserID,
+			Email: userToken.Email,
+		})
+		if err == sql.ErrNoRows {
+			return ErrInvalidUserToken
+		}
+		return err
+	})
+
+	return user, err
+}
+
+type ResetPasswordTxParams struct {
+	HashedToken    string `json:"-"`
+	HashedPassword string `json:"-"`
+}
+
+// ResetPasswordTx uses up a password reset token and sets the new password of the user. The token
+// only works while the user still has the email it was sent to.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		userToken, err := consumeUserToken(ctx, q, arg.HashedToken, UserTokenPurposeResetPassword)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.GetUserForUpdate(ctx, userToken.UserID)
+		if err != nil {
+			return err
+		}
+
+		if user.Email != userToken.Email {
+			return ErrInvalidUserToken
+		}
+
+		user, err = changePassword(ctx, q, user.ID, arg.HashedPassword)
+		return err
+	})
+
+	return user, err
+}
+
+type ChangePasswordTxParams struct {
+	UserID         int64  `json:"user_id"`
+	HashedPassword string `json:"-"`
+}
+
+// ChangePasswordTx sets a new password of the user, which revokes every token issued before it
+func (store *SQLStore) ChangePasswordTx(ctx context.Context, arg ChangePasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = changePassword(ctx, q, arg.UserID, arg.HashedPassword)
+		return err
+	})
+
+	return user, err
+}
+
+// changePassword updates the password and its change time, and invalidates the links of password
+// reset emails that were sent before
+func changePassword(ctx context.Context, q *Queries, userID int64, hashedPassword string) (User, error) {
+	user, err := q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+		ID:                userID,
+		HashedPassword:    hashedPassword,
+		PasswordChangedAt: time.Now(),
+	})
+	if err != nil {
+		return User{}, err
+	}
+
+	err = q.InvalidateUserTokens(ctx, InvalidateUserTokensParams{
+		UserID:  userID,
+		Purpose: UserTokenPurposeResetPassword,
+	})
+	return user, err
+}
+
+func consumeUserToken(ctx context.Context, q *Queries, hashedToken string, purpose string) (UserToken, error) {
+	userToken, err := q.UseUserToken(ctx, UseUserTokenParams{
+		HashedToken: hashedToken,
+		Purpose:     purpose,
+	})
+	if err == sql.ErrNoRows {
+		return UserToken{}, ErrInvalidUserToken
+	}
+	return userToken, err
+}
@@ -0,0 +1,98 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.15.0
+// source: user_token.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const createUserToken = `-- name: CreateUserToken :one
+INSERT INTO USER_TOKENS (
+  USER_ID,
+  PURPOSE,
+  HASHED_TOKEN,
+  EMAIL,
+  EXPIRES_AT
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING id, user_id, purpose, hashed_token, email, expires_at, used_at, created_at
+`
+
+type CreateUserTokenParams struct {
+	UserID      int64     `json:"user_id"`
+	Purpose     string    `json:"purpose"`
+	HashedToken string    `json:"hashed_token"`
+	Email       string    `json:"email"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, createUserToken,
+		arg.UserID,
+		arg.Purpose,
+		arg.HashedToken,
+		arg.Email,
+		arg.ExpiresAt,
+	)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.HashedToken,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const invalidateUserTokens = `-- name: InvalidateUserTokens :exec
+UPDATE USER_TOKENS
+SET USED_AT = now()
+WHERE USER_ID = $1 AND PURPOSE = $2 AND USED_AT = '0001-01-01 00:00:00Z'
+`
+
+type InvalidateUserTokensParams struct {
+	UserID  int64  `json:"user_id"`
+	Purpose string `json:"purpose"`
+}
+
+func (q *Queries) InvalidateUserTokens(ctx context.Context, arg InvalidateUserTokensParams) error {
+	_, err := q.db.ExecContext(ctx, invalidateUserTokens, arg.UserID, arg.Purpose)
+	return err
+}
+
+const useUserToken = `-- name: UseUserToken :one
+UPDATE USER_TOKENS
+SET USED_AT = now()
+WHERE HASHED_TOKEN = $1 AND PURPOSE = $2 AND USED_AT = '0001-01-01 00:00:00Z' AND EXPIRES_AT > now()
+RETURNING id, user_id, purpose, hashed_token, email, expires_at, used_at, created_at
+`
+
+type UseUserTokenParams struct {
+	HashedToken string `json:"hashed_token"`
+	Purpose     string `json:"purpose"`
+}
+
+// Fails when the token is unknown, expired or already used, so each token is accepted once.
+func (q *Queries) UseUserToken(ctx context.Context, arg UseUserTokenParams) (UserToken, error) {
+	row := q.db.QueryRowContext(ctx, useUserToken, arg.HashedToken, arg.Purpose)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Purpose,
+		&i.HashedToken,
+		&i.Email,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
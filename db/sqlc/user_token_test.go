@@ -0,0 +1,122 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func issueRandomUserToken(t *testing.T, store Store, user User, purpose string, expiresAt time.Time) string {
+	emailToken, err := util.RandomEmailToken()
+	require.NoError(t, err)
+
+	userToken, err := store.IssueUserTokenTx(context.Background(), CreateUserTokenParams{
+		UserID:      user.ID,
+		Purpose:     purpose,
+		HashedToken: util.HashToken(emailToken),
+		Email:       user.Email,
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, userToken.UserID)
+	require.True(t, userToken.UsedAt.IsZero())
+
+	return emailToken
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.True(t, user.EmailVerifiedAt.IsZero())
+
+	// issuing a new token invalidates the link of the previous email
+	oldToken := issueRandomUserToken(t, store, user, UserTokenPurposeVerifyEmail, time.Now().Add(time.Hour))
+	emailToken := issueRandomUserToken(t, store, user, UserTokenPurposeVerifyEmail, time.Now().Add(time.Hour))
+
+	_, err := store.VerifyEmailTx(context.Background(), util.HashToken(oldToken))
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	verified, err := store.VerifyEmailTx(context.Background(), util.HashToken(emailToken))
+	require.NoError(t, err)
+	require.Equal(t, user.ID, verified.ID)
+	require.WithinDuration(t, time.Now(), verified.EmailVerifiedAt, time.Minute)
+
+	// each token is accepted once
+	_, err = store.VerifyEmailTx(context.Background(), util.HashToken(emailToken))
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	expiredToken := issueRandomUserToken(t, store, user, UserTokenPurposeVerifyEmail, time.Now().Add(-time.Second))
+	_, err = store.VerifyEmailTx(context.Background(), util.HashToken(expiredToken))
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	// a password reset token cannot verify the email
+	resetToken := issueRandomUserToken(t, store, user, UserTokenPurposeResetPassword, time.Now().Add(time.Hour))
+	_, err = store.VerifyEmailTx(context.Background(), util.HashToken(resetToken))
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.True(t, user.PasswordChangedAt.IsZero())
+
+	emailToken := issueRandomUserToken(t, store, user, UserTokenPurposeResetPassword, time.Now().Add(time.Hour))
+
+	newPassword := util.RandomString(8)
+	hashedPassword, err := util.HashPassword(newPassword)
+	require.NoError(t, err)
+
+	updated, err := store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedToken:    util.HashToken(emailToken),
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.ID, updated.ID)
+	require.NoError(t, util.CheckPassword(newPassword, updated.HashedPassword))
+	require.WithinDuration(t, time.Now(), updated.PasswordChangedAt, time.Minute)
+
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedToken:    util.HashToken(emailToken),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+
+	expiredToken := issueRandomUserToken(t, store, user, UserTokenPurposeResetPassword, time.Now().Add(-time.Second))
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedToken:    util.HashToken(expiredToken),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
+
+func TestChangePasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	resetToken := issueRandomUserToken(t, store, user, UserTokenPurposeResetPassword, time.Now().Add(time.Hour))
+
+	hashedPassword, err := util.HashPassword(util.RandomString(8))
+	require.NoError(t, err)
+
+	changed, err := store.ChangePasswordTx(context.Background(), ChangePasswordTxParams{
+		UserID:         user.ID,
+		HashedPassword: hashedPassword,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, changed.HashedPassword)
+	require.WithinDuration(t, time.Now(), changed.PasswordChangedAt, time.Minute)
+
+	passwordChangedAt, err := store.GetUserPasswordChangedAt(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.WithinDuration(t, changed.PasswordChangedAt, passwordChangedAt, time.Microsecond)
+
+	// the links of reset emails sent before the change no longer work
+	_, err = store.ResetPasswordTx(context.Background(), ResetPasswordTxParams{
+		HashedToken:    util.HashToken(resetToken),
+		HashedPassword: hashedPassword,
+	})
+	require.ErrorIs(t, err, ErrInvalidUserToken)
+}
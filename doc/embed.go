@@ -0,0 +1,7 @@
+// Package doc embeds the Swagger UI and the OpenAPI document generated from the protobuf definitions
+package doc
+
+import "embed"
+
+//go:embed swagger
+var Swagger embed.FS
@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	viewAccountRoles     = []string{db.AccountRoleOwner, db.AccountRoleCoOwner, db.AccountRoleViewer}
+	transferAccountRoles = []string{db.AccountRoleOwner, db.AccountRoleCoOwner}
+)
+
+// authorizeAccount checks that the authenticated user is an active member of the account with one of
+// the roles, like the HTTP API does. Accounts the user is not a member of are reported as not found.
+func (server *Server) authorizeAccount(ctx context.Context, accountID int64, roles ...string) (db.Account, db.User, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return db.Account{}, db.User{}, status.Errorf(codes.NotFound, "account %d not found", accountID)
+		}
+		return db.Account{}, db.User{}, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload(ctx).Username)
+	if err != nil {
+		return db.Account{}, db.User{}, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		UserID:    user.ID,
+	})
+	if err != nil && err != sql.ErrNoRows {
+		return db.Account{}, db.User{}, status.Errorf(codes.Internal, "failed to get account member: %s", err)
+	}
+
+	if err == sql.ErrNoRows || member.Status != db.MemberStatusActive {
+		return db.Account{}, db.User{}, status.Errorf(codes.NotFound, "account %d not found", accountID)
+	}
+
+	for _, role := range roles {
+		if member.Role == role {
+			return account, user, nil
+		}
+	}
+
+	return db.Account{}, db.User{}, status.Errorf(codes.PermissionDenied, "account role %s is not allowed to perform this action", member.Role)
+}
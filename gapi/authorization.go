@@ -0,0 +1,96 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/khorsl/simple_bank/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+)
+
+// publicMethods can be called without an access token
+var publicMethods = map[string]bool{
+	"/pb.SimpleBank/CreateUser":     true,
+	"/pb.SimpleBank/LoginUser":      true,
+	"/pb.SimpleBank/VerifyLoginMFA": true,
+}
+
+type authorizationPayloadKey struct{}
+
+// AuthInterceptor is the gRPC equivalent of the HTTP auth middleware. It verifies the bearer token
+// in the authorization metadata and stores its payload in the context of the call.
+func (server *Server) AuthInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	payload, err := server.authorizeUser(ctx)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return handler(context.WithValue(ctx, authorizationPayloadKey{}, payload), req)
+}
+
+func (server *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeaderKey)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return nil, fmt.Errorf("unsupported authorization type %s", authorizationType)
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Purpose != token.PurposeAccess {
+		return nil, fmt.Errorf("token is not an access token")
+	}
+
+	passwordChangedAt, err := server.store.GetUserPasswordChangedAt(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user of the token does not exist")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to check token: %s", err)
+	}
+
+	if err := payload.ValidSince(passwordChangedAt); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+// authPayload returns the payload stored by AuthInterceptor
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authorizationPayloadKey{}).(*token.Payload)
+}
@@ -0,0 +1,162 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	username := "alice"
+
+	testCases := []struct {
+		name       string
+		method     string
+		setupAuth  func(t *testing.T, server *Server) context.Context
+		buildStubs func(store *mockdb.MockStore)
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name:   "OK",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, username, time.Minute)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "PublicMethod",
+			method: "/pb.SimpleBank/LoginUser",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return context.Background()
+			},
+			checkError: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return metadata.NewIncomingContext(context.Background(), metadata.MD{})
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "UnsupportedAuthorization",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				md := metadata.Pairs(authorizationHeaderKey, "basic dXNlcjpwYXNz")
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "ExpiredToken",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, username, -time.Minute)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "MFAChallengeToken",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				challengeToken, _, err := server.tokenMaker.CreatePurposeToken(username, token.PurposeMFAChallenge, time.Minute)
+				require.NoError(t, err)
+
+				md := metadata.Pairs(authorizationHeaderKey, authorizationTypeBearer+" "+challengeToken)
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "IssuedBeforePasswordChange",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserPasswordChangedAt(gomock.Any(), gomock.Eq(username)).Times(1).Return(time.Now().Add(time.Minute), nil)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Contains(t, err.Error(), token.ErrRevokedToken.Error())
+			},
+		},
+		{
+			name:   "UserNotFound",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrNoRows)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "InternalError",
+			method: "/pb.SimpleBank/GetAccount",
+			setupAuth: func(t *testing.T, server *Server) context.Context {
+				return newContextWithBearerToken(t, server, username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, sql.ErrConnDone)
+			},
+			checkError: func(t *testing.T, err error) {
+				require.Equal(t, codes.Internal, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+
+			server := newTestServer(t, store)
+			ctx := tc.setupAuth(t, server)
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if payload, ok := ctx.Value(authorizationPayloadKey{}).(*token.Payload); ok {
+					require.Equal(t, username, payload.Username)
+				}
+				return nil, nil
+			}
+
+			_, err := server.AuthInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			tc.checkError(t, err)
+		})
+	}
+}
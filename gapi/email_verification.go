@@ -0,0 +1,33 @@
+package gapi
+
+import (
+	"context"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/mail"
+	"github.com/khorsl/simple_bank/util"
+)
+
+// sendVerificationEmail issues a verification token like the HTTP API does and emails its link
+func (server *Server) sendVerificationEmail(ctx context.Context, user db.User) error {
+	duration := server.config.EmailVerificationTokenDuration
+
+	emailToken, err := util.RandomEmailToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = server.store.IssueUserTokenTx(ctx, db.CreateUserTokenParams{
+		UserID:      user.ID,
+		Purpose:     db.UserTokenPurposeVerifyEmail,
+		HashedToken: util.HashToken(emailToken),
+		Email:       user.Email,
+		ExpiresAt:   time.Now().Add(duration),
+	})
+	if err != nil {
+		return err
+	}
+
+	return server.mailer.Send(ctx, mail.NewVerificationEmail(server.config.AppURL, user.Email, user.FullName, emailToken, duration))
+}
@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"errors"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: err.Error(),
+	}
+}
+
+// invalidArgumentError reports every field that failed validation, like a binding error of the HTTP API
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	badRequest := &errdetails.BadRequest{FieldViolations: violations}
+	statusInvalid := status.New(codes.InvalidArgument, "invalid parameters")
+
+	statusDetails, err := statusInvalid.WithDetails(badRequest)
+	if err != nil {
+		return statusInvalid.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// transferTxError maps the errors of the transfer transactions like handleTransferTxError does for HTTP
+func transferTxError(err error) error {
+	var limitErr *db.LimitExceededError
+	if errors.As(err, &limitErr) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	if errors.Is(err, db.ErrInsufficientFunds) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	var notActiveErr *db.AccountNotActiveError
+	if errors.As(err, &notActiveErr) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	var coolingOffErr *db.CoolingOffLimitError
+	if errors.As(err, &coolingOffErr) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return status.Errorf(codes.Internal, "failed to transfer: %s", err)
+}
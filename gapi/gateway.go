@@ -0,0 +1,49 @@
+package gapi
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/khorsl/simple_bank/doc"
+	"github.com/khorsl/simple_bank/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// NewGatewayHandler serves the REST mapping of the gRPC API, generated from the google.api.http
+// annotations, and the Swagger UI at /swagger/. Calls are forwarded to the gRPC server at grpcAddress
+// so that they go through the same interceptors.
+func NewGatewayHandler(ctx context.Context, grpcAddress string) (http.Handler, error) {
+	// field names are kept as in the protobuf definitions, like the JSON of the Gin API
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames:   true,
+			EmitUnpopulated: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOption)
+
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	err := pb.RegisterSimpleBankHandlerFromEndpoint(ctx, grpcMux, grpcAddress, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	swagger, err := fs.Sub(doc.Swagger, "swagger")
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", grpcMux)
+	mux.Handle("/swagger/", http.StripPrefix("/swagger/", http.FileServer(http.FS(swagger))))
+
+	return mux, nil
+}
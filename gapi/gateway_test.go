@@ -0,0 +1,167 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// newTestGateway serves the gateway in front of a gRPC server backed by the store
+func newTestGateway(t *testing.T, server *Server) http.Handler {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(server.AuthInterceptor, server.RateLimitInterceptor))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	handler, err := NewGatewayHandler(ctx, listener.Addr().String())
+	require.NoError(t, err)
+
+	return handler
+}
+
+func TestGateway(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		method        string
+		url           string
+		body          interface{}
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "LoginUser",
+			method: http.MethodPost,
+			url:    "/v1/users/login",
+			body:   map[string]string{"username": user.Username, "password": password},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(db.UserMfa{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var response map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				require.NoError(t, err)
+				require.NotEmpty(t, response["access_token"])
+				require.Equal(t, user.Username, response["user"].(map[string]interface{})["username"])
+			},
+		},
+		{
+			name:   "InvalidArgument",
+			method: http.MethodPost,
+			url:    "/v1/users/login",
+			body:   map[string]string{"username": user.Username},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: http.MethodGet,
+			url:    "/v1/accounts/1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:       "SwaggerUI",
+			method:     http.MethodGet,
+			url:        "/swagger/",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "swagger-ui")
+			},
+		},
+		{
+			name:       "OpenAPIDocument",
+			method:     http.MethodGet,
+			url:        "/swagger/simple_bank.swagger.json",
+			buildStubs: func(store *mockdb.MockStore) {},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var document map[string]interface{}
+				err := json.Unmarshal(recorder.Body.Bytes(), &document)
+				require.NoError(t, err)
+				require.Contains(t, document["paths"], "/v1/transfers")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			handler := newTestGateway(t, newTestServer(t, store))
+			recorder := httptest.NewRecorder()
+
+			var body bytes.Buffer
+			if tc.body != nil {
+				err := json.NewEncoder(&body).Encode(tc.body)
+				require.NoError(t, err)
+			}
+
+			request, err := http.NewRequest(tc.method, tc.url, &body)
+			require.NoError(t, err)
+
+			handler.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGatewayRateLimit(t *testing.T) {
+	server := newRateLimitedTestServer(t, ratelimit.NewMemoryLimiter())
+	handler := newTestGateway(t, server)
+
+	login := func() int {
+		// the body is invalid, calls that get past the limiter stop at validation
+		request, err := http.NewRequest(http.MethodPost, "/v1/users/login", bytes.NewBufferString(`{}`))
+		require.NoError(t, err)
+		request.RemoteAddr = "192.0.2.1:1000"
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusBadRequest, login())
+	require.Equal(t, http.StatusBadRequest, login())
+	require.Equal(t, http.StatusTooManyRequests, login())
+}
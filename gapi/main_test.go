@@ -0,0 +1,95 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+	}
+
+	server, err := NewServer(config, store, ratelimit.NewMemoryLimiter())
+	require.NoError(t, err)
+
+	// the users of test tokens never changed their password and have no saved payees, unless a
+	// test stubs the lookups before
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().GetUserPasswordChangedAt(gomock.Any(), gomock.Any()).AnyTimes().Return(time.Time{}, nil)
+		mockStore.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).AnyTimes().Return(db.Payee{}, sql.ErrNoRows)
+	}
+
+	return server
+}
+
+// newContextWithBearerToken returns the incoming context of a call made with an access token
+func newContextWithBearerToken(t *testing.T, server *Server, username string, duration time.Duration) context.Context {
+	accessToken, _, err := server.tokenMaker.CreateToken(username, duration)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeaderKey: []string{fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)},
+	}
+
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// newAuthorizedContext returns the context a handler sees after AuthInterceptor accepted the call
+func newAuthorizedContext(t *testing.T, server *Server, username string) context.Context {
+	ctx := newContextWithBearerToken(t, server, username, time.Minute)
+
+	payload, err := server.authorizeUser(ctx)
+	require.NoError(t, err)
+
+	return context.WithValue(ctx, authorizationPayloadKey{}, payload)
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = util.RandomString(6)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		ID:             util.RandomInt(1, 1000),
+		Username:       util.RandomUsername(),
+		HashedPassword: hashedPassword,
+		FullName:       util.RandomUsername(),
+		Email:          util.RandomEmail(),
+		Role:           util.CustomerRole,
+	}
+	return
+}
+
+func randomAccount(owner int64) db.Account {
+	return db.Account{
+		ID:       util.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(),
+		Currency: util.USD,
+		Type:     util.Checking,
+		Status:   db.AccountStatusActive,
+	}
+}
+
+func newAccountMember(accountID int64, userID int64, role string) db.AccountMember {
+	return db.AccountMember{
+		AccountID: accountID,
+		UserID:    userID,
+		Role:      role,
+		Status:    db.MemberStatusActive,
+		InvitedBy: userID,
+	}
+}
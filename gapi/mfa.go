@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errInvalidMFACode = status.Error(codes.Unauthenticated, "incorrect authentication code")
+
+// useMFACode reports whether the TOTP code or the recovery code is valid, and uses it up so it
+// cannot be accepted again, like the HTTP API does
+func (server *Server) useMFACode(ctx context.Context, mfa db.UserMfa, code string, recoveryCode string) (bool, error) {
+	if code != "" {
+		step, ok := util.ValidateTOTP(mfa.Secret, code, time.Now())
+		if !ok {
+			return false, nil
+		}
+
+		_, err := server.store.UseUserMFAStep(ctx, db.UseUserMFAStepParams{
+			UserID:       mfa.UserID,
+			LastUsedStep: step,
+		})
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	if recoveryCode == "" {
+		return false, nil
+	}
+
+	_, err := server.store.UseMFARecoveryCode(ctx, db.UseMFARecoveryCodeParams{
+		UserID:     mfa.UserID,
+		HashedCode: util.HashRecoveryCode(recoveryCode),
+	})
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// checkStepUp fails when the amount is above the step-up threshold and the call does not carry a
+// valid authentication code of the user. Wrong codes count as failed logins like in VerifyLoginMFA.
+func (server *Server) checkStepUp(ctx context.Context, user db.User, amount int64, code string) error {
+	threshold := server.config.StepUpTransferThreshold
+	if threshold <= 0 || amount <= threshold {
+		return nil
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return status.Errorf(codes.Internal, "failed to get two-factor authentication: %s", err)
+	}
+
+	if err == sql.ErrNoRows || mfa.Status != db.MFAStatusEnabled {
+		return status.Error(codes.PermissionDenied, "set up two-factor authentication to make this transfer")
+	}
+
+	if code == "" {
+		return status.Error(codes.PermissionDenied, "an authentication code is required for this transfer")
+	}
+
+	ip := clientIP(ctx)
+	if err := server.checkLoginLockout(ctx, user.Username, ip); err != nil {
+		return err
+	}
+
+	ok, err := server.useMFACode(ctx, mfa, code, "")
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to verify authentication code: %s", err)
+	}
+
+	if !ok {
+		return server.loginFailed(ctx, user.Username, ip, errInvalidMFACode)
+	}
+
+	return nil
+}
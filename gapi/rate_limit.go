@@ -0,0 +1,78 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// rateLimitedMethod names the bucket of a method. The names are the routes of the HTTP API so a
+// client shares one bucket across both APIs.
+func (server *Server) rateLimitedMethod(method string) (string, ratelimit.Limit, bool) {
+	config := server.config
+
+	switch method {
+	case "/pb.SimpleBank/CreateUser":
+		return "signup", ratelimit.Limit{Requests: config.RateLimitSignupRequests, Period: config.RateLimitSignupPeriod}, true
+	case "/pb.SimpleBank/LoginUser", "/pb.SimpleBank/VerifyLoginMFA":
+		return "login", ratelimit.Limit{Requests: config.RateLimitLoginRequests, Period: config.RateLimitLoginPeriod}, true
+	case "/pb.SimpleBank/CreateTransfer":
+		return "transfer", ratelimit.Limit{Requests: config.RateLimitTransferRequests, Period: config.RateLimitTransferPeriod}, true
+	}
+
+	return "", ratelimit.Limit{}, false
+}
+
+// RateLimitInterceptor is the gRPC equivalent of the HTTP rate limit middleware. Authenticated calls
+// are limited per user and anonymous ones per client IP, so it must be chained after AuthInterceptor.
+func (server *Server) RateLimitInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	route, limit, ok := server.rateLimitedMethod(info.FullMethod)
+	if !ok || !limit.Enabled() {
+		return handler(ctx, req)
+	}
+
+	key := fmt.Sprintf("%s:ip:%s", route, clientIP(ctx))
+	if payload, ok := ctx.Value(authorizationPayloadKey{}).(*token.Payload); ok {
+		key = fmt.Sprintf("%s:user:%s", route, payload.Username)
+	}
+
+	result, err := server.limiter.Allow(ctx, key, limit)
+	if err != nil {
+		// an unavailable backend should not take the API down with it
+		log.Printf("cannot check rate limit of %s: %v", key, err)
+		return handler(ctx, req)
+	}
+
+	if !result.Allowed {
+		return nil, rateLimitedError(result.RetryAfter)
+	}
+
+	return handler(ctx, req)
+}
+
+// rateLimitedError tells the client how long to wait, rounded up to a second so it never retries too early
+func rateLimitedError(wait time.Duration) error {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(time.Duration(seconds) * time.Second)}
+	statusLimited := status.New(codes.ResourceExhausted, "too many requests, try again later")
+	statusDetails, err := statusLimited.WithDetails(retryInfo)
+	if err != nil {
+		return statusLimited.Err()
+	}
+
+	return statusDetails.Err()
+}
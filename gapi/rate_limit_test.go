@@ -0,0 +1,119 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func newRateLimitedTestServer(t *testing.T, limiter ratelimit.Limiter) *Server {
+	config := util.Config{
+		TokenSymmetricKey:         util.RandomString(32),
+		AccessTokenDuration:       time.Minute,
+		RateLimitLoginRequests:    2,
+		RateLimitLoginPeriod:      time.Minute,
+		RateLimitTransferRequests: 1,
+		RateLimitTransferPeriod:   time.Hour,
+	}
+
+	ctrl := gomock.NewController(t)
+	server, err := NewServer(config, mockdb.NewMockStore(ctrl), limiter)
+	require.NoError(t, err)
+
+	return server
+}
+
+// callThroughRateLimit calls method from the IP with the interceptor, the handler always succeeds
+func callThroughRateLimit(server *Server, ctx context.Context, method string, ip string) error {
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 1234}})
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	}
+
+	_, err := server.RateLimitInterceptor(ctx, nil, info, handler)
+	return err
+}
+
+func TestRateLimitInterceptorPerIP(t *testing.T) {
+	server := newRateLimitedTestServer(t, ratelimit.NewMemoryLimiter())
+	ctx := context.Background()
+
+	// logging in and verifying the second factor share the login bucket
+	require.NoError(t, callThroughRateLimit(server, ctx, "/pb.SimpleBank/LoginUser", "192.0.2.1"))
+	require.NoError(t, callThroughRateLimit(server, ctx, "/pb.SimpleBank/VerifyLoginMFA", "192.0.2.1"))
+
+	err := callThroughRateLimit(server, ctx, "/pb.SimpleBank/LoginUser", "192.0.2.1")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	require.Equal(t, 30*time.Second, details[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+
+	// another client is not affected
+	require.NoError(t, callThroughRateLimit(server, ctx, "/pb.SimpleBank/LoginUser", "198.51.100.7"))
+
+	// methods without a limit are let through
+	for i := 0; i < 3; i++ {
+		require.NoError(t, callThroughRateLimit(server, ctx, "/pb.SimpleBank/GetAccount", "192.0.2.1"))
+	}
+}
+
+func TestRateLimitInterceptorPerUser(t *testing.T) {
+	server := newRateLimitedTestServer(t, ratelimit.NewMemoryLimiter())
+
+	withUser := func(username string) context.Context {
+		return context.WithValue(context.Background(), authorizationPayloadKey{}, &token.Payload{Username: username})
+	}
+
+	require.NoError(t, callThroughRateLimit(server, withUser("alice"), "/pb.SimpleBank/CreateTransfer", "192.0.2.1"))
+
+	err := callThroughRateLimit(server, withUser("alice"), "/pb.SimpleBank/CreateTransfer", "198.51.100.7")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// users behind the same IP have their own limit
+	require.NoError(t, callThroughRateLimit(server, withUser("bob"), "/pb.SimpleBank/CreateTransfer", "192.0.2.1"))
+}
+
+func TestRateLimitInterceptorSharedLimiter(t *testing.T) {
+	limiter := ratelimit.NewMemoryLimiter()
+	server := newRateLimitedTestServer(t, limiter)
+
+	// a login through the HTTP API takes from the same bucket
+	limit := ratelimit.Limit{Requests: server.config.RateLimitLoginRequests, Period: server.config.RateLimitLoginPeriod}
+	for i := 0; i < 2; i++ {
+		_, err := limiter.Allow(context.Background(), "login:ip:192.0.2.1", limit)
+		require.NoError(t, err)
+	}
+
+	err := callThroughRateLimit(server, context.Background(), "/pb.SimpleBank/LoginUser", "192.0.2.1")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestRateLimitInterceptorBackendError(t *testing.T) {
+	server := newRateLimitedTestServer(t, failingLimiter{})
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, callThroughRateLimit(server, context.Background(), "/pb.SimpleBank/LoginUser", "192.0.2.1"))
+	}
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("backend is unavailable")
+}
@@ -0,0 +1,75 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if violations := validateCreateAccountRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, authPayload(ctx).Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user: %s", err)
+	}
+
+	accountType := req.GetType()
+	if accountType == "" {
+		accountType = util.Checking
+	}
+
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    user.ID,
+			Currency: req.GetCurrency(),
+			Balance:  0,
+			Type:     accountType,
+			Nickname: req.GetNickname(),
+		},
+		MaxAccounts: server.config.MaxAccounts(accountType),
+	}
+
+	account, err := server.store.CreateAccountTx(ctx, arg)
+	if err != nil {
+		var limitErr *db.AccountLimitError
+		if errors.As(err, &limitErr) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				return nil, status.Error(codes.AlreadyExists, err.Error())
+			case "foreign_key_violation":
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create account: %s", err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func validateCreateAccountRequest(req *pb.CreateAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if err := validateAccountType(req.GetType()); err != nil {
+		violations = append(violations, fieldViolation("type", err))
+	}
+	if len(req.GetNickname()) > 50 {
+		violations = append(violations, fieldViolation("nickname", fmt.Errorf("must be at most 50 characters")))
+	}
+	return violations
+}
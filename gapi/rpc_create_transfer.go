@@ -0,0 +1,210 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/risk"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransfer goes through the same checks as a transfer made over HTTP. A transfer stopped for
+// review or waiting for approvals is returned with its status and without any account or entry.
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if violations := validateCreateTransferRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, user, err := server.authorizeAccount(ctx, req.GetFromAccountId(), transferAccountRoles...)
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, req.GetCurrency())
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "account %d not found", req.GetToAccountId())
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if toAccount.Currency != req.GetCurrency() {
+		return nil, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", toAccount.ID, toAccount.Currency, req.GetCurrency())
+	}
+
+	if db.AvailableBalance(fromAccount) < req.GetAmount() {
+		return nil, status.Errorf(codes.FailedPrecondition, "accountID %d does not have sufficient funds for %d transfer", fromAccount.ID, req.GetAmount())
+	}
+
+	if err := server.checkStepUp(ctx, user, req.GetAmount(), req.GetMfaCode()); err != nil {
+		return nil, err
+	}
+
+	payee, err := server.getPayeeTransfer(ctx, user.ID, toAccount.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment, err := server.riskEngine.Assess(ctx, risk.Transfer{
+		UserID:        user.ID,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		Currency:      req.GetCurrency(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to assess transfer: %s", err)
+	}
+
+	if assessment.Decision == risk.Block {
+		return server.flagTransfer(ctx, req, assessment, payee, nil)
+	}
+
+	policy, err := server.store.GetAccountApprovalPolicy(ctx, fromAccount.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to get approval policy: %s", err)
+	}
+	needsApproval := err == nil && req.GetAmount() > policy.Threshold
+
+	if assessment.Decision == risk.Review {
+		// a reviewed transfer still has to be approved when it is over the threshold
+		var approval *db.PendingApprovalParams
+		if needsApproval {
+			approval = &db.PendingApprovalParams{
+				InitiatedBy:       user.ID,
+				RequiredApprovals: policy.RequiredApprovals,
+				ApprovalWindow:    time.Duration(policy.ApprovalWindowMinutes) * time.Minute,
+			}
+		}
+
+		return server.flagTransfer(ctx, req, assessment, payee, approval)
+	}
+
+	if needsApproval {
+		return server.requestTransferApproval(ctx, req, user, policy, assessment, payee)
+	}
+
+	result, err := server.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		EnforceLimits: true,
+		RiskDecision:  string(assessment.Decision),
+		MatchedRules:  assessment.MatchedRules,
+		Payee:         payee,
+	})
+	if err != nil {
+		return nil, transferTxError(err)
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+	}, nil
+}
+
+// flagTransfer records a transfer that the risk engine blocked or sent for review
+func (server *Server) flagTransfer(ctx context.Context, req *pb.CreateTransferRequest, assessment risk.Assessment, payee *db.PayeeTransferParams, approval *db.PendingApprovalParams) (*pb.CreateTransferResponse, error) {
+	transferStatus := db.TransferStatusBlocked
+	if assessment.Decision == risk.Review {
+		transferStatus = db.TransferStatusPending
+	}
+
+	result, err := server.store.FlagTransferTx(ctx, db.FlagTransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        req.GetAmount(),
+		Status:        transferStatus,
+		EnforceLimits: transferStatus == db.TransferStatusPending,
+		RiskDecision:  string(assessment.Decision),
+		MatchedRules:  assessment.MatchedRules,
+		Payee:         payee,
+		Approval:      approval,
+	})
+	if err != nil {
+		return nil, transferTxError(err)
+	}
+
+	if transferStatus == db.TransferStatusBlocked {
+		// matched rules are kept internal so they cannot be probed
+		return nil, status.Errorf(codes.PermissionDenied, "transfer %d was blocked by risk checks", result.Transfer.ID)
+	}
+
+	return &pb.CreateTransferResponse{Transfer: convertTransfer(result.Transfer)}, nil
+}
+
+func (server *Server) requestTransferApproval(ctx context.Context, req *pb.CreateTransferRequest, user db.User, policy db.AccountApprovalPolicy, assessment risk.Assessment, payee *db.PayeeTransferParams) (*pb.CreateTransferResponse, error) {
+	result, err := server.store.RequestTransferApprovalTx(ctx, db.RequestTransferApprovalTxParams{
+		FromAccountID:     req.GetFromAccountId(),
+		ToAccountID:       req.GetToAccountId(),
+		Amount:            req.GetAmount(),
+		InitiatedBy:       user.ID,
+		RequiredApprovals: policy.RequiredApprovals,
+		ExpiresAt:         time.Now().Add(time.Duration(policy.ApprovalWindowMinutes) * time.Minute),
+		EnforceLimits:     true,
+		RiskDecision:      string(assessment.Decision),
+		MatchedRules:      assessment.MatchedRules,
+		Payee:             payee,
+	})
+	if err != nil {
+		return nil, transferTxError(err)
+	}
+
+	return &pb.CreateTransferResponse{Transfer: convertTransfer(result.Transfer)}, nil
+}
+
+// getPayeeTransfer records the transfer against the account being paid for the cooling-off limit,
+// which the store applies to every recipient first saved or paid within the cooling-off period
+func (server *Server) getPayeeTransfer(ctx context.Context, userID int64, accountID int64) (*db.PayeeTransferParams, error) {
+	arg := &db.PayeeTransferParams{
+		UserID:           userID,
+		AccountID:        accountID,
+		CoolingOffPeriod: server.config.PayeeCoolingOffPeriod,
+		CoolingOffLimit:  server.config.PayeeCoolingOffLimit,
+	}
+
+	payee, err := server.store.GetPayeeByAccount(ctx, db.GetPayeeByAccountParams{
+		UserID:    userID,
+		AccountID: accountID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return arg, nil
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get payee: %s", err)
+	}
+
+	arg.PayeeID = payee.ID
+	arg.FirstSavedAt = payee.FirstSavedAt
+	return arg, nil
+}
+
+func validateCreateTransferRequest(req *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateID(req.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+	if err := validateID(req.GetToAccountId()); err != nil {
+		violations = append(violations, fieldViolation("to_account_id", err))
+	}
+	if req.GetAmount() <= 0 {
+		violations = append(violations, fieldViolation("amount", fmt.Errorf("must be greater than 0")))
+	}
+	if err := validateCurrency(req.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+	if err := validateMFACode(req.GetMfaCode()); err != nil {
+		violations = append(violations, fieldViolation("mfa_code", err))
+	}
+	return violations
+}
@@ -0,0 +1,389 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/risk"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateTransferRPC(t *testing.T) {
+	amount := int64(10)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.ID)
+	account2 := randomAccount(user2.ID)
+	account1.Balance = amount * 10
+
+	eurAccount := randomAccount(user2.ID)
+	eurAccount.Currency = util.EUR
+
+	authorize := func(store *mockdb.MockStore, member db.AccountMember) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(member, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTransferRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner))
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					EnforceLimits: true,
+					RiskDecision:  string(risk.Allow),
+					MatchedRules:  []string{},
+					Payee:         &db.PayeeTransferParams{UserID: user1.ID, AccountID: account2.ID},
+				}
+				result := db.TransferTxResult{
+					Transfer:    db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount, Status: db.TransferStatusCompleted},
+					FromAccount: account1,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusCompleted, res.GetTransfer().GetStatus())
+				require.Equal(t, account1.ID, res.GetFromAccount().GetId())
+			},
+		},
+		{
+			name: "PayeeCoolingOff",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner))
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				payee := db.Payee{ID: 7, UserID: user1.ID, AccountID: account2.ID}
+				store.EXPECT().
+					GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{UserID: user1.ID, AccountID: account2.ID})).
+					Times(1).
+					Return(payee, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+
+				// the account of a saved payee keeps its cooling-off limit when paid by id
+				store.EXPECT().
+					TransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.NotNil(t, arg.Payee)
+						require.Equal(t, payee.ID, arg.Payee.PayeeID)
+						require.Equal(t, user1.ID, arg.Payee.UserID)
+						return db.TransferTxResult{}, &db.CoolingOffLimitError{PayeeID: payee.ID}
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Error(t, err)
+				st, ok := status.FromError(err)
+				require.True(t, ok)
+				require.Equal(t, codes.FailedPrecondition, st.Code())
+			},
+		},
+		{
+			name: "AwaitingApproval",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner))
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				policy := db.AccountApprovalPolicy{AccountID: account1.ID, Threshold: amount - 1, RequiredApprovals: 1}
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(policy, nil)
+
+				result := db.RequestTransferApprovalTxResult{
+					Transfer: db.Transfer{ID: 1, Status: db.TransferStatusAwaitingApproval},
+				}
+				store.EXPECT().RequestTransferApprovalTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.TransferStatusAwaitingApproval, res.GetTransfer().GetStatus())
+				require.Nil(t, res.GetFromAccount())
+			},
+		},
+		{
+			name: "NotMember",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, db.AccountMember{})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "ViewerCannotTransfer",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleViewer))
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   eurAccount.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner))
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        account1.Balance + 1,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner))
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "ToAccountNotFound",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				authorize(store, newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner))
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidArguments",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        -1,
+				Currency:      "XYZ",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newAuthorizedContext(t, server, user1.Username)
+
+			res, err := server.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestCreateTransferStepUpRPC(t *testing.T) {
+	threshold := int64(100)
+	amount := int64(500)
+
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.ID)
+	account2 := randomAccount(user2.ID)
+	account1.Balance = amount * 2
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{UserID: user1.ID, Secret: secret, Status: db.MFAStatusEnabled}
+
+	step := util.TOTPStep(time.Now())
+	code, err := util.TOTPCode(secret, step)
+	require.NoError(t, err)
+
+	policy := db.LoginLockoutPolicy{
+		MaxFailedAttempts:  5,
+		LockoutDuration:    time.Minute,
+		MaxLockoutDuration: time.Hour,
+	}
+
+	// buildTransferStubs expects the lookups that happen before the step-up check
+	buildTransferStubs := func(store *mockdb.MockStore) {
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+		store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user1.Username)).Times(1).Return(user1, nil)
+		store.EXPECT().GetAccountMember(gomock.Any(), gomock.Any()).Times(1).Return(newAccountMember(account1.ID, user1.ID, db.AccountRoleOwner), nil)
+		store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+	}
+
+	testCases := []struct {
+		name          string
+		mfaCode       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name:    "OK",
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().
+					UseUserMFAStep(gomock.Any(), gomock.Eq(db.UseUserMFAStepParams{UserID: user1.ID, LastUsedStep: step})).
+					Times(1).
+					Return(mfa, nil)
+				store.EXPECT().GetAccountApprovalPolicy(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.AccountApprovalPolicy{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "CodeRequired",
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:    "ReplayedCode",
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Time{}, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(1).Return(db.UserMfa{}, sql.ErrNoRows)
+				store.EXPECT().
+					RecordLoginFailureTx(gomock.Any(), gomock.Eq(db.RecordLoginFailureTxParams{Username: user1.Username, Policy: policy})).
+					Times(1).
+					Return(db.RecordLoginFailureTxResult{}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:    "LockedOut",
+			mfaCode: code,
+			buildStubs: func(store *mockdb.MockStore) {
+				buildTransferStubs(store)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user1.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Times(1).Return(time.Now().Add(time.Minute), nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.Equal(t, codes.ResourceExhausted, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			server.config.StepUpTransferThreshold = threshold
+			server.loginPolicy = policy
+			ctx := newAuthorizedContext(t, server, user1.Username)
+
+			res, err := server.CreateTransfer(ctx, &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Amount:        amount,
+				Currency:      util.USD,
+				MfaCode:       tc.mfaCode,
+			})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strings"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var errInvalidCredentials = status.Error(codes.Unauthenticated, "incorrect username or password")
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if violations := validateLoginUserRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	ip := clientIP(ctx)
+	if err := server.checkLoginLockout(ctx, req.GetUsername(), ip); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, req.GetUsername())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			util.CheckPassword(req.GetPassword(), util.UnknownUserHashedPassword)
+			return nil, server.loginFailed(ctx, req.GetUsername(), ip, errInvalidCredentials)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find user: %s", err)
+	}
+
+	err = util.CheckPassword(req.GetPassword(), user.HashedPassword)
+	if err != nil {
+		return nil, server.loginFailed(ctx, req.GetUsername(), ip, errInvalidCredentials)
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %s", err)
+	}
+
+	if err == nil && mfa.Status == db.MFAStatusEnabled {
+		mfaToken, _, err := server.tokenMaker.CreatePurposeToken(user.Username, token.PurposeMFAChallenge, server.config.MFAChallengeDuration)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create MFA token: %s", err)
+		}
+
+		return &pb.LoginUserResponse{MfaToken: mfaToken}, nil
+	}
+
+	return server.completeLogin(ctx, user)
+}
+
+// completeLogin forgets the failed logins of the user and returns the access token
+func (server *Server) completeLogin(ctx context.Context, user db.User) (*pb.LoginUserResponse, error) {
+	if server.loginPolicy.Enabled() {
+		err := server.store.DeleteLoginThrottle(ctx, db.DeleteLoginThrottleParams{
+			Scope:   db.LoginScopeUsername,
+			Subject: user.Username,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to reset failed logins: %s", err)
+		}
+	}
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, server.config.AccessTokenDuration)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create access token: %s", err)
+	}
+
+	return &pb.LoginUserResponse{
+		AccessToken: accessToken,
+		User:        convertUser(user),
+	}, nil
+}
+
+// checkLoginLockout fails while the username or the client IP is locked, like the HTTP API does
+func (server *Server) checkLoginLockout(ctx context.Context, username string, ip string) error {
+	if !server.loginPolicy.Enabled() {
+		return nil
+	}
+
+	lockedUntil, err := server.store.GetLoginLockedUntil(ctx, db.GetLoginLockedUntilParams{
+		Username: username,
+		Ip:       ip,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to check login lockout: %s", err)
+	}
+
+	if lockedUntil.After(time.Now()) {
+		return status.Error(codes.ResourceExhausted, "too many failed logins, try again later")
+	}
+	return nil
+}
+
+// loginFailed counts the failure against the username and the client IP, and returns the failure
+func (server *Server) loginFailed(ctx context.Context, username string, ip string, failure error) error {
+	if server.loginPolicy.Enabled() {
+		_, err := server.store.RecordLoginFailureTx(ctx, db.RecordLoginFailureTxParams{
+			Username: username,
+			IP:       ip,
+			Policy:   server.loginPolicy,
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to record failed login: %s", err)
+		}
+	}
+
+	return failure
+}
+
+// clientIP is the address of the caller. Calls relayed by the gateway come from a loopback address,
+// for those the address of the HTTP client is the last one the gateway added to x-forwarded-for.
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get("x-forwarded-for"); len(forwarded) > 0 {
+			addresses := strings.Split(forwarded[len(forwarded)-1], ",")
+			return strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+
+	return host
+}
+
+func validateLoginUserRequest(req *pb.LoginUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := validateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err))
+	}
+	if err := validatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err))
+	}
+	return violations
+}
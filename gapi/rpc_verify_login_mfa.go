@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/token"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errInvalidMFAToken = status.Error(codes.Unauthenticated, "the login has expired, log in again")
+
+// VerifyLoginMFA is the second step of a login with two-factor authentication. Wrong codes count as
+// failed logins, so the lockout also stops codes from being guessed.
+func (server *Server) VerifyLoginMFA(ctx context.Context, req *pb.VerifyLoginMFARequest) (*pb.LoginUserResponse, error) {
+	if violations := validateVerifyLoginMFARequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(req.GetMfaToken())
+	if err != nil || payload.Purpose != token.PurposeMFAChallenge {
+		return nil, errInvalidMFAToken
+	}
+
+	ip := clientIP(ctx)
+	if err := server.checkLoginLockout(ctx, payload.Username, ip); err != nil {
+		return nil, err
+	}
+
+	user, err := server.store.GetUserByUsername(ctx, payload.Username)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to find user: %s", err)
+	}
+
+	// a password change cancels logins that were waiting for their second step
+	if payload.ValidSince(user.PasswordChangedAt) != nil {
+		return nil, errInvalidMFAToken
+	}
+
+	mfa, err := server.store.GetUserMFA(ctx, user.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, status.Errorf(codes.Internal, "failed to get two-factor authentication: %s", err)
+	}
+
+	if err == sql.ErrNoRows || mfa.Status != db.MFAStatusEnabled {
+		return nil, errInvalidMFAToken
+	}
+
+	ok, err := server.useMFACode(ctx, mfa, req.GetCode(), req.GetRecoveryCode())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to verify authentication code: %s", err)
+	}
+
+	if !ok {
+		return nil, server.loginFailed(ctx, payload.Username, ip, errInvalidMFACode)
+	}
+
+	return server.completeLogin(ctx, user)
+}
+
+func validateVerifyLoginMFARequest(req *pb.VerifyLoginMFARequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetMfaToken() == "" {
+		violations = append(violations, fieldViolation("mfa_token", fmt.Errorf("is required")))
+	}
+	if req.GetCode() == "" && req.GetRecoveryCode() == "" {
+		violations = append(violations, fieldViolation("code", fmt.Errorf("code or recovery_code is required")))
+	}
+	if err := validateMFACode(req.GetCode()); err != nil {
+		violations = append(violations, fieldViolation("code", err))
+	}
+	if len(req.GetRecoveryCode()) > 16 {
+		violations = append(violations, fieldViolation("recovery_code", fmt.Errorf("must be at most 16 characters")))
+	}
+	return violations
+}
@@ -0,0 +1,129 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVerifyLoginMFARPC(t *testing.T) {
+	user, _ := randomUser(t)
+
+	secret, err := util.RandomTOTPSecret()
+	require.NoError(t, err)
+	mfa := db.UserMfa{UserID: user.ID, Secret: secret, Status: db.MFAStatusEnabled}
+
+	step := util.TOTPStep(time.Now())
+	code, err := util.TOTPCode(secret, step)
+	require.NoError(t, err)
+	oldCode, err := util.TOTPCode(secret, step-10)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		code          string
+		purpose       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.LoginUserResponse, err error)
+	}{
+		{
+			name:    "OK",
+			code:    code,
+			purpose: token.PurposeMFAChallenge,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().
+					UseUserMFAStep(gomock.Any(), gomock.Eq(db.UseUserMFAStepParams{UserID: user.ID, LastUsedStep: step})).
+					Times(1).
+					Return(mfa, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+			},
+		},
+		{
+			name:    "InvalidCode",
+			code:    oldCode,
+			purpose: token.PurposeMFAChallenge,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Eq(user.ID)).Times(1).Return(mfa, nil)
+				store.EXPECT().UseUserMFAStep(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+				require.Equal(t, "incorrect authentication code", status.Convert(err).Message())
+			},
+		},
+		{
+			name:    "PasswordChangedDuringChallenge",
+			code:    code,
+			purpose: token.PurposeMFAChallenge,
+			buildStubs: func(store *mockdb.MockStore) {
+				changed := user
+				changed.PasswordChangedAt = time.Now().Add(time.Minute)
+
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(changed, nil)
+				store.EXPECT().GetUserMFA(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:    "AccessToken",
+			code:    code,
+			purpose: token.PurposeAccess,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:    "InvalidArguments",
+			code:    "abc",
+			purpose: token.PurposeMFAChallenge,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.LoginUserResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+
+			mfaToken, _, err := server.tokenMaker.CreatePurposeToken(user.Username, tc.purpose, time.Minute)
+			require.NoError(t, err)
+
+			res, err := server.VerifyLoginMFA(context.Background(), &pb.VerifyLoginMFARequest{MfaToken: mfaToken, Code: tc.code})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package gapi
+
+import (
+	"fmt"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/mail"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/ratelimit"
+	"github.com/khorsl/simple_bank/risk"
+	"github.com/khorsl/simple_bank/token"
+	"github.com/khorsl/simple_bank/util"
+)
+
+// Server serves gRPC requests for our banking service
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config     util.Config
+	store      db.Store
+	tokenMaker token.Maker
+	riskEngine *risk.Engine
+	limiter    ratelimit.Limiter
+	// loginPolicy locks usernames and client IPs after failed logins
+	loginPolicy db.LoginLockoutPolicy
+	mailer      mail.Mailer
+}
+
+// NewServer creates the gRPC server. The limiter holds the rate limit buckets and is shared with the
+// HTTP server so that a client has one limit whichever API it calls.
+func NewServer(config util.Config, store db.Store, limiter ratelimit.Limiter) (*Server, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	mailer, err := mail.NewMailerFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mailer: %w", err)
+	}
+
+	server := &Server{
+		config:      config,
+		store:       store,
+		tokenMaker:  tokenMaker,
+		riskEngine:  risk.NewEngineFromConfig(config, store),
+		limiter:     limiter,
+		loginPolicy: db.NewLoginLockoutPolicy(config),
+		mailer:      mailer,
+	}
+
+	return server, nil
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+
+	"github.com/khorsl/simple_bank/util"
+)
+
+var (
+	isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9]+$`).MatchString
+	isValidPhone    = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`).MatchString
+	isValidMFACode  = regexp.MustCompile(`^[0-9]{6}$`).MatchString
+)
+
+// The validators mirror the binding tags of the HTTP requests
+
+func validateUsername(value string) error {
+	if value == "" {
+		return fmt.Errorf("is required")
+	}
+	if !isValidUsername(value) {
+		return fmt.Errorf("must contain only letters and digits")
+	}
+	return nil
+}
+
+func validatePassword(value string) error {
+	if len(value) < 6 {
+		return fmt.Errorf("must contain at least 6 characters")
+	}
+	return nil
+}
+
+func validateFullName(value string) error {
+	if value == "" {
+		return fmt.Errorf("is required")
+	}
+	return nil
+}
+
+func validateEmail(value string) error {
+	if _, err := mail.ParseAddress(value); err != nil {
+		return fmt.Errorf("is not a valid email address")
+	}
+	return nil
+}
+
+// validatePhone accepts an empty phone, it is optional
+func validatePhone(value string) error {
+	if value != "" && !isValidPhone(value) {
+		return fmt.Errorf("must be an E.164 phone number")
+	}
+	return nil
+}
+
+func validateCurrency(value string) error {
+	if !util.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency %q", value)
+	}
+	return nil
+}
+
+// validateAccountType accepts an empty type, checking is used then
+func validateAccountType(value string) error {
+	if value != "" && !util.IsSupportedAccountType(value) {
+		return fmt.Errorf("unsupported account type %q", value)
+	}
+	return nil
+}
+
+func validateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive number")
+	}
+	return nil
+}
+
+// validateMFACode accepts an empty code, whether one is needed depends on the call
+func validateMFACode(value string) error {
+	if value != "" && !isValidMFACode(value) {
+		return fmt.Errorf("must be 6 digits")
+	}
+	return nil
+}
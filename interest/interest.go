@@ -0,0 +1,56 @@
+package interest
+
+import (
+	"math/big"
+	"time"
+)
+
+// MicrosPerUnit is the number of micros in one minor unit of a currency. Accruals are kept in
+// micros so that the daily amounts of small balances are not lost to rounding.
+const MicrosPerUnit = 1_000_000
+
+// basisPointsPerUnit is the number of basis points in a rate of 100%
+const basisPointsPerUnit = 10_000
+
+// DaysInYear returns the number of days in the year of date, used as the day count of an
+// actual/actual accrual
+func DaysInYear(date time.Time) int64 {
+	start := time.Date(date.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	return int64(start.AddDate(1, 0, 0).Sub(start).Hours() / 24)
+}
+
+// DailyAccrualMicros returns the interest in micros earned by an end of day balance at an
+// annual rate on date. The result is rounded down to the micro. Balances below zero earn nothing.
+func DailyAccrualMicros(balance int64, annualRateBps int32, date time.Time) int64 {
+	if balance <= 0 || annualRateBps <= 0 {
+		return 0
+	}
+
+	// balance * rate * micros / (10000 * days) overflows int64 for large balances
+	amount := new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(annualRateBps)))
+	amount.Mul(amount, big.NewInt(MicrosPerUnit))
+	amount.Quo(amount, big.NewInt(basisPointsPerUnit*DaysInYear(date)))
+
+	return amount.Int64()
+}
+
+// Split divides accrued micros into the whole minor units to post and the remainder to carry
+// to the next period
+func Split(micros int64) (amount int64, carry int64) {
+	return micros / MicrosPerUnit, micros % MicrosPerUnit
+}
+
+// Date truncates t to midnight UTC of its calendar day
+func Date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// MonthStart returns the first day of the month of date
+func MonthStart(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// IsMonthEnd reports whether date is the last day of its month
+func IsMonthEnd(date time.Time) bool {
+	return date.AddDate(0, 0, 1).Day() == 1
+}
@@ -0,0 +1,95 @@
+package interest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDaysInYear(t *testing.T) {
+	require.Equal(t, int64(365), DaysInYear(day(2023, time.June, 1)))
+	require.Equal(t, int64(366), DaysInYear(day(2024, time.June, 1)))
+}
+
+func TestDailyAccrualMicros(t *testing.T) {
+	testCases := []struct {
+		name    string
+		balance int64
+		rateBps int32
+		date    time.Time
+		micros  int64
+	}{
+		{
+			name:    "Exact",
+			balance: 365_000,
+			rateBps: 100,
+			date:    day(2023, time.March, 1),
+			micros:  10 * MicrosPerUnit,
+		},
+		{
+			name:    "RoundedDown",
+			balance: 100,
+			rateBps: 200,
+			date:    day(2023, time.March, 1),
+			// 100 * 0.02 / 365 = 0.005479452...
+			micros: 5479,
+		},
+		{
+			name:    "LeapYear",
+			balance: 366_000,
+			rateBps: 100,
+			date:    day(2024, time.March, 1),
+			micros:  10 * MicrosPerUnit,
+		},
+		{
+			name:    "NegativeBalance",
+			balance: -1000,
+			rateBps: 200,
+			date:    day(2023, time.March, 1),
+			micros:  0,
+		},
+		{
+			name:    "LargeBalance",
+			balance: 1 << 40,
+			rateBps: 10_000,
+			date:    day(2023, time.March, 1),
+			micros:  (1 << 40) * MicrosPerUnit / 365,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.micros, DailyAccrualMicros(tc.balance, tc.rateBps, tc.date))
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	// a month of accruals on a small balance
+	var total int64
+	for d := 1; d <= 31; d++ {
+		total += DailyAccrualMicros(100, 200, day(2023, time.January, d))
+	}
+
+	amount, carry := Split(total)
+	require.Zero(t, amount)
+	require.Equal(t, total, carry)
+
+	amount, carry = Split(2*MicrosPerUnit + 123)
+	require.Equal(t, int64(2), amount)
+	require.Equal(t, int64(123), carry)
+}
+
+func TestIsMonthEnd(t *testing.T) {
+	require.True(t, IsMonthEnd(day(2024, time.February, 29)))
+	require.False(t, IsMonthEnd(day(2023, time.February, 27)))
+	require.True(t, IsMonthEnd(day(2023, time.December, 31)))
+	require.Equal(t, day(2023, time.December, 1), MonthStart(day(2023, time.December, 31)))
+}
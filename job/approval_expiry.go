@@ -0,0 +1,37 @@
+package job
+
+import (
+	"context"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+// expiryBatchSize is the number of expired transfers released per query
+const expiryBatchSize = 100
+
+// ExpireTransferApprovals releases every transfer whose approval window has passed and returns
+// how many were expired
+func ExpireTransferApprovals(ctx context.Context, store db.Store) (int, error) {
+	expired := 0
+
+	for {
+		ids, err := store.ListExpiredTransferApprovals(ctx, expiryBatchSize)
+		if err != nil {
+			return expired, err
+		}
+
+		for _, id := range ids {
+			transfer, err := store.ExpireTransferApprovalTx(ctx, id)
+			if err != nil {
+				return expired, err
+			}
+			if transfer.Status == db.TransferStatusExpired {
+				expired++
+			}
+		}
+
+		if len(ids) < expiryBatchSize {
+			return expired, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpireTransferApprovals(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, expired int, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpiredTransferApprovals(gomock.Any(), gomock.Eq(int32(expiryBatchSize))).
+					Times(1).
+					Return([]int64{1, 2}, nil)
+				store.EXPECT().
+					ExpireTransferApprovalTx(gomock.Any(), gomock.Eq(int64(1))).
+					Times(1).
+					Return(db.Transfer{ID: 1, Status: db.TransferStatusExpired}, nil)
+				// decided before the job got to it
+				store.EXPECT().
+					ExpireTransferApprovalTx(gomock.Any(), gomock.Eq(int64(2))).
+					Times(1).
+					Return(db.Transfer{ID: 2, Status: db.TransferStatusCompleted}, nil)
+			},
+			checkResponse: func(t *testing.T, expired int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, expired)
+			},
+		},
+		{
+			name: "FullBatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				ids := make([]int64, expiryBatchSize)
+				for i := range ids {
+					ids[i] = int64(i + 1)
+				}
+
+				gomock.InOrder(
+					store.EXPECT().
+						ListExpiredTransferApprovals(gomock.Any(), gomock.Any()).
+						Return(ids, nil),
+					store.EXPECT().
+						ListExpiredTransferApprovals(gomock.Any(), gomock.Any()).
+						Return([]int64{}, nil),
+				)
+				store.EXPECT().
+					ExpireTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(expiryBatchSize).
+					Return(db.Transfer{Status: db.TransferStatusExpired}, nil)
+			},
+			checkResponse: func(t *testing.T, expired int, err error) {
+				require.NoError(t, err)
+				require.Equal(t, expiryBatchSize, expired)
+			},
+		},
+		{
+			name: "ExpireError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExpiredTransferApprovals(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]int64{1}, nil)
+				store.EXPECT().
+					ExpireTransferApprovalTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Transfer{}, errors.New("tx error"))
+			},
+			checkResponse: func(t *testing.T, expired int, err error) {
+				require.Error(t, err)
+				require.Zero(t, expired)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			expired, err := ExpireTransferApprovals(context.Background(), store)
+			tc.checkResponse(t, expired, err)
+		})
+	}
+}
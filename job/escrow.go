@@ -0,0 +1,69 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+// escrowBatchSize is the number of due escrows read per query
+const escrowBatchSize = 100
+
+// EscrowReleaseResult counts the work done by a run of the escrow release job
+type EscrowReleaseResult struct {
+	Released int
+	Failed   int
+}
+
+// ReleaseDueEscrows pays the seller of every held escrow whose release time is at or before now.
+// Escrows that were disputed or confirmed in the meantime are skipped, escrows whose seller account
+// is not active stay held and are retried on the next run.
+func ReleaseDueEscrows(ctx context.Context, store db.Store, now time.Time) (EscrowReleaseResult, error) {
+	var result EscrowReleaseResult
+
+	system, err := store.GetUserByUsername(ctx, db.SystemUsername)
+	if err != nil {
+		return result, err
+	}
+
+	afterID := int64(0)
+
+	for {
+		ids, err := store.ListDueEscrows(ctx, db.ListDueEscrowsParams{
+			ReleaseAt: now,
+			AfterID:   afterID,
+			Limit:     escrowBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, id := range ids {
+			afterID = id
+
+			_, err := store.ChangeEscrowStatusTx(ctx, db.ChangeEscrowStatusTxParams{
+				EscrowID:   id,
+				FromStatus: db.EscrowStatusHeld,
+				Status:     db.EscrowStatusReleased,
+				ChangedBy:  system.ID,
+				OnlyIfDue:  true,
+			})
+			var notActiveErr *db.AccountNotActiveError
+			switch {
+			case err == nil:
+				result.Released++
+			case errors.As(err, &notActiveErr):
+				result.Failed++
+			case errors.Is(err, db.ErrEscrowStatusChanged):
+			default:
+				return result, err
+			}
+		}
+
+		if len(ids) < escrowBatchSize {
+			return result, nil
+		}
+	}
+}
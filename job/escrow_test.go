@@ -0,0 +1,99 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReleaseDueEscrows(t *testing.T) {
+	now := time.Date(2023, time.March, 14, 18, 30, 0, 0, time.UTC)
+	system := db.User{ID: 1, Username: db.SystemUsername}
+
+	release := func(id int64) db.ChangeEscrowStatusTxParams {
+		return db.ChangeEscrowStatusTxParams{
+			EscrowID:   id,
+			FromStatus: db.EscrowStatusHeld,
+			Status:     db.EscrowStatusReleased,
+			ChangedBy:  system.ID,
+			OnlyIfDue:  true,
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, result EscrowReleaseResult, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Eq(db.SystemUsername)).Times(1).Return(system, nil)
+				store.EXPECT().
+					ListDueEscrows(gomock.Any(), gomock.Eq(db.ListDueEscrowsParams{
+						ReleaseAt: now,
+						AfterID:   0,
+						Limit:     escrowBatchSize,
+					})).
+					Times(1).
+					Return([]int64{1, 2, 3}, nil)
+				store.EXPECT().
+					ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(release(1))).
+					Times(1).
+					Return(db.ChangeEscrowStatusTxResult{}, nil)
+				store.EXPECT().
+					ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(release(2))).
+					Times(1).
+					Return(db.ChangeEscrowStatusTxResult{}, &db.AccountNotActiveError{AccountID: 1, Status: db.AccountStatusFrozen})
+				store.EXPECT().
+					ChangeEscrowStatusTx(gomock.Any(), gomock.Eq(release(3))).
+					Times(1).
+					Return(db.ChangeEscrowStatusTxResult{}, db.ErrEscrowStatusChanged)
+			},
+			checkResponse: func(t *testing.T, result EscrowReleaseResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, result.Released)
+				require.Equal(t, 1, result.Failed)
+			},
+		},
+		{
+			name: "ReleaseError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Times(1).Return(system, nil)
+				store.EXPECT().
+					ListDueEscrows(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]int64{1, 2}, nil)
+				store.EXPECT().
+					ChangeEscrowStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ChangeEscrowStatusTxResult{}, errors.New("tx error"))
+			},
+			checkResponse: func(t *testing.T, result EscrowReleaseResult, err error) {
+				require.Error(t, err)
+				require.Zero(t, result.Released)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			result, err := ReleaseDueEscrows(context.Background(), store, now)
+			tc.checkResponse(t, result, err)
+		})
+	}
+}
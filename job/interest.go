@@ -0,0 +1,158 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/interest"
+)
+
+// interestBatchSize is the number of accounts read per query by the interest jobs
+const interestBatchSize = 100
+
+// InterestRunResult counts the work done by a run of the interest job
+type InterestRunResult struct {
+	Accrued          int
+	OverdraftAccrued int
+	Capitalized      int
+}
+
+// RunInterest accrues interest and overdraft interest for date and, when date is the last day of a month, capitalizes
+// the interest of that month. Accruals and postings that already exist are skipped, so the job
+// can be re-run for any date.
+func RunInterest(ctx context.Context, store db.Store, date time.Time) (InterestRunResult, error) {
+	var result InterestRunResult
+	var err error
+
+	date = interest.Date(date)
+	result.Accrued, err = AccrueInterest(ctx, store, date)
+	if err != nil {
+		return result, err
+	}
+
+	result.OverdraftAccrued, err = AccrueOverdraftInterest(ctx, store, date)
+	if err != nil || !interest.IsMonthEnd(date) {
+		return result, err
+	}
+
+	result.Capitalized, err = CapitalizeInterest(ctx, store, interest.MonthStart(date))
+	return result, err
+}
+
+// AccrueInterest records the daily interest of every interest bearing account based on its
+// balance at the end of date (UTC) and returns how many accruals were created
+func AccrueInterest(ctx context.Context, store db.Store, date time.Time) (int, error) {
+	accrued := 0
+	afterID := int64(0)
+
+	for {
+		accounts, err := store.ListInterestBearingAccounts(ctx, db.ListInterestBearingAccountsParams{
+			EndOfDay: date.AddDate(0, 0, 1),
+			AfterID:  afterID,
+			Limit:    interestBatchSize,
+		})
+		if err != nil {
+			return accrued, err
+		}
+
+		for _, account := range accounts {
+			created, err := store.CreateInterestAccrual(ctx, db.CreateInterestAccrualParams{
+				AccountID:     account.ID,
+				AccrualDate:   date,
+				Balance:       account.EndOfDayBalance,
+				AnnualRateBps: account.AnnualRateBps,
+				AmountMicros:  interest.DailyAccrualMicros(account.EndOfDayBalance, account.AnnualRateBps, date),
+			})
+			if err != nil {
+				return accrued, err
+			}
+			accrued += int(created)
+			afterID = account.ID
+		}
+
+		if len(accounts) < interestBatchSize {
+			return accrued, nil
+		}
+	}
+}
+
+// AccrueOverdraftInterest records the daily interest owed by every account with an overdraft rate
+// whose balance at the end of date (UTC) was below zero and returns how many accruals were created.
+// The accruals are not charged to the accounts.
+func AccrueOverdraftInterest(ctx context.Context, store db.Store, date time.Time) (int, error) {
+	accrued := 0
+	afterID := int64(0)
+
+	for {
+		accounts, err := store.ListOverdraftAccounts(ctx, db.ListOverdraftAccountsParams{
+			EndOfDay: date.AddDate(0, 0, 1),
+			AfterID:  afterID,
+			Limit:    interestBatchSize,
+		})
+		if err != nil {
+			return accrued, err
+		}
+
+		for _, account := range accounts {
+			afterID = account.ID
+			if account.EndOfDayBalance >= 0 {
+				continue
+			}
+
+			created, err := store.CreateOverdraftAccrual(ctx, db.CreateOverdraftAccrualParams{
+				AccountID:     account.ID,
+				AccrualDate:   date,
+				Balance:       account.EndOfDayBalance,
+				AnnualRateBps: account.OverdraftRateBps,
+				AmountMicros:  interest.DailyAccrualMicros(-account.EndOfDayBalance, account.OverdraftRateBps, date),
+			})
+			if err != nil {
+				return accrued, err
+			}
+			accrued += int(created)
+		}
+
+		if len(accounts) < interestBatchSize {
+			return accrued, nil
+		}
+	}
+}
+
+// CapitalizeInterest posts the interest accrued during the month starting at periodStart to every
+// account that has not been credited for it yet and returns how many accounts were capitalized
+func CapitalizeInterest(ctx context.Context, store db.Store, periodStart time.Time) (int, error) {
+	capitalized := 0
+	afterID := int64(0)
+
+	for {
+		ids, err := store.ListUnpostedInterestAccounts(ctx, db.ListUnpostedInterestAccountsParams{
+			PeriodStart: periodStart,
+			PeriodEnd:   periodStart.AddDate(0, 1, 0),
+			AfterID:     afterID,
+			Limit:       interestBatchSize,
+		})
+		if err != nil {
+			return capitalized, err
+		}
+
+		for _, id := range ids {
+			_, err := store.CapitalizeInterestTx(ctx, db.CapitalizeInterestTxParams{
+				AccountID:   id,
+				PeriodStart: periodStart,
+			})
+			if err != nil && !errors.Is(err, db.ErrInterestAlreadyPosted) {
+				return capitalized, err
+			}
+			if err == nil {
+				capitalized++
+			}
+			afterID = id
+		}
+
+		if len(ids) < interestBatchSize {
+			return capitalized, nil
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInterest(t *testing.T) {
+	midMonth := time.Date(2023, time.March, 14, 18, 30, 0, 0, time.UTC)
+	monthEnd := time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC)
+	periodStart := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		date          time.Time
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, result InterestRunResult, err error)
+	}{
+		{
+			name: "Accrue",
+			date: midMonth,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListInterestBearingAccounts(gomock.Any(), gomock.Eq(db.ListInterestBearingAccountsParams{
+						EndOfDay: time.Date(2023, time.March, 15, 0, 0, 0, 0, time.UTC),
+						AfterID:  0,
+						Limit:    interestBatchSize,
+					})).
+					Times(1).
+					Return([]db.ListInterestBearingAccountsRow{
+						{ID: 1, AnnualRateBps: 100, EndOfDayBalance: 365_000},
+						{ID: 2, AnnualRateBps: 100, EndOfDayBalance: -10},
+					}, nil)
+				store.EXPECT().
+					CreateInterestAccrual(gomock.Any(), gomock.Eq(db.CreateInterestAccrualParams{
+						AccountID:     1,
+						AccrualDate:   time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC),
+						Balance:       365_000,
+						AnnualRateBps: 100,
+						AmountMicros:  10_000_000,
+					})).
+					Times(1).
+					Return(int64(1), nil)
+				// already accrued by an earlier run
+				store.EXPECT().
+					CreateInterestAccrual(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+				store.EXPECT().
+					ListOverdraftAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListOverdraftAccountsRow{
+						{ID: 3, OverdraftRateBps: 1000, EndOfDayBalance: -36_500},
+						{ID: 4, OverdraftRateBps: 1000, EndOfDayBalance: 100},
+					}, nil)
+				store.EXPECT().
+					CreateOverdraftAccrual(gomock.Any(), gomock.Eq(db.CreateOverdraftAccrualParams{
+						AccountID:     3,
+						AccrualDate:   time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC),
+						Balance:       -36_500,
+						AnnualRateBps: 1000,
+						AmountMicros:  10_000_000,
+					})).
+					Times(1).
+					Return(int64(1), nil)
+				store.EXPECT().
+					ListUnpostedInterestAccounts(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, result InterestRunResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, result.Accrued)
+				require.Equal(t, 1, result.OverdraftAccrued)
+				require.Zero(t, result.Capitalized)
+			},
+		},
+		{
+			name: "MonthEnd",
+			date: monthEnd,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListInterestBearingAccountsRow{}, nil)
+				store.EXPECT().
+					ListOverdraftAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListOverdraftAccountsRow{}, nil)
+				store.EXPECT().
+					ListUnpostedInterestAccounts(gomock.Any(), gomock.Eq(db.ListUnpostedInterestAccountsParams{
+						PeriodStart: periodStart,
+						PeriodEnd:   time.Date(2023, time.April, 1, 0, 0, 0, 0, time.UTC),
+						AfterID:     0,
+						Limit:       interestBatchSize,
+					})).
+					Times(1).
+					Return([]int64{1, 2}, nil)
+				store.EXPECT().
+					CapitalizeInterestTx(gomock.Any(), gomock.Eq(db.CapitalizeInterestTxParams{AccountID: 1, PeriodStart: periodStart})).
+					Times(1).
+					Return(db.CapitalizeInterestTxResult{}, nil)
+				// posted by a concurrent run
+				store.EXPECT().
+					CapitalizeInterestTx(gomock.Any(), gomock.Eq(db.CapitalizeInterestTxParams{AccountID: 2, PeriodStart: periodStart})).
+					Times(1).
+					Return(db.CapitalizeInterestTxResult{}, db.ErrInterestAlreadyPosted)
+			},
+			checkResponse: func(t *testing.T, result InterestRunResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, result.Capitalized)
+			},
+		},
+		{
+			name: "CapitalizeError",
+			date: monthEnd,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListInterestBearingAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListInterestBearingAccountsRow{}, nil)
+				store.EXPECT().
+					ListOverdraftAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.ListOverdraftAccountsRow{}, nil)
+				store.EXPECT().
+					ListUnpostedInterestAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]int64{1}, nil)
+				store.EXPECT().
+					CapitalizeInterestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CapitalizeInterestTxResult{}, errors.New("tx error"))
+			},
+			checkResponse: func(t *testing.T, result InterestRunResult, err error) {
+				require.Error(t, err)
+				require.Zero(t, result.Capitalized)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			result, err := RunInterest(context.Background(), store, tc.date)
+			tc.checkResponse(t, result, err)
+		})
+	}
+}
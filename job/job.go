@@ -0,0 +1,26 @@
+package job
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunEvery calls fn once per interval until the context is cancelled. Errors are logged,
+// the next run is still scheduled.
+func RunEvery(ctx context.Context, name string, interval time.Duration, fn func(ctx context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := fn(ctx)
+			if err != nil {
+				log.Printf("job %s failed: %v", name, err)
+			}
+		}
+	}
+}
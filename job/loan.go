@@ -0,0 +1,68 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/interest"
+)
+
+// loanBatchSize is the number of due installments read per query
+const loanBatchSize = 100
+
+// LoanCollectionResult counts the work done by a run of the loan collection job
+type LoanCollectionResult struct {
+	Collected int
+	Failed    int
+	Overdue   int
+}
+
+// CollectLoanRepayments debits every unpaid installment due on or before date. Installments that
+// cannot be collected stay unpaid and are retried on the next run. Installments still unpaid after
+// their due date become overdue and are charged lateFee once.
+func CollectLoanRepayments(ctx context.Context, store db.Store, date time.Time, lateFee int64) (LoanCollectionResult, error) {
+	var result LoanCollectionResult
+
+	date = interest.Date(date)
+	afterID := int64(0)
+
+	for {
+		installments, err := store.ListDueLoanInstallments(ctx, db.ListDueLoanInstallmentsParams{
+			DueDate: date,
+			AfterID: afterID,
+			Limit:   loanBatchSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, installment := range installments {
+			afterID = installment.ID
+
+			_, err := store.CollectLoanInstallmentTx(ctx, installment.ID)
+			var notActiveErr *db.AccountNotActiveError
+			switch {
+			case err == nil:
+				result.Collected++
+			case errors.Is(err, db.ErrInsufficientFunds), errors.As(err, &notActiveErr):
+				result.Failed++
+			case errors.Is(err, db.ErrInstallmentAlreadyPaid):
+			default:
+				return result, err
+			}
+		}
+
+		if len(installments) < loanBatchSize {
+			break
+		}
+	}
+
+	overdue, err := store.MarkLoanInstallmentsOverdue(ctx, db.MarkLoanInstallmentsOverdueParams{
+		LateFee:   lateFee,
+		DueBefore: date,
+	})
+	result.Overdue = len(overdue)
+	return result, err
+}
@@ -0,0 +1,103 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectLoanRepayments(t *testing.T) {
+	now := time.Date(2023, time.March, 14, 18, 30, 0, 0, time.UTC)
+	today := time.Date(2023, time.March, 14, 0, 0, 0, 0, time.UTC)
+	lateFee := int64(25)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, result LoanCollectionResult, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDueLoanInstallments(gomock.Any(), gomock.Eq(db.ListDueLoanInstallmentsParams{
+						DueDate: today,
+						AfterID: 0,
+						Limit:   loanBatchSize,
+					})).
+					Times(1).
+					Return([]db.LoanInstallment{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}, nil)
+				store.EXPECT().
+					CollectLoanInstallmentTx(gomock.Any(), gomock.Eq(int64(1))).
+					Times(1).
+					Return(db.CollectLoanInstallmentTxResult{}, nil)
+				store.EXPECT().
+					CollectLoanInstallmentTx(gomock.Any(), gomock.Eq(int64(2))).
+					Times(1).
+					Return(db.CollectLoanInstallmentTxResult{}, db.ErrInsufficientFunds)
+				store.EXPECT().
+					CollectLoanInstallmentTx(gomock.Any(), gomock.Eq(int64(3))).
+					Times(1).
+					Return(db.CollectLoanInstallmentTxResult{}, &db.AccountNotActiveError{AccountID: 1, Status: db.AccountStatusFrozen})
+				store.EXPECT().
+					CollectLoanInstallmentTx(gomock.Any(), gomock.Eq(int64(4))).
+					Times(1).
+					Return(db.CollectLoanInstallmentTxResult{}, db.ErrInstallmentAlreadyPaid)
+				store.EXPECT().
+					MarkLoanInstallmentsOverdue(gomock.Any(), gomock.Eq(db.MarkLoanInstallmentsOverdueParams{
+						LateFee:   lateFee,
+						DueBefore: today,
+					})).
+					Times(1).
+					Return([]db.LoanInstallment{{ID: 2}}, nil)
+			},
+			checkResponse: func(t *testing.T, result LoanCollectionResult, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, result.Collected)
+				require.Equal(t, 2, result.Failed)
+				require.Equal(t, 1, result.Overdue)
+			},
+		},
+		{
+			name: "CollectError",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListDueLoanInstallments(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.LoanInstallment{{ID: 1}}, nil)
+				store.EXPECT().
+					CollectLoanInstallmentTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.CollectLoanInstallmentTxResult{}, errors.New("tx error"))
+				store.EXPECT().
+					MarkLoanInstallmentsOverdue(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, result LoanCollectionResult, err error) {
+				require.Error(t, err)
+				require.Zero(t, result.Collected)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			result, err := CollectLoanRepayments(context.Background(), store, now, lateFee)
+			tc.checkResponse(t, result, err)
+		})
+	}
+}
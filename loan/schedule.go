@@ -0,0 +1,124 @@
+package loan
+
+import (
+	"errors"
+	"math/big"
+	"time"
+)
+
+// Amortization methods
+const (
+	// Annuity repays the loan with equal installments, the principal part grows as interest shrinks
+	Annuity = "annuity"
+	// EqualPrincipal repays the same principal every month plus the interest on what is left
+	EqualPrincipal = "equal_principal"
+)
+
+var ErrUnsupportedMethod = errors.New("unsupported amortization method")
+
+// Installment is one monthly repayment of a schedule. Amounts are in minor units.
+type Installment struct {
+	Seq       int32
+	DueDate   time.Time
+	Principal int64
+	Interest  int64
+}
+
+// Amount is what the borrower pays for the installment
+func (installment Installment) Amount() int64 {
+	return installment.Principal + installment.Interest
+}
+
+// Schedule returns the monthly installments repaying principal over termMonths at an annual rate.
+// The first installment is due one month after start. Interest of each month is charged on the
+// outstanding principal and rounded half up to the minor unit; the last installment repays
+// whatever principal is left so that the principal parts always add up to the loan.
+func Schedule(principal int64, annualRateBps int32, termMonths int32, method string, start time.Time) ([]Installment, error) {
+	var payment int64
+	switch method {
+	case Annuity:
+		payment = annuityPayment(principal, annualRateBps, termMonths)
+	case EqualPrincipal:
+	default:
+		return nil, ErrUnsupportedMethod
+	}
+
+	installments := make([]Installment, termMonths)
+	outstanding := principal
+
+	for i := range installments {
+		remaining := int64(termMonths) - int64(i)
+		installment := Installment{
+			Seq:      int32(i + 1),
+			DueDate:  addMonths(start, i+1),
+			Interest: monthlyInterest(outstanding, annualRateBps),
+		}
+
+		switch {
+		case remaining == 1:
+			installment.Principal = outstanding
+		case method == Annuity:
+			installment.Principal = payment - installment.Interest
+			if installment.Principal > outstanding {
+				installment.Principal = outstanding
+			}
+		default:
+			installment.Principal = outstanding / remaining
+		}
+
+		outstanding -= installment.Principal
+		installments[i] = installment
+	}
+
+	return installments, nil
+}
+
+// addMonths moves date by months, keeping it on the last day of the month when the target month
+// is shorter, so a loan started on Jan 31 is due on Feb 28 and not on Mar 3
+func addMonths(date time.Time, months int) time.Time {
+	firstOfMonth := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+// monthlyInterest returns balance * rate / 12 rounded half up to the minor unit
+func monthlyInterest(balance int64, annualRateBps int32) int64 {
+	interest := new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(balance), big.NewInt(int64(annualRateBps))),
+		big.NewInt(12*10_000),
+	)
+	return roundHalfUp(interest)
+}
+
+// annuityPayment returns P * r / (1 - (1 + r)^-n) rounded half up, with r the monthly rate
+func annuityPayment(principal int64, annualRateBps int32, termMonths int32) int64 {
+	if annualRateBps == 0 {
+		return roundHalfUp(big.NewRat(principal, int64(termMonths)))
+	}
+
+	rate := big.NewRat(int64(annualRateBps), 12*10_000)
+
+	// (1 + r)^n is exact as a rational, terms are at most a few hundred months
+	growth := big.NewRat(1, 1)
+	onePlusRate := new(big.Rat).Add(big.NewRat(1, 1), rate)
+	for i := int32(0); i < termMonths; i++ {
+		growth.Mul(growth, onePlusRate)
+	}
+
+	// P * r * g / (g - 1) is the same as P * r / (1 - g^-1)
+	payment := new(big.Rat).Mul(new(big.Rat).SetInt64(principal), rate)
+	payment.Mul(payment, growth)
+	payment.Quo(payment, new(big.Rat).Sub(growth, big.NewRat(1, 1)))
+
+	return roundHalfUp(payment)
+}
+
+func roundHalfUp(value *big.Rat) int64 {
+	half := new(big.Rat).Add(value, big.NewRat(1, 2))
+	return new(big.Int).Quo(half.Num(), half.Denom()).Int64()
+}
@@ -0,0 +1,97 @@
+package loan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnuitySchedule(t *testing.T) {
+	start := time.Date(2023, time.January, 31, 0, 0, 0, 0, time.UTC)
+
+	// 1000.00 at 12% over 12 months is 88.85 a month
+	installments, err := Schedule(100_000, 1200, 12, Annuity, start)
+	require.NoError(t, err)
+	require.Len(t, installments, 12)
+
+	require.Equal(t, int32(1), installments[0].Seq)
+	require.Equal(t, int64(1000), installments[0].Interest)
+	require.Equal(t, int64(7885), installments[0].Principal)
+	require.Equal(t, int64(8885), installments[0].Amount())
+
+	require.Equal(t, time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC), installments[0].DueDate)
+	require.Equal(t, time.Date(2023, time.March, 31, 0, 0, 0, 0, time.UTC), installments[1].DueDate)
+
+	for _, installment := range installments[:11] {
+		require.Equal(t, int64(8885), installment.Amount())
+	}
+	require.InDelta(t, 8885, installments[11].Amount(), 5)
+
+	requireFullyRepaid(t, 100_000, installments)
+}
+
+func TestEqualPrincipalSchedule(t *testing.T) {
+	start := time.Date(2023, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+	installments, err := Schedule(1000, 1200, 3, EqualPrincipal, start)
+	require.NoError(t, err)
+	require.Len(t, installments, 3)
+
+	require.Equal(t, []int64{333, 333, 334}, []int64{
+		installments[0].Principal,
+		installments[1].Principal,
+		installments[2].Principal,
+	})
+	require.Equal(t, []int64{10, 7, 3}, []int64{
+		installments[0].Interest,
+		installments[1].Interest,
+		installments[2].Interest,
+	})
+	require.Equal(t, time.Date(2023, time.February, 15, 0, 0, 0, 0, time.UTC), installments[0].DueDate)
+
+	requireFullyRepaid(t, 1000, installments)
+}
+
+func TestZeroRateSchedule(t *testing.T) {
+	for _, method := range []string{Annuity, EqualPrincipal} {
+		installments, err := Schedule(100, 0, 3, method, time.Now())
+		require.NoError(t, err)
+
+		for _, installment := range installments {
+			require.Zero(t, installment.Interest)
+		}
+		requireFullyRepaid(t, 100, installments)
+	}
+}
+
+func TestRandomSchedules(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		principal := util.RandomInt(1, 10_000_000)
+		rate := int32(util.RandomInt(0, 3000))
+		term := int32(util.RandomInt(1, 360))
+
+		for _, method := range []string{Annuity, EqualPrincipal} {
+			installments, err := Schedule(principal, rate, term, method, time.Now())
+			require.NoError(t, err)
+			require.Len(t, installments, int(term))
+			requireFullyRepaid(t, principal, installments)
+		}
+	}
+}
+
+func TestUnsupportedMethod(t *testing.T) {
+	_, err := Schedule(100, 0, 3, "balloon", time.Now())
+	require.ErrorIs(t, err, ErrUnsupportedMethod)
+}
+
+func requireFullyRepaid(t *testing.T, principal int64, installments []Installment) {
+	var repaid int64
+	for _, installment := range installments {
+		require.GreaterOrEqual(t, installment.Principal, int64(0))
+		require.GreaterOrEqual(t, installment.Interest, int64(0))
+		repaid += installment.Principal
+	}
+	require.Equal(t, principal, repaid)
+}
@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+)
+
+// FileMailer writes each email to an .eml file in a directory instead of sending it, so the links of
+// verification and password reset emails can be followed without a mail server
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir string, from string) (*FileMailer, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail directory is required")
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("cannot create mail directory: %w", err)
+	}
+
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (mailer *FileMailer) Send(ctx context.Context, email Email) error {
+	now := time.Now()
+
+	msg, err := email.message(mailer.from, now)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), util.RandomString(6))
+	return os.WriteFile(filepath.Join(mailer.dir, name), msg, 0o600)
+}
@@ -0,0 +1,26 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// LogMailer writes emails to the log instead of sending them
+type LogMailer struct {
+	from string
+}
+
+func NewLogMailer(from string) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (mailer *LogMailer) Send(ctx context.Context, email Email) error {
+	msg, err := email.message(mailer.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	log.Printf("email to %s:\n%s", email.To, msg)
+	return nil
+}
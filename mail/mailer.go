@@ -0,0 +1,77 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+)
+
+// Mail drivers that can be configured with MAIL_DRIVER
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+var errHeaderInjection = errors.New("email header contains a line break")
+
+// Email is a plain text email to a single recipient
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails. SMTPMailer delivers them, FileMailer and LogMailer stand in for a mail
+// server during development and tests.
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// NewMailerFromConfig creates the mailer of the configured driver, the log driver when none is set
+func NewMailerFromConfig(config util.Config) (Mailer, error) {
+	switch config.MailDriver {
+	case DriverSMTP:
+		return NewSMTPMailer(config.SMTPHost, config.SMTPPort, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	case DriverFile:
+		return NewFileMailer(config.MailDir, config.MailFrom)
+	case DriverLog, "":
+		return NewLogMailer(config.MailFrom), nil
+	}
+	return nil, fmt.Errorf("unknown mail driver %q", config.MailDriver)
+}
+
+// message formats the email as an RFC 5322 message
+func (email Email) message(from string, date time.Time) ([]byte, error) {
+	for _, header := range []string{from, email.To, email.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errHeaderInjection
+		}
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", email.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", email.Subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.ReplaceAll(email.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return msg.Bytes(), nil
+}
+
+// parseAddress returns the bare address of a sender or recipient such as "Simple Bank <no-reply@example.com>"
+func parseAddress(address string) (string, error) {
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil {
+		return "", fmt.Errorf("invalid email address %q: %w", address, err)
+	}
+	return parsed.Address, nil
+}
@@ -0,0 +1,84 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+const testFrom = "Simple Bank <no-reply@example.com>"
+
+func TestNewMailerFromConfig(t *testing.T) {
+	mailer, err := NewMailerFromConfig(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &LogMailer{}, mailer)
+
+	mailer, err = NewMailerFromConfig(util.Config{MailDriver: DriverFile, MailDir: t.TempDir(), MailFrom: testFrom})
+	require.NoError(t, err)
+	require.IsType(t, &FileMailer{}, mailer)
+
+	mailer, err = NewMailerFromConfig(util.Config{MailDriver: DriverSMTP, SMTPHost: "localhost", SMTPPort: 25, MailFrom: testFrom})
+	require.NoError(t, err)
+	require.IsType(t, &SMTPMailer{}, mailer)
+
+	_, err = NewMailerFromConfig(util.Config{MailDriver: DriverSMTP, MailFrom: testFrom})
+	require.Error(t, err)
+
+	_, err = NewMailerFromConfig(util.Config{MailDriver: "carrier-pigeon"})
+	require.Error(t, err)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	mailer, err := NewFileMailer(dir, testFrom)
+	require.NoError(t, err)
+
+	email := NewPasswordResetEmail("https://bank.example.com", "alice@example.com", "Alice", "abc-123_XYZ", 30*time.Minute)
+	require.NoError(t, mailer.Send(context.Background(), email))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.True(t, strings.HasSuffix(files[0].Name(), ".eml"))
+
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+
+	msg := string(data)
+	require.Contains(t, msg, "From: "+testFrom+"\r\n")
+	require.Contains(t, msg, "To: alice@example.com\r\n")
+	require.Contains(t, msg, "Subject: Reset your password\r\n")
+	require.Contains(t, msg, "https://bank.example.com/reset-password?token=abc-123_XYZ\r\n")
+	require.NotContains(t, strings.ReplaceAll(msg, "\r\n", ""), "\n")
+}
+
+func TestMessageRejectsHeaderInjection(t *testing.T) {
+	email := Email{
+		To:      "alice@example.com\r\nBcc: mallory@example.com",
+		Subject: "Hello",
+		Body:    "Hi",
+	}
+
+	_, err := email.message(testFrom, time.Now())
+	require.ErrorIs(t, err, errHeaderInjection)
+
+	email.To = "alice@example.com"
+	email.Subject = "Hello\nBcc: mallory@example.com"
+	_, err = email.message(testFrom, time.Now())
+	require.ErrorIs(t, err, errHeaderInjection)
+}
+
+func TestVerificationEmail(t *testing.T) {
+	email := NewVerificationEmail("https://bank.example.com/", "bob@example.com", "Bob", "t+k/n", 48*time.Hour)
+	require.Equal(t, "bob@example.com", email.To)
+	require.Contains(t, email.Body, "Hi Bob,")
+	require.Contains(t, email.Body, "https://bank.example.com/verify-email?token=t%2Bk%2Fn")
+	require.Contains(t, email.Body, "48h0m0s")
+}
@@ -0,0 +1,103 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer delivers emails to an SMTP server. The connection is upgraded with STARTTLS when the
+// server offers it, and credentials are only sent over TLS or to localhost.
+type SMTPMailer struct {
+	host     string
+	addr     string
+	username string
+	password string
+	from     string
+	fromAddr string
+}
+
+func NewSMTPMailer(host string, port int, username string, password string, from string) (*SMTPMailer, error) {
+	if host == "" {
+		return nil, fmt.Errorf("smtp host is required")
+	}
+
+	fromAddr, err := parseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SMTPMailer{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		username: username,
+		password: password,
+		from:     from,
+		fromAddr: fromAddr,
+	}, nil
+}
+
+func (mailer *SMTPMailer) Send(ctx context.Context, email Email) error {
+	toAddr, err := parseAddress(email.To)
+	if err != nil {
+		return err
+	}
+
+	msg, err := email.message(mailer.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", mailer.addr)
+	if err != nil {
+		return fmt.Errorf("cannot connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, mailer.host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("cannot connect to smtp server: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: mailer.host}); err != nil {
+			return fmt.Errorf("cannot start tls: %w", err)
+		}
+	}
+
+	if mailer.username != "" {
+		auth := smtp.PlainAuth("", mailer.username, mailer.password, mailer.host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("cannot authenticate to smtp server: %w", err)
+		}
+	}
+
+	if err := client.Mail(mailer.fromAddr); err != nil {
+		return err
+	}
+	if err := client.Rcpt(toAddr); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSession is what a fake SMTP server received from one client
+type smtpSession struct {
+	from string
+	to   []string
+	data string
+}
+
+// startFakeSMTPServer accepts one connection on localhost and answers just enough of the protocol for
+// net/smtp to deliver a message. It offers neither STARTTLS nor AUTH.
+func startFakeSMTPServer(t *testing.T) (host string, port int, sessions <-chan smtpSession) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan smtpSession, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var session smtpSession
+		reader := bufio.NewReader(conn)
+		reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+		reply("220 localhost ready")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			command := strings.ToUpper(line)
+
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				session.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				session.to = append(session.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case command == "DATA":
+				reply("354 end data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					dataLine, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				session.data = data.String()
+				reply("250 OK")
+			case command == "QUIT":
+				reply("221 bye")
+				received <- session
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	host, port, sessions := startFakeSMTPServer(t)
+
+	mailer, err := NewSMTPMailer(host, port, "", "", testFrom)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email := NewVerificationEmail("https://bank.example.com", "Carol <carol@example.com>", "Carol", "token", time.Hour)
+	require.NoError(t, mailer.Send(ctx, email))
+
+	select {
+	case session := <-sessions:
+		require.Equal(t, "no-reply@example.com", session.from)
+		require.Equal(t, []string{"carol@example.com"}, session.to)
+		require.Contains(t, session.data, "Subject: Verify your email address\r\n")
+		require.Contains(t, session.data, "https://bank.example.com/verify-email?token=token\r\n")
+	case <-ctx.Done():
+		t.Fatal("smtp server did not receive the email")
+	}
+}
+
+func TestSMTPMailerUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	mailer, err := NewSMTPMailer("127.0.0.1", port, "", "", testFrom)
+	require.NoError(t, err)
+
+	err = mailer.Send(context.Background(), Email{To: "dave@example.com", Subject: "Hello", Body: "Hi"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "127.0.0.1:"+strconv.Itoa(port))
+}
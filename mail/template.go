@@ -0,0 +1,46 @@
+package mail
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// NewVerificationEmail asks the user to confirm the email address by following a link with the token
+func NewVerificationEmail(appURL string, to string, fullName string, token string, expiresIn time.Duration) Email {
+	return Email{
+		To:      to,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(`Hi %s,
+
+Please confirm that this is your email address by opening the link below:
+
+%s
+
+The link expires in %s. If you did not sign up for Simple Bank, you can ignore this email.
+`, fullName, tokenLink(appURL, "/verify-email", token), expiresIn),
+	}
+}
+
+// NewPasswordResetEmail sends the user a link with the token to choose a new password
+func NewPasswordResetEmail(appURL string, to string, fullName string, token string, expiresIn time.Duration) Email {
+	return Email{
+		To:      to,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(`Hi %s,
+
+Someone asked to reset the password of your Simple Bank account. Open the link below to choose a
+new password:
+
+%s
+
+The link expires in %s and can only be used once. If you did not ask for this, you can ignore this
+email and your password stays the same.
+`, fullName, tokenLink(appURL, "/reset-password", token), expiresIn),
+	}
+}
+
+func tokenLink(appURL string, path string, token string) string {
+	return strings.TrimSuffix(appURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
@@ -1,11 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/khorsl/simple_bank/api"
+	"github.com/khorsl/simple_bank/gapi"
+	"github.com/khorsl/simple_bank/job"
+	"github.com/khorsl/simple_bank/pb"
+	"github.com/khorsl/simple_bank/ratelimit"
 	"github.com/khorsl/simple_bank/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 
 	db "github.com/khorsl/simple_bank/db/sqlc"
 
@@ -24,7 +34,31 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+	limiter := ratelimit.NewMemoryLimiter()
+	if config.ApprovalExpiryInterval > 0 {
+		go runApprovalExpiry(config, store)
+	}
+	if config.LoanCollectionInterval > 0 {
+		go runLoanCollection(config, store)
+	}
+	if config.MoneyRequestExpiryInterval > 0 {
+		go runMoneyRequestExpiry(config, store)
+	}
+	if config.EscrowReleaseInterval > 0 {
+		go runEscrowRelease(config, store)
+	}
+
+	if config.GRPCServerAddress != "" {
+		go runGrpcServer(config, store, limiter)
+		if config.GatewayAddress != "" {
+			go runGatewayServer(config)
+		}
+	}
+	runGinServer(config, store, limiter)
+}
+
+func runGinServer(config util.Config, store db.Store, limiter ratelimit.Limiter) {
+	server, err := api.NewServer(config, store, limiter)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
@@ -34,3 +68,78 @@ func main() {
 		log.Fatal("cannot connect to server:", err)
 	}
 }
+
+func runGatewayServer(config util.Config) {
+	handler, err := gapi.NewGatewayHandler(context.Background(), config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal("cannot create gateway:", err)
+	}
+
+	log.Printf("start HTTP gateway at %s", config.GatewayAddress)
+	err = http.ListenAndServe(config.GatewayAddress, handler)
+	if err != nil {
+		log.Fatal("cannot start HTTP gateway:", err)
+	}
+}
+
+func runGrpcServer(config util.Config, store db.Store, limiter ratelimit.Limiter) {
+	server, err := gapi.NewServer(config, store, limiter)
+	if err != nil {
+		log.Fatal("cannot create gRPC server:", err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(server.AuthInterceptor, server.RateLimitInterceptor))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	reflection.Register(grpcServer)
+
+	listener, err := net.Listen("tcp", config.GRPCServerAddress)
+	if err != nil {
+		log.Fatal("cannot create listener:", err)
+	}
+
+	log.Printf("start gRPC server at %s", listener.Addr().String())
+	err = grpcServer.Serve(listener)
+	if err != nil {
+		log.Fatal("cannot start gRPC server:", err)
+	}
+}
+
+func runApprovalExpiry(config util.Config, store db.Store) {
+	job.RunEvery(context.Background(), "approval expiry", config.ApprovalExpiryInterval, func(ctx context.Context) error {
+		expired, err := job.ExpireTransferApprovals(ctx, store)
+		if expired > 0 {
+			log.Printf("expired %d transfers awaiting approval", expired)
+		}
+		return err
+	})
+}
+
+func runLoanCollection(config util.Config, store db.Store) {
+	job.RunEvery(context.Background(), "loan collection", config.LoanCollectionInterval, func(ctx context.Context) error {
+		result, err := job.CollectLoanRepayments(ctx, store, time.Now().UTC(), config.LoanLateFee)
+		if result.Collected > 0 || result.Failed > 0 || result.Overdue > 0 {
+			log.Printf("collected %d loan installments, %d failed, %d became overdue", result.Collected, result.Failed, result.Overdue)
+		}
+		return err
+	})
+}
+
+func runMoneyRequestExpiry(config util.Config, store db.Store) {
+	job.RunEvery(context.Background(), "money request expiry", config.MoneyRequestExpiryInterval, func(ctx context.Context) error {
+		expired, err := store.ExpireMoneyRequests(ctx)
+		if expired > 0 {
+			log.Printf("expired %d money requests", expired)
+		}
+		return err
+	})
+}
+
+func runEscrowRelease(config util.Config, store db.Store) {
+	job.RunEvery(context.Background(), "escrow release", config.EscrowReleaseInterval, func(ctx context.Context) error {
+		result, err := job.ReleaseDueEscrows(ctx, store, time.Now())
+		if result.Released > 0 || result.Failed > 0 {
+			log.Printf("released %d escrows, %d failed", result.Released, result.Failed)
+		}
+		return err
+	})
+}
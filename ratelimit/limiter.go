@@ -0,0 +1,31 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit is a token bucket that holds up to Requests tokens and refills them evenly over Period.
+// A client can burst Requests at once, then is held to Requests per Period.
+type Limit struct {
+	Requests int64
+	Period   time.Duration
+}
+
+// Enabled reports whether the limit is configured, a zero limit lets every request through
+func (limit Limit) Enabled() bool {
+	return limit.Requests > 0 && limit.Period > 0
+}
+
+// Result is the outcome of taking a token. RetryAfter is how long until a token is available when
+// the request is not allowed.
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Limiter stores the buckets. MemoryLimiter keeps them in the process, a shared backend such as
+// Redis lets several instances of the server enforce one limit.
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
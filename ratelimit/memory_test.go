@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	return clock.now
+}
+
+func newTestLimiter() (*MemoryLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	limiter := NewMemoryLimiter()
+	limiter.now = clock.Now
+	limiter.lastSweep = clock.now
+	return limiter, clock
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	limit := Limit{Requests: 3, Period: time.Minute}
+
+	// the bucket starts full, a burst of Requests goes through
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(context.Background(), "a", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+
+	result, err := limiter.Allow(context.Background(), "a", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+	require.Equal(t, 20*time.Second, result.RetryAfter)
+
+	// other keys have their own bucket
+	result, err = limiter.Allow(context.Background(), "b", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	// one token is back a third of the period later
+	clock.now = clock.now.Add(20 * time.Second)
+	result, err = limiter.Allow(context.Background(), "a", limit)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	result, err = limiter.Allow(context.Background(), "a", limit)
+	require.NoError(t, err)
+	require.False(t, result.Allowed)
+}
+
+func TestMemoryLimiterDisabled(t *testing.T) {
+	limiter, _ := newTestLimiter()
+
+	for i := 0; i < 10; i++ {
+		result, err := limiter.Allow(context.Background(), "a", Limit{})
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+	require.Empty(t, limiter.buckets)
+}
+
+func TestMemoryLimiterSweep(t *testing.T) {
+	limiter, clock := newTestLimiter()
+	limit := Limit{Requests: 2, Period: time.Hour}
+
+	_, err := limiter.Allow(context.Background(), "refilled", Limit{Requests: 2, Period: time.Second})
+	require.NoError(t, err)
+	_, err = limiter.Allow(context.Background(), "draining", limit)
+	require.NoError(t, err)
+
+	clock.now = clock.now.Add(sweepInterval)
+	_, err = limiter.Allow(context.Background(), "other", limit)
+	require.NoError(t, err)
+
+	require.NotContains(t, limiter.buckets, "refilled")
+	require.Contains(t, limiter.buckets, "draining")
+	require.Contains(t, limiter.buckets, "other")
+}
@@ -0,0 +1,111 @@
+package risk
+
+import (
+	"context"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+	"github.com/khorsl/simple_bank/util"
+)
+
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Review Decision = "review"
+	Block  Decision = "block"
+)
+
+var severity = map[Decision]int{
+	Allow:  0,
+	Review: 1,
+	Block:  2,
+}
+
+// Transfer is the transfer being assessed, before any money is moved
+type Transfer struct {
+	UserID        int64
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+}
+
+// Rule checks a transfer against the transfer history of the sending user.
+// It returns Allow when the rule does not match.
+type Rule interface {
+	Name() string
+	Evaluate(ctx context.Context, store db.Querier, transfer Transfer) (Decision, error)
+}
+
+type Assessment struct {
+	Decision     Decision `json:"decision"`
+	MatchedRules []string `json:"matched_rules"`
+}
+
+// Engine runs every rule and keeps the most severe decision
+type Engine struct {
+	store db.Querier
+	rules []Rule
+}
+
+func NewEngine(store db.Querier, rules ...Rule) *Engine {
+	return &Engine{
+		store: store,
+		rules: rules,
+	}
+}
+
+// NewEngineFromConfig only enables the rules that are configured
+func NewEngineFromConfig(config util.Config, store db.Querier) *Engine {
+	var rules []Rule
+
+	if config.RiskVelocityMaxTransfers > 0 {
+		rules = append(rules, VelocityRule{
+			MaxTransfers: config.RiskVelocityMaxTransfers,
+			Window:       config.RiskVelocityWindow,
+			Action:       Block,
+		})
+	}
+
+	if config.RiskNewCounterpartyThreshold > 0 {
+		rules = append(rules, NewCounterpartyRule{
+			Threshold: config.RiskNewCounterpartyThreshold,
+			Action:    Review,
+		})
+	}
+
+	if config.RiskAmountSpikeMultiplier > 0 {
+		rules = append(rules, AmountSpikeRule{
+			Multiplier: config.RiskAmountSpikeMultiplier,
+			Window:     config.RiskAmountSpikeWindow,
+			Action:     Review,
+		})
+	}
+
+	return NewEngine(store, rules...)
+}
+
+func (engine *Engine) Assess(ctx context.Context, transfer Transfer) (Assessment, error) {
+	assessment := Assessment{
+		Decision:     Allow,
+		MatchedRules: []string{},
+	}
+
+	for _, rule := range engine.rules {
+		decision, err := rule.Evaluate(ctx, engine.store, transfer)
+		if err != nil {
+			return Assessment{}, err
+		}
+
+		if decision == Allow {
+			continue
+		}
+
+		assessment.MatchedRules = append(assessment.MatchedRules, rule.Name())
+		if severity[decision] > severity[assessment.Decision] {
+			assessment.Decision = decision
+		}
+	}
+
+	return assessment, nil
+}
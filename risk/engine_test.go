@@ -0,0 +1,141 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/khorsl/simple_bank/db/mock"
+	"github.com/khorsl/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTransfer() Transfer {
+	return Transfer{
+		UserID:        util.RandomInt(1, 1000),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1, 1000),
+		Amount:        util.RandomInt(1, 1000),
+		Currency:      util.USD,
+	}
+}
+
+func TestVelocityRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer()
+	rule := VelocityRule{MaxTransfers: 3, Window: time.Minute, Action: Block}
+
+	store.EXPECT().CountUserTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+	decision, err := rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Allow, decision)
+
+	store.EXPECT().CountUserTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+	decision, err = rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Block, decision)
+}
+
+func TestNewCounterpartyRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer()
+	rule := NewCounterpartyRule{Threshold: transfer.Amount, Action: Review}
+
+	// at the threshold, history is not looked up
+	store.EXPECT().CountUserTransfersToAccount(gomock.Any(), gomock.Any()).Times(0)
+	decision, err := rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Allow, decision)
+
+	rule.Threshold = transfer.Amount - 1
+
+	store.EXPECT().CountUserTransfersToAccount(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+	decision, err = rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Allow, decision)
+
+	store.EXPECT().CountUserTransfersToAccount(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	decision, err = rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Review, decision)
+}
+
+func TestAmountSpikeRule(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer()
+	transfer.Amount = 1000
+	rule := AmountSpikeRule{Multiplier: 10, Window: 30 * 24 * time.Hour, Action: Review}
+
+	// no history
+	store.EXPECT().GetUserAverageTransferAmount(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	decision, err := rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Allow, decision)
+
+	store.EXPECT().GetUserAverageTransferAmount(gomock.Any(), gomock.Any()).Times(1).Return(int64(101), nil)
+	decision, err = rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Allow, decision)
+
+	store.EXPECT().GetUserAverageTransferAmount(gomock.Any(), gomock.Any()).Times(1).Return(int64(100), nil)
+	decision, err = rule.Evaluate(context.Background(), store, transfer)
+	require.NoError(t, err)
+	require.Equal(t, Review, decision)
+}
+
+func TestEngine(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	transfer := randomTransfer()
+	transfer.Amount = 1000
+
+	engine := NewEngine(store,
+		VelocityRule{MaxTransfers: 3, Window: time.Minute, Action: Block},
+		NewCounterpartyRule{Threshold: 10, Action: Review},
+		AmountSpikeRule{Multiplier: 10, Window: time.Hour, Action: Review},
+	)
+
+	store.EXPECT().CountUserTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().CountUserTransfersToAccount(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().GetUserAverageTransferAmount(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+
+	assessment, err := engine.Assess(context.Background(), transfer)
+	require.NoError(t, err)
+	require.Equal(t, Review, assessment.Decision)
+	require.Equal(t, []string{"new_counterparty"}, assessment.MatchedRules)
+
+	store.EXPECT().CountUserTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(5), nil)
+	store.EXPECT().CountUserTransfersToAccount(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+	store.EXPECT().GetUserAverageTransferAmount(gomock.Any(), gomock.Any()).Times(1).Return(int64(50), nil)
+
+	assessment, err = engine.Assess(context.Background(), transfer)
+	require.NoError(t, err)
+	require.Equal(t, Block, assessment.Decision)
+	require.Equal(t, []string{"velocity", "new_counterparty", "amount_spike"}, assessment.MatchedRules)
+
+	store.EXPECT().CountUserTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+
+	_, err = engine.Assess(context.Background(), transfer)
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
+
+func TestEngineWithoutRules(t *testing.T) {
+	assessment, err := NewEngine(nil).Assess(context.Background(), randomTransfer())
+	require.NoError(t, err)
+	require.Equal(t, Allow, assessment.Decision)
+	require.NotNil(t, assessment.MatchedRules)
+	require.Empty(t, assessment.MatchedRules)
+}
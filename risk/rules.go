@@ -0,0 +1,91 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	db "github.com/khorsl/simple_bank/db/sqlc"
+)
+
+// VelocityRule matches when the user already made MaxTransfers transfers within Window
+type VelocityRule struct {
+	MaxTransfers int64
+	Window       time.Duration
+	Action       Decision
+}
+
+func (rule VelocityRule) Name() string {
+	return "velocity"
+}
+
+func (rule VelocityRule) Evaluate(ctx context.Context, store db.Querier, transfer Transfer) (Decision, error) {
+	count, err := store.CountUserTransfersSince(ctx, db.CountUserTransfersSinceParams{
+		Owner: transfer.UserID,
+		Since: time.Now().Add(-rule.Window),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if count >= rule.MaxTransfers {
+		return rule.Action, nil
+	}
+	return Allow, nil
+}
+
+// NewCounterpartyRule matches the first transfer to an account when it is above Threshold
+type NewCounterpartyRule struct {
+	Threshold int64
+	Action    Decision
+}
+
+func (rule NewCounterpartyRule) Name() string {
+	return "new_counterparty"
+}
+
+func (rule NewCounterpartyRule) Evaluate(ctx context.Context, store db.Querier, transfer Transfer) (Decision, error) {
+	if transfer.Amount <= rule.Threshold {
+		return Allow, nil
+	}
+
+	count, err := store.CountUserTransfersToAccount(ctx, db.CountUserTransfersToAccountParams{
+		Owner:       transfer.UserID,
+		ToAccountID: transfer.ToAccountID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if count == 0 {
+		return rule.Action, nil
+	}
+	return Allow, nil
+}
+
+// AmountSpikeRule matches when the amount is at least Multiplier times the average transfer of the user
+// over Window. Users without any history in the currency are not matched.
+type AmountSpikeRule struct {
+	Multiplier int64
+	Window     time.Duration
+	Action     Decision
+}
+
+func (rule AmountSpikeRule) Name() string {
+	return "amount_spike"
+}
+
+func (rule AmountSpikeRule) Evaluate(ctx context.Context, store db.Querier, transfer Transfer) (Decision, error) {
+	average, err := store.GetUserAverageTransferAmount(ctx, db.GetUserAverageTransferAmountParams{
+		Owner:    transfer.UserID,
+		Currency: transfer.Currency,
+		Since:    time.Now().Add(-rule.Window),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if average > 0 && transfer.Amount >= rule.Multiplier*average {
+		return rule.Action, nil
+	}
+	return Allow, nil
+}
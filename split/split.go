@@ -0,0 +1,77 @@
+package split
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Split methods
+const (
+	// Equal gives every participant the same share
+	Equal = "equal"
+	// Percentage gives every participant a share in basis points of the total
+	Percentage = "percentage"
+	// Exact gives every participant the amount they were assigned
+	Exact = "exact"
+)
+
+// basisPointsPerUnit is 100% in basis points
+const basisPointsPerUnit = 10_000
+
+var (
+	ErrUnsupportedMethod = errors.New("unsupported split method")
+	ErrPercentagesTotal  = errors.New("percentages of a split must add up to 100%")
+	ErrAmountsTotal      = errors.New("amounts of a split must add up to its total")
+	ErrShareTooSmall     = errors.New("every participant of a split must have a share above zero")
+)
+
+// Divide splits total, in minor units, between participants. Weights are ignored by Equal, are
+// basis points for Percentage and amounts for Exact. Shares are rounded down to the minor unit;
+// the remainder is returned separately so the caller can give it to the owner of the split.
+func Divide(total int64, method string, weights []int64) (shares []int64, remainder int64, err error) {
+	shares = make([]int64, len(weights))
+
+	switch method {
+	case Equal:
+		for i := range shares {
+			shares[i] = total / int64(len(weights))
+		}
+	case Percentage:
+		var sum int64
+		for i, bps := range weights {
+			sum += bps
+			shares[i] = percentageOf(total, bps)
+		}
+		if sum != basisPointsPerUnit {
+			return nil, 0, ErrPercentagesTotal
+		}
+	case Exact:
+		var sum int64
+		for i, amount := range weights {
+			sum += amount
+			shares[i] = amount
+		}
+		if sum != total {
+			return nil, 0, ErrAmountsTotal
+		}
+	default:
+		return nil, 0, ErrUnsupportedMethod
+	}
+
+	remainder = total
+	for _, share := range shares {
+		if share <= 0 {
+			return nil, 0, ErrShareTooSmall
+		}
+		remainder -= share
+	}
+
+	return shares, remainder, nil
+}
+
+// percentageOf rounds down total * bps / 10000 without overflowing
+func percentageOf(total int64, bps int64) int64 {
+	share := new(big.Int).Mul(big.NewInt(total), big.NewInt(bps))
+	share.Quo(share, big.NewInt(basisPointsPerUnit))
+	return share.Int64()
+}
@@ -0,0 +1,46 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDivideEqual(t *testing.T) {
+	// 100.00 between 3 leaves one minor unit
+	shares, remainder, err := Divide(10_000, Equal, make([]int64, 3))
+	require.NoError(t, err)
+	require.Equal(t, []int64{3333, 3333, 3333}, shares)
+	require.Equal(t, int64(1), remainder)
+
+	_, _, err = Divide(2, Equal, make([]int64, 3))
+	require.ErrorIs(t, err, ErrShareTooSmall)
+}
+
+func TestDividePercentage(t *testing.T) {
+	shares, remainder, err := Divide(999, Percentage, []int64{5000, 2500, 2500})
+	require.NoError(t, err)
+	require.Equal(t, []int64{499, 249, 249}, shares)
+	require.Equal(t, int64(2), remainder)
+
+	_, _, err = Divide(999, Percentage, []int64{5000, 2500})
+	require.ErrorIs(t, err, ErrPercentagesTotal)
+}
+
+func TestDivideExact(t *testing.T) {
+	shares, remainder, err := Divide(1000, Exact, []int64{600, 400})
+	require.NoError(t, err)
+	require.Equal(t, []int64{600, 400}, shares)
+	require.Zero(t, remainder)
+
+	_, _, err = Divide(1000, Exact, []int64{600, 300})
+	require.ErrorIs(t, err, ErrAmountsTotal)
+
+	_, _, err = Divide(1000, Exact, []int64{1000, 0})
+	require.ErrorIs(t, err, ErrShareTooSmall)
+}
+
+func TestDivideUnsupportedMethod(t *testing.T) {
+	_, _, err := Divide(1000, "weighted", []int64{1, 1})
+	require.ErrorIs(t, err, ErrUnsupportedMethod)
+}
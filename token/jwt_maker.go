@@ -25,7 +25,11 @@ func NewJwtMaker(secretKey string) (Maker, error) {
 }
 
 func (maker *JwtMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+	return maker.CreatePurposeToken(username, PurposeAccess, duration)
+}
+
+func (maker *JwtMaker) CreatePurposeToken(username string, purpose string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPurposePayload(username, purpose, duration)
 	if err != nil {
 		return "", nil, err
 	}
@@ -32,6 +32,7 @@ func TestJwtMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, PurposeAccess, payload.Purpose)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -84,3 +85,15 @@ func TestInvalidJWTTokenAlgNone(t *testing.T) {
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Nil(t, payload)
 }
+
+func TestJwtPurposeToken(t *testing.T) {
+	jwtMaker, err := NewJwtMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := jwtMaker.CreatePurposeToken(util.RandomUsername(), PurposeMFAChallenge, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := jwtMaker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, PurposeMFAChallenge, payload.Purpose)
+}
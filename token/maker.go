@@ -3,7 +3,11 @@ package token
 import "time"
 
 type Maker interface {
+	// CreateToken creates an access token
 	CreateToken(username string, duration time.Duration) (string, *Payload, error)
 
+	// CreatePurposeToken creates a token that is only valid for the given purpose
+	CreatePurposeToken(username string, purpose string, duration time.Duration) (string, *Payload, error)
+
 	VerifyToken(token string) (*Payload, error)
 }
@@ -24,7 +24,11 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 }
 
 func (maker *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, duration)
+	return maker.CreatePurposeToken(username, PurposeAccess, duration)
+}
+
+func (maker *PasetoMaker) CreatePurposeToken(username string, purpose string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPurposePayload(username, purpose, duration)
 	if err != nil {
 		return "", nil, err
 	}
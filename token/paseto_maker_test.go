@@ -33,6 +33,7 @@ func TestPasetoMaker(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, PurposeAccess, payload.Purpose)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -83,3 +84,31 @@ func TestInvalidPasetoToken(t *testing.T) {
 	require.EqualError(t, err, ErrInvalidToken.Error())
 	require.Empty(t, payload)
 }
+
+func TestPasetoPurposeToken(t *testing.T) {
+	pasetoMaker, err := NewPasetoMaker(util.RandomString(chacha20poly1305.KeySize))
+	require.NoError(t, err)
+
+	token, _, err := pasetoMaker.CreatePurposeToken(util.RandomUsername(), PurposeMFAChallenge, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := pasetoMaker.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, PurposeMFAChallenge, payload.Purpose)
+}
+
+func TestPasetoRevokedToken(t *testing.T) {
+	maker, err := NewPasetoMaker(util.RandomString(32))
+	require.NoError(t, err)
+
+	token, _, err := maker.CreateToken(util.RandomUsername(), time.Minute)
+	require.NoError(t, err)
+
+	payload, err := maker.VerifyToken(token)
+	require.NoError(t, err)
+
+	require.NoError(t, payload.ValidSince(time.Time{}))
+	require.NoError(t, payload.ValidSince(payload.IssuedAt))
+	require.NoError(t, payload.ValidSince(time.Now().Add(-time.Second)))
+	require.ErrorIs(t, payload.ValidSince(time.Now().Add(time.Second)), ErrRevokedToken)
+}
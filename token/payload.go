@@ -10,16 +10,29 @@ import (
 var (
 	ErrInvalidToken = errors.New("token is invalid")
 	ErrExpiredToken = errors.New("token has expired")
+	ErrRevokedToken = errors.New("token was issued before the last password change")
+)
+
+// Purposes of a token. Only access tokens are accepted by the authenticated endpoints.
+const (
+	PurposeAccess       = "access"
+	PurposeMFAChallenge = "mfa_challenge"
 )
 
 type Payload struct {
 	ID        uuid.UUID `json:"uuid"`
 	Username  string    `json:"username"`
+	Purpose   string    `json:"purpose"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
 func NewPayload(username string, duration time.Duration) (*Payload, error) {
+	return NewPurposePayload(username, PurposeAccess, duration)
+}
+
+// NewPurposePayload creates the payload of a token that can only be used for the given purpose
+func NewPurposePayload(username string, purpose string, duration time.Duration) (*Payload, error) {
 	tokenId, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -28,6 +41,7 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID:        tokenId,
 		Username:  username,
+		Purpose:   purpose,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -41,3 +55,12 @@ func (payload *Payload) Valid() error {
 	}
 	return nil
 }
+
+// ValidSince returns ErrRevokedToken when the token was issued before the last password change of
+// the user, so changing the password logs out every other session
+func (payload *Payload) ValidSince(passwordChangedAt time.Time) error {
+	if payload.IssuedAt.Before(passwordChangedAt) {
+		return ErrRevokedToken
+	}
+	return nil
+}
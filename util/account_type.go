@@ -0,0 +1,16 @@
+package util
+
+const (
+	Checking = "checking"
+	Savings  = "savings"
+	// Internal accounts hold the bank's side of ledger postings, they have no members
+	Internal = "internal"
+)
+
+func IsSupportedAccountType(accountType string) bool {
+	switch accountType {
+	case Checking, Savings:
+		return true
+	}
+	return false
+}
@@ -12,8 +12,75 @@ type Config struct {
 	DBDriver            string        `mapstructure:"DB_DRIVER"`
 	DBSource            string        `mapstructure:"DB_SOURCE"`
 	ServerAddress       string        `mapstructure:"SERVER_ADDRESS"`
+	GRPCServerAddress   string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	GatewayAddress      string        `mapstructure:"GATEWAY_ADDRESS"`
 	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+
+	RiskVelocityMaxTransfers     int64         `mapstructure:"RISK_VELOCITY_MAX_TRANSFERS"`
+	RiskVelocityWindow           time.Duration `mapstructure:"RISK_VELOCITY_WINDOW"`
+	RiskNewCounterpartyThreshold int64         `mapstructure:"RISK_NEW_COUNTERPARTY_THRESHOLD"`
+	RiskAmountSpikeMultiplier    int64         `mapstructure:"RISK_AMOUNT_SPIKE_MULTIPLIER"`
+	RiskAmountSpikeWindow        time.Duration `mapstructure:"RISK_AMOUNT_SPIKE_WINDOW"`
+
+	ApprovalExpiryInterval time.Duration `mapstructure:"APPROVAL_EXPIRY_INTERVAL"`
+	LoanCollectionInterval time.Duration `mapstructure:"LOAN_COLLECTION_INTERVAL"`
+	LoanLateFee            int64         `mapstructure:"LOAN_LATE_FEE"`
+
+	PayeeCoolingOffPeriod time.Duration `mapstructure:"PAYEE_COOLING_OFF_PERIOD"`
+	PayeeCoolingOffLimit  int64         `mapstructure:"PAYEE_COOLING_OFF_LIMIT"`
+
+	MoneyRequestTTL            time.Duration `mapstructure:"MONEY_REQUEST_TTL"`
+	MoneyRequestExpiryInterval time.Duration `mapstructure:"MONEY_REQUEST_EXPIRY_INTERVAL"`
+
+	EscrowReleaseInterval time.Duration `mapstructure:"ESCROW_RELEASE_INTERVAL"`
+
+	// accounts of a type each user may own, zero or less means no limit
+	MaxCheckingAccounts int64 `mapstructure:"MAX_CHECKING_ACCOUNTS"`
+	MaxSavingsAccounts  int64 `mapstructure:"MAX_SAVINGS_ACCOUNTS"`
+
+	// failed logins in a row that lock the username or the client IP, zero disables the lockout
+	LoginMaxFailedAttempts      int64         `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS"`
+	LoginMaxFailedAttemptsPerIP int64         `mapstructure:"LOGIN_MAX_FAILED_ATTEMPTS_PER_IP"`
+	LoginLockoutDuration        time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+	LoginMaxLockoutDuration     time.Duration `mapstructure:"LOGIN_MAX_LOCKOUT_DURATION"`
+
+	// MFAChallengeDuration is how long the second step of a login with two-factor authentication may take
+	MFAChallengeDuration time.Duration `mapstructure:"MFA_CHALLENGE_DURATION"`
+	// StepUpTransferThreshold is the amount above which a transfer needs an authentication code, zero disables it
+	StepUpTransferThreshold int64 `mapstructure:"STEP_UP_TRANSFER_THRESHOLD"`
+
+	// MailDriver is smtp to deliver emails, or file or log to keep them local during development
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailDir      string `mapstructure:"MAIL_DIR"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	// AppURL is the address of the web app that the links of emails open
+	AppURL string `mapstructure:"APP_URL"`
+	// how long the links of verification and password reset emails work
+	EmailVerificationTokenDuration time.Duration `mapstructure:"EMAIL_VERIFICATION_TOKEN_DURATION"`
+	PasswordResetTokenDuration     time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+
+	// TrustedProxies are the proxies whose X-Forwarded-For header is used to find the client IP
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// rate limits allow the number of requests per period, zero disables a limit
+	RateLimitLoginRequests    int64         `mapstructure:"RATE_LIMIT_LOGIN_REQUESTS"`
+	RateLimitLoginPeriod      time.Duration `mapstructure:"RATE_LIMIT_LOGIN_PERIOD"`
+	RateLimitSignupRequests   int64         `mapstructure:"RATE_LIMIT_SIGNUP_REQUESTS"`
+	RateLimitSignupPeriod     time.Duration `mapstructure:"RATE_LIMIT_SIGNUP_PERIOD"`
+	RateLimitTransferRequests int64         `mapstructure:"RATE_LIMIT_TRANSFER_REQUESTS"`
+	RateLimitTransferPeriod   time.Duration `mapstructure:"RATE_LIMIT_TRANSFER_PERIOD"`
+	RateLimitLookupRequests   int64         `mapstructure:"RATE_LIMIT_LOOKUP_REQUESTS"`
+	RateLimitLookupPeriod     time.Duration `mapstructure:"RATE_LIMIT_LOOKUP_PERIOD"`
+	// emails sent on request have their own limits so they cannot be used to flood an inbox
+	RateLimitPasswordResetRequests     int64         `mapstructure:"RATE_LIMIT_PASSWORD_RESET_REQUESTS"`
+	RateLimitPasswordResetPeriod       time.Duration `mapstructure:"RATE_LIMIT_PASSWORD_RESET_PERIOD"`
+	RateLimitEmailVerificationRequests int64         `mapstructure:"RATE_LIMIT_EMAIL_VERIFICATION_REQUESTS"`
+	RateLimitEmailVerificationPeriod   time.Duration `mapstructure:"RATE_LIMIT_EMAIL_VERIFICATION_PERIOD"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -31,3 +98,20 @@ func LoadConfig(path string) (config Config, err error) {
 	err = viper.Unmarshal(&config)
 	return
 }
+
+// MaxAccounts returns how many accounts of a type each user may own. Zero means no limit, so an
+// unset or negative setting does not stop users from opening accounts.
+func (config Config) MaxAccounts(accountType string) int64 {
+	var max int64
+	switch accountType {
+	case Checking:
+		max = config.MaxCheckingAccounts
+	case Savings:
+		max = config.MaxSavingsAccounts
+	}
+
+	if max < 0 {
+		return 0
+	}
+	return max
+}
@@ -0,0 +1,19 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxAccounts(t *testing.T) {
+	config := Config{
+		MaxCheckingAccounts: 2,
+		MaxSavingsAccounts:  -1,
+	}
+
+	require.Equal(t, int64(2), config.MaxAccounts(Checking))
+	require.Zero(t, config.MaxAccounts(Savings))
+	require.Zero(t, Config{}.MaxAccounts(Checking))
+	require.Zero(t, config.MaxAccounts("unknown"))
+}
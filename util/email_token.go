@@ -0,0 +1,18 @@
+package util
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+)
+
+// emailTokenBytes is the amount of randomness in the tokens of verification and password reset emails
+const emailTokenBytes = 32
+
+// RandomEmailToken returns a URL safe token to put in a link of an email
+func RandomEmailToken() (string, error) {
+	b := make([]byte, emailTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,20 @@
+package util
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomEmailToken(t *testing.T) {
+	token1, err := RandomEmailToken()
+	require.NoError(t, err)
+	token2, err := RandomEmailToken()
+	require.NoError(t, err)
+	require.NotEqual(t, token1, token2)
+
+	b, err := base64.RawURLEncoding.DecodeString(token1)
+	require.NoError(t, err)
+	require.Len(t, b, emailTokenBytes)
+}
@@ -0,0 +1,14 @@
+package util
+
+import "strings"
+
+// MaskName keeps the first letter of every word in a name and hides the rest,
+// so "John Smith" becomes "J*** S****"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		runes := []rune(word)
+		words[i] = string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	}
+	return strings.Join(words, " ")
+}
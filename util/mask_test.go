@@ -0,0 +1,14 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskName(t *testing.T) {
+	require.Equal(t, "J*** S****", MaskName("John Smith"))
+	require.Equal(t, "Z** J* M*****", MaskName("  Zoë J. Müller "))
+	require.Equal(t, "A", MaskName("A"))
+	require.Equal(t, "", MaskName(""))
+}
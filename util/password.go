@@ -6,6 +6,10 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// UnknownUserHashedPassword is the hash of a random password. Logins of unknown usernames are checked
+// against it, so they take as long as a wrong password and do not reveal which usernames exist.
+const UnknownUserHashedPassword = "$2a$10$IOXwfu9T6.tCz8GCzo361uQsF8b1z9yU1If1mQkUOsyLcqzQqDCW2"
+
 func HashPassword(password string) (string, error) {
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -27,3 +27,13 @@ func TestPassword(t *testing.T) {
 	require.NoError(t, err2)
 	require.NotEqual(t, hashedPassword1, hashedPassword2)
 }
+
+func TestUnknownUserHashedPassword(t *testing.T) {
+	// a valid hash, so checking against it costs as much as checking a real password
+	cost, err := bcrypt.Cost([]byte(UnknownUserHashedPassword))
+	require.NoError(t, err)
+	require.Equal(t, bcrypt.DefaultCost, cost)
+
+	err = CheckPassword(RandomString(6), UnknownUserHashedPassword)
+	require.EqualError(t, err, bcrypt.ErrMismatchedHashAndPassword.Error())
+}
@@ -0,0 +1,6 @@
+package util
+
+const (
+	CustomerRole = "customer"
+	AdminRole    = "admin"
+)
@@ -0,0 +1,14 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken hashes a token for storage, like the token of an email or of a payment link, so a leaked
+// database cannot be used to take over accounts or pay links. Like recovery codes the tokens are
+// random, so a fast hash is safe.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,16 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHashToken(t *testing.T) {
+	token1 := RandomString(32)
+	token2 := RandomString(32)
+
+	require.Len(t, HashToken(token1), 64)
+	require.Equal(t, HashToken(token1), HashToken(token1))
+	require.NotEqual(t, HashToken(token1), HashToken(token2))
+}
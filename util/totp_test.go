@@ -0,0 +1,88 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 key of the test vectors in RFC 6238, base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tc := range testCases {
+		code, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tc.unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, tc.code, code)
+	}
+
+	_, err := TOTPCode("not base32!", 1)
+	require.Error(t, err)
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := RandomTOTPSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	step := TOTPStep(now)
+
+	code, err := TOTPCode(secret, step)
+	require.NoError(t, err)
+
+	got, ok := ValidateTOTP(secret, code, now)
+	require.True(t, ok)
+	require.Equal(t, step, got)
+
+	// the previous code is still accepted for clock drift, older ones are not
+	previous, err := TOTPCode(secret, step-1)
+	require.NoError(t, err)
+	got, ok = ValidateTOTP(secret, previous, now)
+	require.True(t, ok)
+	require.Equal(t, step-1, got)
+
+	old, err := TOTPCode(secret, step-3)
+	require.NoError(t, err)
+	_, ok = ValidateTOTP(secret, old, now)
+	require.False(t, ok)
+
+	_, ok = ValidateTOTP(secret, "12345", now)
+	require.False(t, ok)
+}
+
+func TestTOTPURL(t *testing.T) {
+	url := TOTPURL("Simple Bank", "alice", rfc6238Secret)
+	require.True(t, strings.HasPrefix(url, "otpauth://totp/Simple%20Bank:alice?"))
+	require.Contains(t, url, "secret="+rfc6238Secret)
+	require.Contains(t, url, "issuer=Simple+Bank")
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := RandomRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		require.Len(t, code, 9)
+		require.False(t, seen[code])
+		seen[code] = true
+	}
+
+	// codes can be typed without the dash and in upper case
+	hashed := HashRecoveryCode(codes[0])
+	require.Equal(t, hashed, HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+	require.NotEqual(t, hashed, HashRecoveryCode(codes[1]))
+}